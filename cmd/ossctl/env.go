@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage/local"
+	"github.com/gooss/server/pkg/config"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// env 命令运行所需的依赖
+type env struct {
+	cfg     *config.Config
+	repo    *metadata.PostgresRepository
+	storage *local.LocalStorage
+}
+
+// openEnv 按服务端配置初始化日志、数据库和存储引擎
+func openEnv(configPath string) (*env, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// 命令行工具的日志输出到 stderr，stdout 留给报告
+	if err := logger.Init(cfg.Logging.Level, cfg.Logging.Format, "stderr", ""); err != nil {
+		return nil, fmt.Errorf("failed to init logger: %w", err)
+	}
+
+	if cfg.Storage.Type != "local" {
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Storage.Type)
+	}
+
+	repo, err := metadata.NewPostgresRepository(cfg.Database.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	storageEngine, err := local.New(cfg.Storage.Local.BasePath)
+	if err != nil {
+		repo.Close()
+		return nil, fmt.Errorf("failed to init storage: %w", err)
+	}
+
+	return &env{cfg: cfg, repo: repo, storage: storageEngine}, nil
+}
+
+// writable 与服务端相同的只读判断：配置文件中的开关和通过管理 API 开启的开关，
+// 每次调用都重新读取数据库，导入过程中开启的只读模式随即生效
+func (e *env) writable(bucket string) error {
+	if e.cfg.Server.ReadOnly {
+		return errors.New("the service is in read-only mode")
+	}
+	for _, name := range e.cfg.Server.ReadOnlyBuckets {
+		if name == bucket {
+			return errors.New("the bucket is in read-only mode")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	modes, err := e.repo.ListReadOnlyModes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load read-only modes: %w", err)
+	}
+	for _, m := range modes {
+		switch {
+		case m.Scope == metadata.ReadOnlyScopeCluster:
+			return readOnlyError("the service is in read-only mode", m.Reason)
+		case m.Scope == metadata.ReadOnlyScopeBucket && m.BucketName == bucket:
+			return readOnlyError("the bucket is in read-only mode", m.Reason)
+		}
+	}
+	return nil
+}
+
+func readOnlyError(message, reason string) error {
+	if reason != "" {
+		return errors.New(message + ": " + reason)
+	}
+	return errors.New(message)
+}
+
+// Close 释放资源
+func (e *env) Close() {
+	e.repo.Close()
+	logger.Sync()
+}
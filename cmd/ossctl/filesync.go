@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gooss/server/internal/filesync"
+)
+
+// runImport 将本地目录导入 Bucket，退出码：0 全部成功，1 执行失败，3 部分文件失败
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := fs.String("config", "configs/config.yaml", "config file path")
+	bucket := fs.String("bucket", "", "target bucket (required)")
+	src := fs.String("src", "", "source directory (required)")
+	prefix := fs.String("prefix", "", "key prefix for imported objects")
+	owner := fs.String("owner", "", "create the bucket for this user if it does not exist")
+	concurrency := fs.Int("concurrency", 8, "number of files imported in parallel")
+	resume := fs.Bool("resume", false, "skip files already imported with the same size and mtime")
+	sidecarSuffix := fs.String("sidecar-suffix", filesync.DefaultSidecarSuffix, "suffix of sidecar metadata files")
+	fs.Parse(args)
+
+	if *bucket == "" || *src == "" {
+		fmt.Fprintln(os.Stderr, "-bucket and -src are required")
+		fs.Usage()
+		return 2
+	}
+
+	e, err := openEnv(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer e.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := filesync.New(e.storage, e.repo).Import(ctx, filesync.ImportOptions{
+		Bucket:        *bucket,
+		Source:        *src,
+		Prefix:        *prefix,
+		Owner:         *owner,
+		Concurrency:   *concurrency,
+		Resume:        *resume,
+		SidecarSuffix: *sidecarSuffix,
+		Writable:      e.writable,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return 1
+	}
+	return writeTransferReport(report)
+}
+
+// runExport 将 Bucket 导出到本地目录，退出码：0 全部成功，1 执行失败，3 部分对象失败
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := fs.String("config", "configs/config.yaml", "config file path")
+	bucket := fs.String("bucket", "", "source bucket (required)")
+	dest := fs.String("dest", "", "destination directory (required)")
+	prefix := fs.String("prefix", "", "only export objects under this prefix (stripped from file paths)")
+	concurrency := fs.Int("concurrency", 8, "number of objects exported in parallel")
+	resume := fs.Bool("resume", false, "skip files already exported with the same size and mtime")
+	sidecars := fs.Bool("sidecars", true, "write metadata, tags and content type to sidecar files")
+	sidecarSuffix := fs.String("sidecar-suffix", filesync.DefaultSidecarSuffix, "suffix of sidecar metadata files")
+	fs.Parse(args)
+
+	if *bucket == "" || *dest == "" {
+		fmt.Fprintln(os.Stderr, "-bucket and -dest are required")
+		fs.Usage()
+		return 2
+	}
+
+	e, err := openEnv(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer e.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := filesync.New(e.storage, e.repo).Export(ctx, filesync.ExportOptions{
+		Bucket:        *bucket,
+		Dest:          *dest,
+		Prefix:        *prefix,
+		Concurrency:   *concurrency,
+		Resume:        *resume,
+		Sidecars:      *sidecars,
+		SidecarSuffix: *sidecarSuffix,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export failed: %v\n", err)
+		return 1
+	}
+	return writeTransferReport(report)
+}
+
+// writeTransferReport 将报告以 JSON 写到 stdout，摘要写到 stderr
+func writeTransferReport(report *filesync.Report) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%d files: %d transferred (%d bytes), %d skipped, %d failed\n",
+		report.Files, report.Transferred, report.Bytes, report.Skipped, report.Failed)
+	if report.Failed > 0 {
+		return 3
+	}
+	return 0
+}
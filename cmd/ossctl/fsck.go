@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gooss/server/internal/maintenance"
+)
+
+// runFsck 执行一致性检查，退出码：0 无问题，1 执行失败，3 存在未修复的问题
+func runFsck(args []string) int {
+	fs := flag.NewFlagSet("fsck", flag.ExitOnError)
+	configPath := fs.String("config", "configs/config.yaml", "config file path")
+	repair := fs.Bool("repair", false, "repair issues that can be fixed automatically")
+	bucket := fs.String("bucket", "", "only check the given bucket")
+	skipHash := fs.Bool("skip-hash", false, "compare sizes only, skip ETag rehashing")
+	output := fs.String("output", "", "write JSON report to file instead of stdout")
+	fs.Parse(args)
+
+	e, err := openEnv(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer e.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := maintenance.NewFsck(e.storage, e.repo).Run(ctx, maintenance.FsckOptions{
+		Repair:   *repair,
+		Bucket:   *bucket,
+		SkipHash: *skipHash,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fsck failed: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create report file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "Checked %d objects, %d files: %d issues (%d unresolved), %d errors\n",
+		report.ObjectsChecked, report.FilesChecked, len(report.Issues), report.Unresolved(), len(report.Errors))
+
+	if report.Unresolved() > 0 || len(report.Errors) > 0 {
+		return 3
+	}
+	return 0
+}
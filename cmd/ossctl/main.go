@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: ossctl <command> [options]
+
+Commands:
+  fsck    检查元数据与存储数据的一致性
+  import  将本地目录导入 Bucket
+  export  将 Bucket 导出到本地目录
+
+Run 'ossctl <command> -h' for command options.
+`)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var code int
+	switch os.Args[1] {
+	case "fsck":
+		code = runFsck(os.Args[2:])
+	case "import":
+		code = runImport(os.Args[2:])
+	case "export":
+		code = runExport(os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
+		usage()
+		code = 2
+	}
+	os.Exit(code)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/gooss/server/internal/auth"
 	"github.com/gooss/server/internal/metadata"
 	"github.com/gooss/server/internal/storage/local"
+	"github.com/gooss/server/internal/tracing"
 	"github.com/gooss/server/pkg/config"
 	"github.com/gooss/server/pkg/logger"
 )
@@ -39,6 +40,20 @@ func main() {
 
 	logger.Info("Starting 1103-OSS Server...")
 
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Errorf("Failed to init tracing: %v", err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warnf("Failed to flush traces: %v", err)
+		}
+	}()
+
 	// 初始化数据库
 	repo, err := metadata.NewPostgresRepository(cfg.Database.DSN())
 	if err != nil {
@@ -70,28 +85,78 @@ func main() {
 
 	// 创建 API 服务器
 	server := api.NewServer(cfg, storageEngine, repo)
+	if err := server.LoadTLS(); err != nil {
+		logger.Errorf("Failed to load TLS certificate: %v", err)
+		os.Exit(1)
+	}
+
+	// 配置文件变化时热加载 CORS、日志级别和限流配置
+	config.Watch(server.ApplyConfig)
+
+	// 启动后台维护任务
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	server.StartBackgroundJobs(bgCtx)
 
 	// 启动服务器
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	logger.Infof("Server listening on %s", addr)
+	scheme := "http"
+	if cfg.Server.TLS.Enabled {
+		scheme = "https"
+	}
+	logger.Infof("Server listening on %s (%s)", addr, scheme)
 
-	// 优雅关闭
+	serverErr := make(chan error, 2)
 	go func() {
 		if err := server.Run(addr); err != nil {
-			logger.Errorf("Server error: %v", err)
+			serverErr <- fmt.Errorf("server error: %w", err)
 		}
 	}()
 
-	// 等待中断信号
+	// 管理端口：管理 API、监控指标和性能分析
+	if cfg.Server.AdminPort > 0 {
+		adminHost := cfg.Server.AdminHost
+		if adminHost == "" {
+			adminHost = cfg.Server.Host
+		}
+		adminAddr := fmt.Sprintf("%s:%d", adminHost, cfg.Server.AdminPort)
+		logger.Infof("Admin listening on %s", adminAddr)
+		go func() {
+			if err := server.RunAdmin(adminAddr); err != nil {
+				serverErr <- fmt.Errorf("admin server error: %w", err)
+			}
+		}()
+	}
+
+	// 等待中断信号或监听失败，SIGHUP 重新加载 TLS 证书
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Infof("Shutting down server...")
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+wait:
+	for {
+		select {
+		case <-hup:
+			logger.Infof("Received SIGHUP, reloading TLS certificates")
+			if err := server.ReloadTLS(); err != nil {
+				logger.Errorf("Failed to reload TLS certificates: %v", err)
+			}
+		case sig := <-quit:
+			logger.Infof("Received %s, shutting down server...", sig)
+			break wait
+		case err := <-serverErr:
+			logger.Errorf("%v, shutting down server...", err)
+			break wait
+		}
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// 优雅关闭：排空请求并写入审计日志后再停止后台任务
+	ctx, cancel := context.WithTimeout(context.Background(), server.DrainTimeout())
 	defer cancel()
-	_ = ctx
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Warnf("Server shutdown incomplete: %v", err)
+	}
+	stopBackground()
 
 	logger.Infof("Server stopped")
 }
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useCommonMiddleware 业务端口和管理端口共用的全局中间件
+func (s *Server) useCommonMiddleware(r *gin.Engine) {
+	// 链路追踪
+	r.Use(s.TracingMiddleware())
+
+	// 请求 ID
+	r.Use(s.RequestIDMiddleware())
+
+	// CORS 中间件
+	r.Use(s.corsMiddleware())
+
+	// 监控指标中间件
+	r.Use(s.MetricsMiddleware())
+}
+
+// healthCheck 健康检查，关闭过程中返回 503 使负载均衡停止转发新请求
+func (s *Server) healthCheck(c *gin.Context) {
+	if s.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// requireAdmin 要求已认证用户为管理员，各处理函数中的检查保留作为兜底
+func (s *Server) requireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("is_admin") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Administrator privileges required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireMetricsToken 配置了 metrics_token 时要求请求携带 Authorization: Bearer <token>
+func (s *Server) requireMetricsToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := s.cfg.Server.MetricsToken
+		if token == "" {
+			c.Next()
+			return
+		}
+		auth := c.GetHeader("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare([]byte(auth[len("Bearer "):]), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid metrics token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// registerPprof 在管理端口上注册性能分析接口
+func (s *Server) registerPprof(r *gin.Engine) {
+	debug := r.Group("/debug/pprof")
+	debug.Use(s.requireClientCert())
+	{
+		debug.GET("/", gin.WrapF(pprof.Index))
+		debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/profile", gin.WrapF(pprof.Profile))
+		debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/trace", gin.WrapF(pprof.Trace))
+		debug.GET("/:name", func(c *gin.Context) {
+			pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+		})
+	}
+}
@@ -34,6 +34,10 @@ func (s *Server) GetAuditLogs(c *gin.Context) {
 		filter.BucketName = bucketName
 	}
 
+	if requestID := c.Query("request_id"); requestID != "" {
+		filter.RequestID = requestID
+	}
+
 	if startTimeStr := c.Query("start_time"); startTimeStr != "" {
 		if startTime, err := time.Parse(time.RFC3339, startTimeStr); err == nil {
 			filter.StartTime = &startTime
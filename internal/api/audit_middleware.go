@@ -3,12 +3,18 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/metrics"
+	"github.com/gooss/server/internal/tracing"
+	"github.com/gooss/server/pkg/logger"
+	"github.com/gooss/server/pkg/requestid"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // AuditMiddleware 审计日志中间件
@@ -32,6 +38,9 @@ func (s *Server) AuditMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		_, span := tracing.Start(c.Request.Context(), "audit")
+		defer span.End()
+
 		// 提取用户信息
 		var userID *int64
 		var username string
@@ -75,17 +84,26 @@ func (s *Server) AuditMiddleware() gin.HandlerFunc {
 			CreatedAt:    startTime,
 		}
 
+		// 记录请求 ID，便于根据用户反馈的 x-amz-request-id 查找
+		requestID := requestid.FromContext(c.Request.Context())
+		if requestID != "" {
+			log.Metadata, _ = json.Marshal(map[string]string{"request_id": requestID})
+		}
+
 		// 如果有错误，记录错误信息
 		if len(c.Errors) > 0 {
 			log.ErrorMessage = c.Errors.String()
 		}
 
-		// 异步记录日志，避免影响性能
+		// 异步记录日志，避免影响性能；关闭时等待未完成的写入
+		// 写入在请求结束后完成，只沿用链路信息而不继承请求的取消
+		ctx := trace.ContextWithSpanContext(requestid.NewContext(context.Background(), requestID), span.SpanContext())
+		s.auditPending.Add(1)
 		go func() {
-			ctx := context.Background()
+			defer s.auditPending.Add(-1)
 			if err := s.repo.CreateAuditLog(ctx, log); err != nil {
-				// 仅记录错误到结构化日志，不输出到标准输出
-				// TODO: 使用 logger.Error("failed to create audit log", "error", err)
+				metrics.AuditLogWriteFailures.Inc()
+				logger.Ctx(ctx).Errorf("Failed to create audit log: %v", err)
 			}
 		}()
 	}
@@ -104,6 +122,38 @@ func (s *Server) parseAction(c *gin.Context) (action, resourceType, resourceName
 		return "", "", ""
 	}
 
+	// 管理接口只记录修改操作
+	if strings.HasPrefix(path, "/admin/") {
+		if method == "GET" || method == "HEAD" {
+			return "", "", ""
+		}
+		switch c.FullPath() {
+		case "/admin/users":
+			if method == "POST" {
+				return metadata.ActionCreateUser, metadata.ResourceTypeUser, ""
+			}
+		case "/admin/users/:id":
+			switch method {
+			case "PUT":
+				return metadata.ActionUpdateUser, metadata.ResourceTypeUser, c.Param("id")
+			case "DELETE":
+				return metadata.ActionDeleteUser, metadata.ResourceTypeUser, c.Param("id")
+			}
+		case "/admin/read-only", "/admin/read-only/buckets/:bucket":
+			resourceType := metadata.ResourceTypeCluster
+			if c.Param("bucket") != "" {
+				resourceType = metadata.ResourceTypeBucket
+			}
+			switch method {
+			case "PUT":
+				return metadata.ActionEnableReadOnly, resourceType, c.Param("bucket")
+			case "DELETE":
+				return metadata.ActionDisableReadOnly, resourceType, c.Param("bucket")
+			}
+		}
+		return metadata.ActionAdminOperation, metadata.ResourceTypeAdmin, method + " " + strings.TrimPrefix(path, "/admin")
+	}
+
 	// Bucket 操作
 	if strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "/api/") {
 		parts := strings.Split(strings.Trim(path, "/"), "/")
@@ -119,6 +169,44 @@ func (s *Server) parseAction(c *gin.Context) (action, resourceType, resourceName
 			}
 		}
 
+		// Bucket 复制配置
+		if _, ok := c.GetQuery("replication"); ok && len(parts) == 1 {
+			switch method {
+			case "PUT":
+				return metadata.ActionSetReplication, metadata.ResourceTypeBucket, parts[0]
+			case "DELETE":
+				return metadata.ActionDeleteReplication, metadata.ResourceTypeBucket, parts[0]
+			}
+			return "", "", ""
+		}
+
+		// Object Lock 配置、保留与法律保留
+		for _, sub := range []string{"object-lock", "retention", "legal-hold"} {
+			if _, ok := c.GetQuery(sub); ok && method == "PUT" {
+				if len(parts) > 1 {
+					return metadata.ActionSetObjectLock, metadata.ResourceTypeObject, strings.Join(parts[1:], "/")
+				}
+				return metadata.ActionSetObjectLock, metadata.ResourceTypeBucket, parts[0]
+			}
+		}
+
+		// Tagging 操作
+		if _, ok := c.GetQuery("tagging"); ok {
+			resourceType := metadata.ResourceTypeBucket
+			resourceName := parts[0]
+			if len(parts) > 1 {
+				resourceType = metadata.ResourceTypeObject
+				resourceName = strings.Join(parts[1:], "/")
+			}
+			switch method {
+			case "PUT":
+				return metadata.ActionSetTagging, resourceType, resourceName
+			case "DELETE":
+				return metadata.ActionDeleteTagging, resourceType, resourceName
+			}
+			return "", "", ""
+		}
+
 		switch len(parts) {
 		case 0, 1:
 			// Bucket 级别操作
@@ -131,9 +219,9 @@ func (s *Server) parseAction(c *gin.Context) (action, resourceType, resourceName
 					return metadata.ActionDeleteBucket, metadata.ResourceTypeBucket, bucketName
 				}
 			}
-		case 2:
-			// Object 级别操作
-			objectKey := parts[1]
+		default:
+			// Object 级别操作，key 可能包含多级路径
+			objectKey := strings.Join(parts[1:], "/")
 			switch method {
 			case "PUT":
 				return metadata.ActionUploadObject, metadata.ResourceTypeObject, objectKey
@@ -171,6 +259,9 @@ func (s *Server) parseAction(c *gin.Context) (action, resourceType, resourceName
 // extractResourceInfo 提取资源信息
 func (s *Server) extractResourceInfo(c *gin.Context) (bucketName, objectKey string) {
 	path := c.Request.URL.Path
+	if strings.HasPrefix(path, "/admin/") {
+		return c.Param("bucket"), ""
+	}
 	if strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "/api/") {
 		parts := strings.Split(strings.Trim(path, "/"), "/")
 		if len(parts) >= 1 {
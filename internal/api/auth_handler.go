@@ -66,7 +66,7 @@ func (s *Server) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, LoginResponse{
 		AccessKey: cred.AccessKey,
 		SecretKey: cred.SecretKey,
-		Endpoint:  s.cfg.Server.APIEndpoint,
+		Endpoint:  s.apiEndpoint(c),
 		Username:  user.Username,
 		IsAdmin:   user.IsAdmin,
 	})
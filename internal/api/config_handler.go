@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/pkg/config"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// effectiveConfig 返回当前生效的配置：启动时的配置叠加已热加载的配置项
+func (s *Server) effectiveConfig() *config.Config {
+	return s.effective.Load()
+}
+
+// ApplyConfig 应用配置文件的变化。CORS 来源、只读开关、日志级别和限流配置立即生效，
+// 其余配置项记录为需要重启
+func (s *Server) ApplyConfig(cfg *config.Config, err error) {
+	if err != nil {
+		logger.Errorf("Failed to reload config, keeping current settings: %v", err)
+		return
+	}
+
+	current := s.effectiveConfig()
+	next := *current
+
+	var applied, restart []string
+	for _, key := range config.Diff(current, cfg) {
+		if config.HotReloadable(key) {
+			applied = append(applied, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+
+	next.Server.AllowedOrigins = cfg.Server.AllowedOrigins
+	next.Server.ReadOnly = cfg.Server.ReadOnly
+	next.Server.ReadOnlyBuckets = cfg.Server.ReadOnlyBuckets
+	if next.Logging.Level != cfg.Logging.Level {
+		if err := logger.SetLevel(cfg.Logging.Level); err != nil {
+			logger.Warnf("Invalid logging.level %q, keeping %q", cfg.Logging.Level, next.Logging.Level)
+		} else {
+			next.Logging.Level = cfg.Logging.Level
+		}
+	}
+	backend := next.Limits.RateLimitBackend
+	next.Limits = cfg.Limits
+	next.Limits.RateLimitBackend = backend
+	s.limiter.Update(next.Limits)
+	s.effective.Store(&next)
+	s.auditReadOnlyConfig(current.Server, next.Server)
+
+	if len(applied) > 0 {
+		logger.Infof("Config reloaded, applied: %v", applied)
+	}
+	if len(restart) > 0 {
+		logger.Warnf("Config changes require a restart to take effect: %v", restart)
+	}
+}
+
+// GetConfig 查看当前生效的配置（仅管理员），敏感配置项已隐藏。
+// restart_required 列出配置文件中已修改但需重启才能生效的配置项
+func (s *Server) GetConfig(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view the configuration"})
+		return
+	}
+
+	effective := s.effectiveConfig()
+	restart := []string{}
+	if latest := config.Get(); latest != nil {
+		for _, key := range config.Diff(effective, latest) {
+			if !config.HotReloadable(key) {
+				restart = append(restart, key)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"config":           effective.Flatten(true),
+		"restart_required": restart,
+	})
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/maintenance"
+)
+
+// GetMultipartGCStats 获取分片上传清理统计（仅管理员）
+func (s *Server) GetMultipartGCStats(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view maintenance status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.multipartGC.Stats())
+}
+
+// RunMultipartGC 立即执行分片上传清理，dry_run=true 时只预览（仅管理员）
+func (s *Server) RunMultipartGC(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can run maintenance tasks"})
+		return
+	}
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run value"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	report := s.multipartGC.Run(c.Request.Context(), dryRun)
+	c.JSON(http.StatusOK, report)
+}
+
+// RunFsck 执行元数据与数据一致性检查，repair=true 时修复可自动处理的问题（仅管理员）
+func (s *Server) RunFsck(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can run maintenance tasks"})
+		return
+	}
+
+	opts := maintenance.FsckOptions{Bucket: c.Query("bucket")}
+	for name, dst := range map[string]*bool{"repair": &opts.Repair, "skip_hash": &opts.SkipHash} {
+		if v := c.Query(name); v != "" {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name + " value"})
+				return
+			}
+			*dst = parsed
+		}
+	}
+
+	report, err := s.fsck.Run(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetScrubStats 获取数据完整性巡检统计（仅管理员）
+func (s *Server) GetScrubStats(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view maintenance status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.scrubber.Stats(c.Request.Context()))
+}
+
+// RunScrub 立即执行一轮数据完整性巡检（仅管理员）
+func (s *Server) RunScrub(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can run maintenance tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.scrubber.Run(c.Request.Context()))
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsMiddleware 记录请求数、耗时、流量和并发请求数
+func (s *Server) MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "/health" || route == "/metrics" {
+			c.Next()
+			return
+		}
+
+		startTime := time.Now()
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		var body *countingReadCloser
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body = &countingReadCloser{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+
+		c.Next()
+
+		operation := s.metricsOperation(c)
+		metrics.HTTPRequestsTotal.WithLabelValues(operation, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(operation).Observe(time.Since(startTime).Seconds())
+		if body != nil {
+			metrics.HTTPReceivedBytes.WithLabelValues(operation).Add(float64(body.n.Load()))
+		}
+		if size := c.Writer.Size(); size > 0 {
+			metrics.HTTPSentBytes.WithLabelValues(operation).Add(float64(size))
+		}
+	}
+}
+
+// metricsOperation 确定请求的指标标签：写操作沿用审计日志的分类，
+// 其余 S3 请求按方法和资源层级归类，管理接口使用路由模板
+func (s *Server) metricsOperation(c *gin.Context) string {
+	route := c.FullPath()
+	if route == "" {
+		return "UNMATCHED"
+	}
+	for _, prefix := range []string{"/admin", "/auth", "/user"} {
+		if strings.HasPrefix(route, prefix) {
+			return c.Request.Method + " " + route
+		}
+	}
+
+	if action, _, _ := s.parseAction(c); action != "" {
+		return action
+	}
+
+	level := "SERVICE"
+	if c.Param("key") != "" && c.Param("key") != "/" {
+		level = "OBJECT"
+	} else if c.Param("bucket") != "" {
+		level = "BUCKET"
+	}
+	return c.Request.Method + "_" + level
+}
+
+// countingReadCloser 统计请求体实际读取的字节数
+type countingReadCloser struct {
+	io.ReadCloser
+	n atomic.Int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n.Add(int64(n))
+	return n, err
+}
+
+// registerMetrics 将依赖服务状态的指标注册到本实例的 metricsRegistry，
+// 同一进程中创建多个 Server 时不会重复注册到全局注册表
+func (s *Server) registerMetrics() {
+	if p, ok := s.repo.(interface{ PoolStat() *pgxpool.Stat }); ok {
+		s.metricsRegistry.MustRegister(metrics.NewPoolCollector(p.PoolStat))
+	}
+	s.metricsRegistry.MustRegister(metrics.NewRepositoryCollector(s.repo))
+
+	// 后台维护任务
+	counter := func(subsystem, name, help string, value func() int64) {
+		promauto.With(s.metricsRegistry).NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "oss", Subsystem: subsystem, Name: name, Help: help,
+		}, func() float64 { return float64(value()) })
+	}
+	counter("multipart_gc", "uploads_aborted_total", "Total number of stale multipart uploads aborted.",
+		func() int64 { return s.multipartGC.Stats().UploadsAborted })
+	counter("multipart_gc", "orphans_removed_total", "Total number of orphan multipart directories removed.",
+		func() int64 { return s.multipartGC.Stats().OrphansRemoved })
+	counter("multipart_gc", "bytes_freed_total", "Total bytes freed by multipart GC.",
+		func() int64 { return s.multipartGC.Stats().BytesFreed })
+	counter("scrub", "objects_verified_total", "Total number of objects verified by the scrubber.",
+		func() int64 { return s.scrubber.Counters().ObjectsVerified })
+	counter("scrub", "bytes_read_total", "Total bytes read by the scrubber.",
+		func() int64 { return s.scrubber.Counters().BytesRead })
+	counter("scrub", "corrupt_found_total", "Total number of corrupt or missing objects found.",
+		func() int64 { return s.scrubber.Counters().CorruptFound })
+	counter("scrub", "repaired_total", "Total number of objects repaired from the redundant copy.",
+		func() int64 { return s.scrubber.Counters().Repaired })
+}
+
+// MetricsHandler Prometheus 指标处理器，同时输出全局注册的请求指标和本实例的指标
+func (s *Server) MetricsHandler() http.Handler {
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer, s.metricsRegistry}
+	return promhttp.InstrumentMetricHandler(s.metricsRegistry, promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage/local"
+	"github.com/gooss/server/pkg/config"
+	"github.com/gooss/server/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Init("error", "console", "stderr", ""); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// metricsRepo 只实现指标采集用到的方法
+type metricsRepo struct {
+	metadata.Repository
+}
+
+func (metricsRepo) ListAllBuckets(ctx context.Context) ([]metadata.Bucket, error) {
+	return nil, nil
+}
+
+func (metricsRepo) CountMultipartUploads(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestNewServerRegistersMetricsPerServer(t *testing.T) {
+	var servers []*Server
+	for i := 0; i < 2; i++ {
+		engine, err := local.New(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		servers = append(servers, NewServer(&config.Config{}, engine, metricsRepo{}))
+	}
+
+	for _, s := range servers {
+		rec := httptest.NewRecorder()
+		s.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("metrics status = %d", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "oss_scrub_objects_verified_total") {
+			t.Errorf("metrics output is missing scrub counters")
+		}
+	}
+}
@@ -2,67 +2,89 @@ package api
 
 import (
 	"context"
-	"encoding/xml"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
-	"sync"
-	"time"
+	"path"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gooss/server/internal/auth"
 	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/migration"
 	"github.com/gooss/server/internal/storage"
 	"github.com/gooss/server/pkg/logger"
 )
 
+// defaultMigrationErrorLimit 任务详情中返回的最近错误条数
+const defaultMigrationErrorLimit = 100
+
 type MigrationHandler struct {
-	storage storage.Engine
 	repo    metadata.Repository
-	region  string
+	manager *migration.Manager
 }
 
-func NewMigrationHandler(storage storage.Engine, repo metadata.Repository, region string) *MigrationHandler {
+func NewMigrationHandler(storage storage.Engine, repo metadata.Repository, region string, opts migration.Options) *MigrationHandler {
 	return &MigrationHandler{
-		storage: storage,
 		repo:    repo,
-		region:  region,
+		manager: migration.NewManager(storage, repo, region, opts),
 	}
 }
 
+// Start 启动任务调度，接管重启前未完成的任务
+func (h *MigrationHandler) Start(ctx context.Context) {
+	h.manager.Start(ctx)
+}
+
 type MigrationRequest struct {
 	SourceEndpoint string `json:"sourceEndpoint" binding:"required"`
 	AccessKey      string `json:"accessKey" binding:"required"`
 	SecretKey      string `json:"secretKey" binding:"required"`
 	Region         string `json:"region"`
+	// Mode 为 sync 时只复制新增或变化的对象；DeleteMissing 同时删除源端已不存在的对象；
+	// SyncInterval（如 "1h"）非空时按间隔重复同步，用于切换前保持目标与源端一致
+	Mode          string `json:"mode"`
+	DeleteMissing bool   `json:"deleteMissing"`
+	SyncInterval  string `json:"syncInterval"`
+	// 并发数和带宽上限（字节/秒），不填时使用配置文件中的 migration 设置
+	Concurrency    int   `json:"concurrency"`
+	BytesPerSecond int64 `json:"bytesPerSecond"`
+	// 迁移范围：IncludeBuckets/ExcludeBuckets 支持通配符（如 "logs-*"），Prefixes 按源 Bucket 限定前缀，
+	// BucketMapping 将源 Bucket 改名写入。TargetOwner 为目标 Bucket 所属用户名，默认为发起人
+	IncludeBuckets []string          `json:"includeBuckets"`
+	ExcludeBuckets []string          `json:"excludeBuckets"`
+	Prefixes       map[string]string `json:"prefixes"`
+	BucketMapping  map[string]string `json:"bucketMapping"`
+	TargetOwner    string            `json:"targetOwner"`
+	// DryRun 只统计将要迁移的对象数和字节数，不写入任何数据
+	DryRun bool `json:"dryRun"`
 }
 
+// MigrationProgress 迁移任务详情：任务状态、各 Bucket 进度（含 Bucket 配置的迁移结果）、对象校验汇总和最近的错误
 type MigrationProgress struct {
-	Status           string   `json:"status"`
-	CurrentBucket    string   `json:"currentBucket"`
-	TotalBuckets     int      `json:"totalBuckets"`
-	CurrentObject    string   `json:"currentObject"`
-	TotalObjects     int      `json:"totalObjects"`
-	CompletedObjects int      `json:"completedObjects"`
-	Errors           []string `json:"errors"`
-}
-
-type BucketInfo struct {
-	Name         string
-	CreationDate string
+	*metadata.MigrationJob
+	CompletedBuckets int                        `json:"completed_buckets"`
+	ListedObjects    int64                      `json:"listed_objects"`
+	ListedBytes      int64                      `json:"listed_bytes"`
+	CompletedObjects int64                      `json:"completed_objects"`
+	FailedObjects    int64                      `json:"failed_objects"`
+	SkippedObjects   int64                      `json:"skipped_objects"`
+	DeletedObjects   int64                      `json:"deleted_objects"`
+	CompletedBytes   int64                      `json:"completed_bytes"`
+	Buckets          []metadata.MigrationBucket `json:"buckets"`
+	Errors           []metadata.MigrationError  `json:"errors"`
+
+	Report *metadata.MigrationObjectSummary `json:"report"`
 }
 
-type ObjectInfo struct {
-	Key          string
-	Size         int64
-	ETag         string
-	ContentType  string
-	LastModified string
-}
-
-// StartMigration 启动迁移任务
+// StartMigration 创建迁移任务，任务在后台执行，进度通过 GET /admin/migration/:id 查询
 func (h *MigrationHandler) StartMigration(c *gin.Context) {
-	userID := c.GetInt64("user_id")
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can start migrations"})
+		return
+	}
 
 	var req MigrationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -70,319 +92,320 @@ func (h *MigrationHandler) StartMigration(c *gin.Context) {
 		return
 	}
 
-	// 规范化源端点
-	sourceEndpoint := strings.TrimSuffix(req.SourceEndpoint, "/")
-	if !strings.HasPrefix(sourceEndpoint, "http://") && !strings.HasPrefix(sourceEndpoint, "https://") {
-		sourceEndpoint = "http://" + sourceEndpoint
-	}
-
 	if req.Region == "" {
 		req.Region = "us-east-1"
 	}
+	switch req.Mode {
+	case "":
+		req.Mode = metadata.MigrationModeCopy
+	case metadata.MigrationModeCopy, metadata.MigrationModeSync:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be copy or sync"})
+		return
+	}
+	if req.Mode != metadata.MigrationModeSync && (req.DeleteMissing || req.SyncInterval != "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "deleteMissing and syncInterval require sync mode"})
+		return
+	}
+	if req.Concurrency < 0 || req.BytesPerSecond < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "concurrency and bytesPerSecond must not be negative"})
+		return
+	}
+	if req.SyncInterval != "" {
+		if _, err := migration.ParseSyncInterval(req.SyncInterval); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.DryRun && req.SyncInterval != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dryRun cannot be combined with syncInterval"})
+		return
+	}
+	filters := metadata.MigrationFilters{
+		IncludeBuckets: req.IncludeBuckets,
+		ExcludeBuckets: req.ExcludeBuckets,
+		Prefixes:       req.Prefixes,
+		BucketMapping:  req.BucketMapping,
+	}
+	if err := validateMigrationFilters(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerID := c.GetInt64("user_id")
+	if req.TargetOwner != "" {
+		owner, err := h.repo.GetUserByUsername(c.Request.Context(), req.TargetOwner)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if owner == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Target owner not found: " + req.TargetOwner})
+			return
+		}
+		ownerID = owner.ID
+	}
 
-	logger.Infof("Starting migration from %s", sourceEndpoint)
+	job := &metadata.MigrationJob{
+		ID:             uuid.New().String(),
+		SourceEndpoint: migration.NormalizeEndpoint(req.SourceEndpoint),
+		SourceRegion:   req.Region,
+		AccessKey:      req.AccessKey,
+		SecretKey:      req.SecretKey,
+		OwnerID:        ownerID,
+		CreatedBy:      c.GetString("username"),
+		Mode:           req.Mode,
+		DeleteMissing:  req.DeleteMissing,
+		SyncInterval:   req.SyncInterval,
+		Concurrency:    req.Concurrency,
+		BytesPerSecond: req.BytesPerSecond,
+		Filters:        filters,
+		DryRun:         req.DryRun,
+	}
+	if _, err := migration.NewClient(job.SourceEndpoint, job.AccessKey, job.SecretKey, job.SourceRegion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// 在后台执行迁移
-	go h.performMigration(context.Background(), userID, sourceEndpoint, req.AccessKey, req.SecretKey, req.Region)
+	if err := h.manager.Submit(c.Request.Context(), job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Ctx(c.Request.Context()).Infof("Migration job %s (%s, dry run: %v) created from %s by %s", job.ID, job.Mode, job.DryRun, job.SourceEndpoint, job.CreatedBy)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Migration started",
-		"status":  "in_progress",
+		"job":     job,
 	})
 }
 
-// performMigration 执行实际的迁移操作
-func (h *MigrationHandler) performMigration(ctx context.Context, userID int64, endpoint, accessKey, secretKey, region string) {
-	logger.Infof("Starting migration for user %d from %s", userID, endpoint)
-
-	// 1. 列出源服务器的所有存储桶
-	buckets, err := h.listSourceBuckets(endpoint, accessKey, secretKey, region)
-	if err != nil {
-		logger.Errorf("Failed to list source buckets: %v", err)
+// ListMigrations 列出迁移任务，支持 status、limit、offset 参数
+func (h *MigrationHandler) ListMigrations(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view migrations"})
 		return
 	}
 
-	logger.Infof("Found %d buckets to migrate", len(buckets))
-
-	// 2. 逐个迁移存储桶
-	for _, bucket := range buckets {
-		logger.Infof("Migrating bucket: %s", bucket.Name)
-
-		if err := h.migrateBucket(ctx, userID, endpoint, accessKey, secretKey, region, bucket); err != nil {
-			logger.Errorf("Failed to migrate bucket %s: %v", bucket.Name, err)
-			continue
+	filter := &metadata.MigrationJobFilter{Status: c.Query("status"), Limit: 50}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = limit
 		}
-
-		logger.Infof("Successfully migrated bucket: %s", bucket.Name)
 	}
-
-	logger.Infof("Migration completed for user %d", userID)
-}
-
-// migrateBucket 迁移单个存储桶
-func (h *MigrationHandler) migrateBucket(ctx context.Context, userID int64, endpoint, accessKey, secretKey, region string, sourceBucket BucketInfo) error {
-	bucketName := sourceBucket.Name
-
-	// 1. 检查目标是否已存在
-	existing, err := h.repo.GetBucketByName(ctx, bucketName)
-	if err != nil {
-		return fmt.Errorf("failed to check bucket: %w", err)
-	}
-
-	var targetBucket *metadata.Bucket
-
-	if existing != nil {
-		logger.Infof("Bucket %s already exists, using existing bucket", bucketName)
-		targetBucket = existing
-	} else {
-		// 2. 创建目标存储桶
-		if err := h.storage.CreateBucket(ctx, bucketName); err != nil {
-			return fmt.Errorf("failed to create bucket: %w", err)
-		}
-
-		targetBucket = &metadata.Bucket{
-			Name:    bucketName,
-			OwnerID: userID,
-			Region:  h.region,
-			ACL:     "private",
-		}
-
-		if err := h.repo.CreateBucket(ctx, targetBucket); err != nil {
-			return fmt.Errorf("failed to save bucket metadata: %w", err)
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
 		}
 	}
 
-	// 3. 列出源存储桶中的所有对象
-	objects, err := h.listSourceObjects(endpoint, accessKey, secretKey, region, bucketName)
+	jobs, err := h.repo.ListMigrationJobs(c.Request.Context(), filter)
 	if err != nil {
-		return fmt.Errorf("failed to list objects: %w", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	logger.Infof("Found %d objects in bucket %s", len(objects), bucketName)
-
-	// 4. 并发迁移对象（限制并发数）
-	const maxConcurrent = 10
-	sem := make(chan struct{}, maxConcurrent)
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(objects))
-
-	for _, obj := range objects {
-		wg.Add(1)
-		go func(obj ObjectInfo) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":  jobs,
+		"count": len(jobs),
+	})
+}
 
-			if err := h.migrateObject(ctx, endpoint, accessKey, secretKey, region, bucketName, targetBucket.ID, obj); err != nil {
-				logger.Errorf("Failed to migrate object %s: %v", obj.Key, err)
-				errChan <- err
-			}
-		}(obj)
+// GetMigration 查看迁移任务进度，errors_limit、errors_offset 参数分页查看错误
+func (h *MigrationHandler) GetMigration(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view migrations"})
+		return
 	}
 
-	wg.Wait()
-	close(errChan)
-
-	// 收集错误
-	var errors []error
-	for err := range errChan {
-		errors = append(errors, err)
+	ctx := c.Request.Context()
+	job, err := h.repo.GetMigrationJob(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("migration completed with %d errors", len(errors))
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Migration job not found"})
+		return
 	}
 
-	return nil
-}
-
-// migrateObject 迁移单个对象
-func (h *MigrationHandler) migrateObject(ctx context.Context, endpoint, accessKey, secretKey, region, bucketName string, targetBucketID int64, obj ObjectInfo) error {
-	// 1. 从源下载对象
-	reader, contentType, err := h.getSourceObject(endpoint, accessKey, secretKey, region, bucketName, obj.Key)
+	buckets, err := h.repo.ListMigrationBuckets(ctx, job.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get source object: %w", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-	defer reader.Close()
 
-	// 2. 上传到目标
-	if contentType == "" {
-		contentType = obj.ContentType
+	limit, offset := defaultMigrationErrorLimit, 0
+	if limitStr := c.Query("errors_limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
 	}
-
-	objInfo, err := h.storage.Put(ctx, bucketName, obj.Key, reader, obj.Size, contentType)
+	if offsetStr := c.Query("errors_offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+	errs, err := h.repo.ListMigrationErrors(ctx, job.ID, limit, offset)
 	if err != nil {
-		return fmt.Errorf("failed to put object: %w", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	// 3. 保存元数据
-	targetObj := &metadata.Object{
-		BucketID:     targetBucketID,
-		Key:          obj.Key,
-		Size:         objInfo.Size,
-		ETag:         objInfo.ETag,
-		ContentType:  contentType,
-		StorageClass: "STANDARD",
-		StoragePath:  objInfo.StoragePath,
+	report, err := h.repo.SummarizeMigrationObjects(ctx, job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := h.repo.CreateObject(ctx, targetObj); err != nil {
-		return fmt.Errorf("failed to save object metadata: %w", err)
+	progress := &MigrationProgress{MigrationJob: job, Buckets: buckets, Errors: errs, Report: report}
+	for _, b := range buckets {
+		if b.Status == metadata.MigrationStatusCompleted {
+			progress.CompletedBuckets++
+		}
+		progress.ListedObjects += b.ListedObjects
+		progress.ListedBytes += b.ListedBytes
+		progress.CompletedObjects += b.CompletedObjects
+		progress.FailedObjects += b.FailedObjects
+		progress.SkippedObjects += b.SkippedObjects
+		progress.DeletedObjects += b.DeletedObjects
+		progress.CompletedBytes += b.CompletedBytes
 	}
 
-	return nil
+	c.JSON(http.StatusOK, progress)
 }
 
-// listSourceBuckets 列出源S3服务器的所有存储桶
-func (h *MigrationHandler) listSourceBuckets(endpoint, accessKey, secretKey, region string) ([]BucketInfo, error) {
-	client := &http.Client{}
-
-	req, err := http.NewRequest("GET", endpoint+"/", nil)
-	if err != nil {
-		return nil, err
+// GetMigrationReport 逐对象的迁移报告：校验结果、元数据和标签数量、未能保留的属性。
+// 支持 bucket（源 Bucket）、issues=true（只看有问题的对象）、limit、offset 参数
+func (h *MigrationHandler) GetMigrationReport(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view migrations"})
+		return
 	}
 
-	// 添加基本的认证头（MinIO/S3兼容）
-	req.Header.Set("Authorization", fmt.Sprintf("AWS %s:%s", accessKey, secretKey))
-	req.Header.Set("Host", req.URL.Host)
-	req.Header.Set("Date", time.Now().UTC().Format(time.RFC1123))
-
-	resp, err := client.Do(req)
+	ctx := c.Request.Context()
+	job, err := h.repo.GetMigrationJob(ctx, c.Param("id"))
 	if err != nil {
-		return nil, err
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("list buckets failed: %d - %s", resp.StatusCode, string(body))
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Migration job not found"})
+		return
 	}
 
-	// 解析XML响应
-	var result struct {
-		Buckets struct {
-			Bucket []struct {
-				Name         string `xml:"Name"`
-				CreationDate string `xml:"CreationDate"`
-			} `xml:"Bucket"`
-		} `xml:"Buckets"`
+	filter := &metadata.MigrationObjectFilter{
+		SourceBucket: c.Query("bucket"),
+		IssuesOnly:   c.Query("issues") == "true",
+		Limit:        1000,
 	}
-
-	if err := parseXMLResponse(resp.Body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
 	}
 
-	buckets := make([]BucketInfo, 0, len(result.Buckets.Bucket))
-	for _, b := range result.Buckets.Bucket {
-		buckets = append(buckets, BucketInfo{
-			Name:         b.Name,
-			CreationDate: b.CreationDate,
-		})
+	objects, err := h.repo.ListMigrationObjects(ctx, job.ID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	return buckets, nil
+	c.JSON(http.StatusOK, gin.H{
+		"objects": objects,
+		"count":   len(objects),
+	})
 }
 
-// listSourceObjects 列出源存储桶中的所有对象
-func (h *MigrationHandler) listSourceObjects(endpoint, accessKey, secretKey, region, bucket string) ([]ObjectInfo, error) {
-	var objects []ObjectInfo
-	marker := ""
-
-	for {
-		url := fmt.Sprintf("%s/%s?max-keys=1000", endpoint, bucket)
-		if marker != "" {
-			url += "&marker=" + marker
-		}
+// CancelMigration 取消未结束的迁移任务（包括等待下一轮的同步任务），已完成的对象保留
+func (h *MigrationHandler) CancelMigration(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can cancel migrations"})
+		return
+	}
 
-		client := &http.Client{}
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
+	id := c.Param("id")
+	ok, err := h.manager.Cancel(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		h.notActionable(c, id, "Migration job has already finished")
+		return
+	}
+	logger.Ctx(c.Request.Context()).Infof("Migration job %s cancelled by %s", id, c.GetString("username"))
 
-		req.Header.Set("Authorization", fmt.Sprintf("AWS4 %s:%s", accessKey, secretKey))
-		req.Header.Set("Host", req.URL.Host)
-		req.Header.Set("Date", time.Now().UTC().Format(time.RFC1123))
-		req.Header.Set("X-Amz-Date", time.Now().UTC().Format(time.RFC1123))
+	c.JSON(http.StatusOK, gin.H{"message": "Migration cancelled"})
+}
 
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, err
-		}
+// ResumeMigration 从最后完成的 Key 继续失败或已取消的任务
+func (h *MigrationHandler) ResumeMigration(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can resume migrations"})
+		return
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			return nil, fmt.Errorf("list objects failed: %d - %s", resp.StatusCode, string(body))
+	id := c.Param("id")
+	ok, err := h.manager.Resume(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, migration.ErrNotStarted) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		h.notActionable(c, id, "Only failed or cancelled migration jobs can be resumed")
+		return
+	}
+	logger.Ctx(c.Request.Context()).Infof("Migration job %s resumed by %s", id, c.GetString("username"))
 
-		var result struct {
-			IsTruncated bool   `xml:"IsTruncated"`
-			NextMarker  string `xml:"NextMarker"`
-			Contents    []struct {
-				Key          string `xml:"Key"`
-				Size         int64  `xml:"Size"`
-				ETag         string `xml:"ETag"`
-				LastModified string `xml:"LastModified"`
-			} `xml:"Contents"`
-		}
+	c.JSON(http.StatusOK, gin.H{"message": "Migration resumed"})
+}
 
-		if err := parseXMLResponse(resp.Body, &result); err != nil {
-			resp.Body.Close()
-			return nil, err
+// validateMigrationFilters 校验通配符语法和改名后的 Bucket 名称，不同源 Bucket 不能映射到同一目标
+func validateMigrationFilters(f *metadata.MigrationFilters) error {
+	for _, patterns := range [][]string{f.IncludeBuckets, f.ExcludeBuckets} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid bucket pattern %q", p)
+			}
 		}
-		resp.Body.Close()
-
-		for _, obj := range result.Contents {
-			objects = append(objects, ObjectInfo{
-				Key:          obj.Key,
-				Size:         obj.Size,
-				ETag:         strings.Trim(obj.ETag, "\""),
-				LastModified: obj.LastModified,
-			})
+	}
+	targets := make(map[string]string, len(f.BucketMapping))
+	for source, target := range f.BucketMapping {
+		if err := auth.ValidateBucketName(target); err != nil {
+			return fmt.Errorf("invalid target bucket name %q: %v", target, err)
 		}
-
-		if !result.IsTruncated {
-			break
+		if other, ok := targets[target]; ok {
+			return fmt.Errorf("buckets %s and %s are both mapped to %s", other, source, target)
 		}
-		marker = result.NextMarker
+		targets[target] = source
 	}
-
-	return objects, nil
+	return nil
 }
 
-// getSourceObject 从源获取对象内容
-func (h *MigrationHandler) getSourceObject(endpoint, accessKey, secretKey, region, bucket, key string) (io.ReadCloser, string, error) {
-	url := fmt.Sprintf("%s/%s/%s", endpoint, bucket, key)
-
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, "", err
-	}
-
-	// 添加基本的认证头（MinIO/S3兼容）
-	req.Header.Set("Authorization", fmt.Sprintf("AWS %s:%s", accessKey, secretKey))
-	req.Header.Set("Host", req.URL.Host)
-	req.Header.Set("Date", time.Now().UTC().Format(time.RFC1123))
-
-	resp, err := client.Do(req)
+// notActionable 任务不存在时返回 404，状态不允许该操作时返回 409
+func (h *MigrationHandler) notActionable(c *gin.Context, id, message string) {
+	job, err := h.repo.GetMigrationJob(c.Request.Context(), id)
 	if err != nil {
-		return nil, "", err
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, "", fmt.Errorf("get object failed: %d - %s", resp.StatusCode, string(body))
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Migration job not found"})
+		return
 	}
-
-	contentType := resp.Header.Get("Content-Type")
-	return resp.Body, contentType, nil
-}
-
-// parseXMLResponse 解析XML响应
-func parseXMLResponse(reader io.Reader, v interface{}) error {
-	decoder := xml.NewDecoder(reader)
-	return decoder.Decode(v)
+	c.JSON(http.StatusConflict, gin.H{"error": message, "status": job.Status})
 }
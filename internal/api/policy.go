@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/api/s3"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// policyDenied 判断请求是否被所访问 Bucket 的策略显式拒绝。
+// Bucket 所有者和管理员始终可以管理策略本身，避免被错误的拒绝策略锁死
+func (s *Server) policyDenied(c *gin.Context, user *metadata.User) bool {
+	bucketName := c.Param("bucket")
+	if bucketName == "" {
+		return false
+	}
+
+	ctx := c.Request.Context()
+	bucket, err := s.repo.GetBucketByName(ctx, bucketName)
+	if err != nil || bucket == nil {
+		return false
+	}
+	policyData, err := s.repo.GetBucketPolicy(ctx, bucket.ID)
+	if err != nil || policyData == nil {
+		return false
+	}
+	policy, err := metadata.ParseBucketPolicy(policyData)
+	if err != nil {
+		logger.Ctx(ctx).Warnf("Invalid policy on bucket %s: %v", bucketName, err)
+		return false
+	}
+
+	if _, ok := c.GetQuery("policy"); ok && (user.IsAdmin || user.ID == bucket.OwnerID) {
+		return false
+	}
+	return policy.Denies(user.Username, policyAction(c), s3.PolicyConditions(c.Request))
+}
+
+// policyAction 将请求映射为 Bucket 策略中的 S3 操作名
+func policyAction(c *gin.Context) string {
+	has := func(name string) bool {
+		_, ok := c.GetQuery(name)
+		return ok
+	}
+
+	key := c.Param("key")
+	if key == "" || key == "/" {
+		switch c.Request.Method {
+		case http.MethodPut:
+			switch {
+			case has("policy"):
+				return "s3:PutBucketPolicy"
+			case has("tagging"):
+				return "s3:PutBucketTagging"
+			case has("object-lock"):
+				return "s3:PutBucketObjectLockConfiguration"
+			case has("replication"):
+				return "s3:PutReplicationConfiguration"
+			}
+			return "s3:CreateBucket"
+		case http.MethodDelete:
+			switch {
+			case has("policy"):
+				return "s3:DeleteBucketPolicy"
+			case has("tagging"):
+				return "s3:PutBucketTagging"
+			case has("replication"):
+				return "s3:PutReplicationConfiguration"
+			}
+			return "s3:DeleteBucket"
+		default:
+			switch {
+			case has("policy"):
+				return "s3:GetBucketPolicy"
+			case has("tagging"):
+				return "s3:GetBucketTagging"
+			case has("object-lock"):
+				return "s3:GetBucketObjectLockConfiguration"
+			case has("replication"):
+				return "s3:GetReplicationConfiguration"
+			case has("uploads"):
+				return "s3:ListBucketMultipartUploads"
+			}
+			return "s3:ListBucket"
+		}
+	}
+
+	switch c.Request.Method {
+	case http.MethodPut:
+		switch {
+		case has("tagging"):
+			return "s3:PutObjectTagging"
+		case has("retention"):
+			return "s3:PutObjectRetention"
+		case has("legal-hold"):
+			return "s3:PutObjectLegalHold"
+		}
+		return "s3:PutObject"
+	case http.MethodPost:
+		return "s3:PutObject"
+	case http.MethodDelete:
+		switch {
+		case has("tagging"):
+			return "s3:DeleteObjectTagging"
+		case has("uploadId"):
+			return "s3:AbortMultipartUpload"
+		}
+		return "s3:DeleteObject"
+	default:
+		switch {
+		case has("tagging"):
+			return "s3:GetObjectTagging"
+		case has("retention"):
+			return "s3:GetObjectRetention"
+		case has("legal-hold"):
+			return "s3:GetObjectLegalHold"
+		case has("uploadId"):
+			return "s3:ListMultipartUploadParts"
+		}
+		return "s3:GetObject"
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPolicyAction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		method string
+		key    string
+		query  string
+		want   string
+	}{
+		{http.MethodGet, "", "", "s3:ListBucket"},
+		{http.MethodHead, "/", "", "s3:ListBucket"},
+		{http.MethodPut, "", "", "s3:CreateBucket"},
+		{http.MethodDelete, "", "", "s3:DeleteBucket"},
+		{http.MethodGet, "", "policy", "s3:GetBucketPolicy"},
+		{http.MethodPut, "", "policy", "s3:PutBucketPolicy"},
+		{http.MethodDelete, "", "policy", "s3:DeleteBucketPolicy"},
+		{http.MethodGet, "", "tagging", "s3:GetBucketTagging"},
+		{http.MethodPut, "", "tagging", "s3:PutBucketTagging"},
+		{http.MethodDelete, "", "tagging", "s3:PutBucketTagging"},
+		{http.MethodGet, "", "object-lock", "s3:GetBucketObjectLockConfiguration"},
+		{http.MethodPut, "", "object-lock", "s3:PutBucketObjectLockConfiguration"},
+		{http.MethodGet, "", "replication", "s3:GetReplicationConfiguration"},
+		{http.MethodPut, "", "replication", "s3:PutReplicationConfiguration"},
+		{http.MethodDelete, "", "replication", "s3:PutReplicationConfiguration"},
+		{http.MethodGet, "", "uploads", "s3:ListBucketMultipartUploads"},
+		{http.MethodGet, "/a.txt", "", "s3:GetObject"},
+		{http.MethodHead, "/a.txt", "", "s3:GetObject"},
+		{http.MethodPut, "/a.txt", "", "s3:PutObject"},
+		{http.MethodPost, "/a.txt", "uploads", "s3:PutObject"},
+		{http.MethodPost, "/a.txt", "uploadId=1", "s3:PutObject"},
+		{http.MethodDelete, "/a.txt", "", "s3:DeleteObject"},
+		{http.MethodGet, "/a.txt", "tagging", "s3:GetObjectTagging"},
+		{http.MethodPut, "/a.txt", "tagging", "s3:PutObjectTagging"},
+		{http.MethodDelete, "/a.txt", "tagging", "s3:DeleteObjectTagging"},
+		{http.MethodGet, "/a.txt", "retention", "s3:GetObjectRetention"},
+		{http.MethodPut, "/a.txt", "retention", "s3:PutObjectRetention"},
+		{http.MethodGet, "/a.txt", "legal-hold", "s3:GetObjectLegalHold"},
+		{http.MethodPut, "/a.txt", "legal-hold", "s3:PutObjectLegalHold"},
+		{http.MethodGet, "/a.txt", "uploadId=1", "s3:ListMultipartUploadParts"},
+		{http.MethodDelete, "/a.txt", "uploadId=1", "s3:AbortMultipartUpload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.key+"?"+tt.query, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(tt.method, "/bucket"+tt.key+"?"+tt.query, nil)
+			c.Params = gin.Params{{Key: "bucket", Value: "bucket"}, {Key: "key", Value: tt.key}}
+			if got := policyAction(c); got != tt.want {
+				t.Errorf("policyAction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
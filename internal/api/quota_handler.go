@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+)
+
+// quotaTarget 解析路由中的配额对象：/quotas/buckets/:bucket 或 /quotas/users/:id
+func (s *Server) quotaTarget(c *gin.Context) (scope string, id int64, ok bool) {
+	if name := c.Param("bucket"); name != "" {
+		bucket, err := s.repo.GetBucketByName(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return "", 0, false
+		}
+		if bucket == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bucket not found"})
+			return "", 0, false
+		}
+		return metadata.QuotaScopeBucket, bucket.ID, true
+	}
+
+	user, err := s.repo.GetUserByID(c.Request.Context(), parseInt64(c.Param("id")))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return "", 0, false
+	}
+	return metadata.QuotaScopeUser, user.ID, true
+}
+
+// GetQuota 获取 Bucket 或用户的配额和当前用量（仅管理员）
+func (s *Server) GetQuota(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view quotas"})
+		return
+	}
+
+	scope, id, ok := s.quotaTarget(c)
+	if !ok {
+		return
+	}
+
+	quota, err := s.repo.GetQuota(c.Request.Context(), scope, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var usage *metadata.Usage
+	if scope == metadata.QuotaScopeBucket {
+		usage, err = s.repo.GetBucketUsage(c.Request.Context(), id)
+	} else {
+		usage, err = s.repo.GetUserUsage(c.Request.Context(), id)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scope": scope,
+		"id":    id,
+		"quota": quota,
+		"usage": usage,
+	})
+}
+
+// SetQuota 设置 Bucket 或用户的配额，各项为 0 表示不限制（仅管理员）
+func (s *Server) SetQuota(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can set quotas"})
+		return
+	}
+
+	scope, id, ok := s.quotaTarget(c)
+	if !ok {
+		return
+	}
+
+	var quota metadata.Quota
+	if err := c.ShouldBindJSON(&quota); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := quota.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.repo.SetQuota(c.Request.Context(), scope, id, &quota); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Quota updated successfully",
+		"quota":   quota,
+	})
+}
+
+// DeleteQuota 删除 Bucket 或用户的配额（仅管理员）
+func (s *Server) DeleteQuota(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can delete quotas"})
+		return
+	}
+
+	scope, id, ok := s.quotaTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := s.repo.DeleteQuota(c.Request.Context(), scope, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quota deleted successfully"})
+}
+
+// RecalculateUsage 按对象表重新计算用量计数（仅管理员）
+func (s *Server) RecalculateUsage(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can recalculate usage"})
+		return
+	}
+
+	if err := s.repo.RecalculateUsage(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Usage recalculated successfully"})
+}
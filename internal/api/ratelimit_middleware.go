@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/ratelimit"
+	"github.com/gooss/server/pkg/config"
+	"github.com/gooss/server/pkg/logger"
+	"github.com/gooss/server/pkg/response"
+	"github.com/redis/go-redis/v9"
+)
+
+// slowDownMessage 与 S3 SlowDown 错误的提示一致
+const slowDownMessage = "Please reduce your request rate."
+
+// newRateLimiter 根据配置创建限流器；Redis 不可用时回退到内存计数
+func newRateLimiter(cfg *config.Config) *ratelimit.Limiter {
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.Limits.RateLimitBackend == "redis" {
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr(),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			logger.Errorf("Failed to connect to Redis at %s, rate limits fall back to per-instance memory: %v", cfg.Redis.Addr(), err)
+			client.Close()
+		} else {
+			store = ratelimit.NewRedisStore(client)
+		}
+	}
+	return ratelimit.New(store, cfg.Limits)
+}
+
+// IPRateLimitMiddleware 按来源 IP 限流，在认证之前执行以限制未认证请求
+func (s *Server) IPRateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.limiter.Allow(c.Request.Context(), ratelimit.ScopeIP, c.ClientIP()) {
+			s.slowDown(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitMiddleware 按 Access Key 和 Bucket 限流，并对请求体和响应体按用户限速
+func (s *Server) RateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if !s.limiter.Allow(ctx, ratelimit.ScopeAccessKey, c.GetString("access_key")) ||
+			!s.limiter.Allow(ctx, ratelimit.ScopeBucket, c.Param("bucket")) {
+			s.slowDown(c)
+			return
+		}
+
+		// 匿名访问按来源 IP 计算带宽
+		user := "ip:" + c.ClientIP()
+		if uid, exists := c.Get("user_id"); exists {
+			if id, ok := uid.(int64); ok {
+				user = "user:" + strconv.FormatInt(id, 10)
+			}
+		}
+
+		if c.Request.Body != nil && c.Request.Body != http.NoBody && s.limiter.Throttled(ratelimit.DirectionUpload) {
+			c.Request.Body = &limitedReadCloser{
+				Reader: s.limiter.LimitReader(ctx, user, c.Request.Body),
+				Closer: c.Request.Body,
+			}
+		}
+		if s.limiter.Throttled(ratelimit.DirectionDownload) {
+			c.Writer = &limitedResponseWriter{
+				ResponseWriter: c.Writer,
+				writer:         s.limiter.LimitWriter(ctx, user, c.Writer),
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// slowDown 返回 S3 SlowDown 错误
+func (s *Server) slowDown(c *gin.Context) {
+	c.Header("Retry-After", "1")
+	c.XML(http.StatusServiceUnavailable, newS3Error(c, response.ErrSlowDown, slowDownMessage))
+	c.Abort()
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// limitedResponseWriter 响应体经过带宽限制后写出
+type limitedResponseWriter struct {
+	gin.ResponseWriter
+	writer io.Writer
+}
+
+func (w *limitedResponseWriter) Write(p []byte) (int, error) {
+	return w.writer.Write(p)
+}
+
+func (w *limitedResponseWriter) WriteString(s string) (int, error) {
+	return w.writer.Write([]byte(s))
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/metrics"
+	"github.com/gooss/server/pkg/config"
+	"github.com/gooss/server/pkg/logger"
+	"github.com/gooss/server/pkg/response"
+)
+
+// readOnlyRefreshInterval 从数据库同步只读开关的间隔，其他实例上的切换在该时间内生效
+const readOnlyRefreshInterval = 5 * time.Second
+
+// readOnlyState 通过管理 API 开启的只读开关
+type readOnlyState struct {
+	cluster *metadata.ReadOnlyMode
+	buckets map[string]*metadata.ReadOnlyMode
+}
+
+// refreshReadOnly 从数据库加载只读开关
+func (s *Server) refreshReadOnly(ctx context.Context) error {
+	modes, err := s.repo.ListReadOnlyModes(ctx)
+	if err != nil {
+		return err
+	}
+	state := &readOnlyState{buckets: make(map[string]*metadata.ReadOnlyMode)}
+	for i := range modes {
+		m := &modes[i]
+		switch m.Scope {
+		case metadata.ReadOnlyScopeCluster:
+			state.cluster = m
+		case metadata.ReadOnlyScopeBucket:
+			state.buckets[m.BucketName] = m
+		}
+	}
+	s.readOnly.Store(state)
+	return nil
+}
+
+// startReadOnlySync 定期同步只读开关，使多个实例的状态保持一致
+func (s *Server) startReadOnlySync(ctx context.Context) {
+	if err := s.refreshReadOnly(ctx); err != nil {
+		logger.Warnf("Failed to load read-only modes: %v", err)
+	}
+	go func() {
+		ticker := time.NewTicker(readOnlyRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.refreshReadOnly(ctx); err != nil {
+					logger.Warnf("Failed to refresh read-only modes: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// readOnlyError 判断对 Bucket 的修改是否被只读开关拒绝，返回错误码和说明
+func (s *Server) readOnlyError(bucketName string) (code, message string, blocked bool) {
+	cfg := s.effectiveConfig().Server
+	state := s.readOnly.Load()
+
+	if cfg.ReadOnly {
+		return response.ErrServiceReadOnly, "The service is in read-only mode", true
+	}
+	if state != nil && state.cluster != nil {
+		return response.ErrServiceReadOnly, readOnlyMessage("The service is in read-only mode", state.cluster), true
+	}
+	if bucketName == "" {
+		return "", "", false
+	}
+	for _, name := range cfg.ReadOnlyBuckets {
+		if name == bucketName {
+			return response.ErrBucketReadOnly, "The bucket is in read-only mode", true
+		}
+	}
+	if state != nil {
+		if m, ok := state.buckets[bucketName]; ok {
+			return response.ErrBucketReadOnly, readOnlyMessage("The bucket is in read-only mode", m), true
+		}
+	}
+	return "", "", false
+}
+
+// bucketWritable 复制和迁移写入本地 Bucket 前检查只读开关，与 S3 写入请求使用相同的判断
+func (s *Server) bucketWritable(bucket string) error {
+	if _, message, blocked := s.readOnlyError(bucket); blocked {
+		return errors.New(message)
+	}
+	return nil
+}
+
+func readOnlyMessage(message string, m *metadata.ReadOnlyMode) string {
+	if m.Reason != "" {
+		return message + ": " + m.Reason
+	}
+	return message
+}
+
+// ReadOnlyMiddleware 只读模式下拒绝所有修改操作，读取不受影响
+func (s *Server) ReadOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if code, message, blocked := s.readOnlyError(c.Param("bucket")); blocked {
+			c.XML(http.StatusServiceUnavailable, newS3Error(c, code, message))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetReadOnlyStatus 查看只读开关（仅管理员）
+func (s *Server) GetReadOnlyStatus(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view read-only mode"})
+		return
+	}
+
+	modes, err := s.repo.ListReadOnlyModes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cfg := s.effectiveConfig().Server
+	buckets := cfg.ReadOnlyBuckets
+	if buckets == nil {
+		buckets = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"modes": modes,
+		"config": gin.H{
+			"read_only":         cfg.ReadOnly,
+			"read_only_buckets": buckets,
+		},
+	})
+}
+
+// EnableReadOnly 开启集群或 Bucket 的只读模式（仅管理员）
+// PUT /admin/read-only 或 PUT /admin/read-only/buckets/:bucket，请求体可选 {"reason": "..."}
+func (s *Server) EnableReadOnly(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can change read-only mode"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	mode := &metadata.ReadOnlyMode{
+		Scope:     metadata.ReadOnlyScopeCluster,
+		Reason:    req.Reason,
+		EnabledBy: c.GetString("username"),
+	}
+	if bucketName := c.Param("bucket"); bucketName != "" {
+		bucket, err := s.repo.GetBucketByName(c.Request.Context(), bucketName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if bucket == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Bucket not found"})
+			return
+		}
+		mode.Scope = metadata.ReadOnlyScopeBucket
+		mode.BucketName = bucketName
+	}
+
+	if err := s.repo.SetReadOnlyMode(c.Request.Context(), mode); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.refreshReadOnly(c.Request.Context()); err != nil {
+		logger.Ctx(c.Request.Context()).Warnf("Failed to refresh read-only modes: %v", err)
+	}
+	logger.Ctx(c.Request.Context()).Infof("Read-only mode enabled: scope=%s bucket=%s by %s", mode.Scope, mode.BucketName, mode.EnabledBy)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Read-only mode enabled",
+		"mode":    mode,
+	})
+}
+
+// DisableReadOnly 关闭集群或 Bucket 的只读模式（仅管理员）
+func (s *Server) DisableReadOnly(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can change read-only mode"})
+		return
+	}
+
+	scope := metadata.ReadOnlyScopeCluster
+	bucketName := c.Param("bucket")
+	if bucketName != "" {
+		scope = metadata.ReadOnlyScopeBucket
+	}
+
+	deleted, err := s.repo.DeleteReadOnlyMode(c.Request.Context(), scope, bucketName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Read-only mode is not enabled"})
+		return
+	}
+	if err := s.refreshReadOnly(c.Request.Context()); err != nil {
+		logger.Ctx(c.Request.Context()).Warnf("Failed to refresh read-only modes: %v", err)
+	}
+	logger.Ctx(c.Request.Context()).Infof("Read-only mode disabled: scope=%s bucket=%s by %s", scope, bucketName, c.GetString("username"))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Read-only mode disabled"})
+}
+
+// auditReadOnlyConfig 配置文件中的只读开关变化时记录审计日志
+func (s *Server) auditReadOnlyConfig(old, new config.ServerConfig) {
+	var logs []*metadata.AuditLog
+	if old.ReadOnly != new.ReadOnly {
+		logs = append(logs, readOnlyAuditLog(new.ReadOnly, metadata.ResourceTypeCluster, ""))
+	}
+
+	before := make(map[string]bool)
+	for _, name := range old.ReadOnlyBuckets {
+		before[name] = true
+	}
+	after := make(map[string]bool)
+	for _, name := range new.ReadOnlyBuckets {
+		after[name] = true
+		if !before[name] {
+			logs = append(logs, readOnlyAuditLog(true, metadata.ResourceTypeBucket, name))
+		}
+	}
+	for name := range before {
+		if !after[name] {
+			logs = append(logs, readOnlyAuditLog(false, metadata.ResourceTypeBucket, name))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, log := range logs {
+		logger.Infof("Read-only mode %s via config: %s %s", log.Action, log.ResourceType, log.BucketName)
+		if err := s.repo.CreateAuditLog(ctx, log); err != nil {
+			metrics.AuditLogWriteFailures.Inc()
+			logger.Warnf("Failed to write audit log for read-only mode: %v", err)
+		}
+	}
+}
+
+func readOnlyAuditLog(enabled bool, resourceType, bucketName string) *metadata.AuditLog {
+	action := metadata.ActionDisableReadOnly
+	if enabled {
+		action = metadata.ActionEnableReadOnly
+	}
+	meta, _ := json.Marshal(map[string]string{"source": "config"})
+	return &metadata.AuditLog{
+		Username:     metadata.SystemUsername,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceName: bucketName,
+		BucketName:   bucketName,
+		StatusCode:   http.StatusOK,
+		Metadata:     meta,
+	}
+}
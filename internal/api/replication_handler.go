@@ -0,0 +1,212 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/migration"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// ReplicationTargetRequest 创建远端复制目标的请求
+type ReplicationTargetRequest struct {
+	Endpoint  string `json:"endpoint" binding:"required"`
+	AccessKey string `json:"accessKey" binding:"required"`
+	SecretKey string `json:"secretKey" binding:"required"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket" binding:"required"`
+}
+
+// CreateReplicationTarget 创建远端复制目标，创建前检查凭证能否访问目标 Bucket。
+// 返回的 arn 用作复制规则的 Destination.Bucket（仅管理员）
+func (s *Server) CreateReplicationTarget(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can manage replication targets"})
+		return
+	}
+
+	var req ReplicationTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.Region == "" {
+		req.Region = "us-east-1"
+	}
+
+	target := &metadata.ReplicationTarget{
+		ID:        uuid.New().String(),
+		Endpoint:  migration.NormalizeEndpoint(req.Endpoint),
+		Region:    req.Region,
+		AccessKey: req.AccessKey,
+		SecretKey: req.SecretKey,
+		Bucket:    req.Bucket,
+		CreatedBy: c.GetString("username"),
+	}
+	client, err := migration.NewClient(target.Endpoint, target.AccessKey, target.SecretKey, target.Region)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := client.ListObjects(c.Request.Context(), target.Bucket, "", "", "", 1); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot access target bucket: " + err.Error()})
+		return
+	}
+
+	if err := s.repo.CreateReplicationTarget(c.Request.Context(), target); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Ctx(c.Request.Context()).Infof("Replication target %s (%s/%s) created by %s", target.ID, target.Endpoint, target.Bucket, target.CreatedBy)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Replication target created",
+		"target":  target,
+	})
+}
+
+// ListReplicationTargets 列出远端复制目标（仅管理员）
+func (s *Server) ListReplicationTargets(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can manage replication targets"})
+		return
+	}
+
+	targets, err := s.repo.ListReplicationTargets(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"targets": targets,
+		"count":   len(targets),
+	})
+}
+
+// DeleteReplicationTarget 删除远端复制目标，仍被复制规则引用时拒绝（仅管理员）
+func (s *Server) DeleteReplicationTarget(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can manage replication targets"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	inUse, err := s.repo.ReplicationTargetInUse(ctx, metadata.ReplicationTargetARN(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if inUse {
+		c.JSON(http.StatusConflict, gin.H{"error": "Replication target is used by bucket replication rules"})
+		return
+	}
+
+	deleted, err := s.repo.DeleteReplicationTarget(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Replication target not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Replication target deleted"})
+}
+
+// GetReplicationStatus 获取复制统计：累计复制数、队列中各状态的任务数和对象复制状态分布（仅管理员）
+func (s *Server) GetReplicationStatus(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view replication status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.replicator.Stats(c.Request.Context()))
+}
+
+// ListReplicationTasks 列出复制队列中的任务，支持 bucket、status（pending、running、failed）、limit、offset 参数（仅管理员）
+func (s *Server) ListReplicationTasks(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view replication status"})
+		return
+	}
+
+	filter := &metadata.ReplicationTaskFilter{Status: c.Query("status"), Limit: 100}
+	if name := c.Query("bucket"); name != "" {
+		bucket, ok := s.lookupReplicationBucket(c, name)
+		if !ok {
+			return
+		}
+		filter.BucketID = bucket.ID
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	tasks, err := s.repo.ListReplicationTasks(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"tasks": tasks,
+		"count": len(tasks),
+	})
+}
+
+// RetryReplication 将重试次数用尽的复制任务重新放回队列，bucket 参数限定 Bucket（仅管理员）
+func (s *Server) RetryReplication(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can retry replication"})
+		return
+	}
+
+	var bucketID int64
+	if name := c.Query("bucket"); name != "" {
+		bucket, ok := s.lookupReplicationBucket(c, name)
+		if !ok {
+			return
+		}
+		bucketID = bucket.ID
+	}
+
+	requeued, err := s.repo.RequeueFailedReplication(c.Request.Context(), bucketID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Failed replication tasks requeued",
+		"requeued": requeued,
+	})
+}
+
+// lookupReplicationBucket 按名称查找 Bucket，不存在时已写入错误响应
+func (s *Server) lookupReplicationBucket(c *gin.Context, name string) (*metadata.Bucket, bool) {
+	bucket, err := s.repo.GetBucketByName(c.Request.Context(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if bucket == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bucket not found"})
+		return nil, false
+	}
+	return bucket, true
+}
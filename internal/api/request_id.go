@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/pkg/requestid"
+	"github.com/gooss/server/pkg/response"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDMiddleware 为每个请求生成唯一 ID，写入响应头、请求 context 和当前 span，
+// 用户反馈问题时可据此查找日志和审计记录
+func (s *Server) RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID, hostID := requestid.New()
+		c.Header(requestid.HeaderRequestID, requestID)
+		c.Header(requestid.HeaderHostID, hostID)
+
+		ctx := c.Request.Context()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("oss.request_id", requestID))
+		c.Request = c.Request.WithContext(requestid.NewContext(ctx, requestID))
+
+		c.Next()
+	}
+}
+
+// newS3Error 创建带有当前请求 ID 的 S3 错误响应
+func newS3Error(c *gin.Context, code, message string) *response.Error {
+	return response.NewError(code, message, c.Request.URL.Path).
+		WithRequestID(requestid.FromContext(c.Request.Context()), c.Writer.Header().Get(requestid.HeaderHostID))
+}
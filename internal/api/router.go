@@ -1,25 +1,59 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gooss/server/internal/api/s3"
 	"github.com/gooss/server/internal/auth"
+	"github.com/gooss/server/internal/maintenance"
 	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/metrics"
+	"github.com/gooss/server/internal/migration"
+	"github.com/gooss/server/internal/ratelimit"
+	"github.com/gooss/server/internal/replication"
 	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/internal/storage/local"
+	"github.com/gooss/server/internal/tracing"
+	"github.com/gooss/server/internal/usage"
+	"github.com/gooss/server/internal/util"
 	"github.com/gooss/server/pkg/config"
+	"github.com/gooss/server/pkg/logger"
 	"github.com/gooss/server/pkg/response"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Server API 服务器
 type Server struct {
 	cfg              *config.Config
+	effective        atomic.Pointer[config.Config] // 叠加热加载配置项后的当前配置
+	readOnly         atomic.Pointer[readOnlyState]
 	engine           *gin.Engine
+	adminEngine      *gin.Engine // 配置了管理端口时，管理 API 使用独立的监听
 	s3Handler        *s3.Handler
 	migrationHandler *MigrationHandler
+	multipartGC      *maintenance.MultipartGC
+	fsck             *maintenance.Fsck
+	scrubber         *maintenance.Scrubber
+	replicator       *replication.Replicator
+	limiter          *ratelimit.Limiter
+	usage            *usage.Accountant
 	repo             metadata.Repository
+	tls              *serverTLS
+	metricsRegistry  *prometheus.Registry // 依赖本实例状态的指标，每个 Server 独立注册
+
+	// 优雅关闭
+	srvMu        sync.Mutex
+	httpServer   *http.Server
+	adminServer  *http.Server
+	draining     atomic.Bool
+	auditPending atomic.Int64
 }
 
 // NewServer 创建 API 服务器
@@ -27,30 +61,194 @@ func NewServer(cfg *config.Config, storageEngine storage.Engine, repo metadata.R
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 	engine.Use(gin.Recovery())
+	setTrustedProxies(engine, cfg.Server.TrustedProxies)
 
-	s3Handler := s3.NewHandler(storageEngine, repo, "us-east-1")
-	migrationHandler := NewMigrationHandler(storageEngine, repo, "us-east-1")
+	// 记录存储引擎操作耗时与链路
+	storageEngine = tracing.TraceStorage(metrics.InstrumentStorage(storageEngine))
 
+	s3Handler := s3.NewHandler(storageEngine, repo, "us-east-1")
 	server := &Server{
-		cfg:              cfg,
-		engine:           engine,
-		s3Handler:        s3Handler,
-		migrationHandler: migrationHandler,
-		repo:             repo,
+		cfg:             cfg,
+		engine:          engine,
+		s3Handler:       s3Handler,
+		multipartGC:     newMultipartGC(cfg.Maintenance.MultipartGC, storageEngine, repo),
+		fsck:            maintenance.NewFsck(storageEngine, repo),
+		scrubber:        newScrubber(cfg, storageEngine, repo),
+		limiter:         newRateLimiter(cfg),
+		usage:           newAccountant(cfg.Usage, repo),
+		repo:            repo,
+		metricsRegistry: prometheus.NewRegistry(),
+	}
+	server.replicator = newReplicator(cfg.Replication, storageEngine, repo, server.bucketWritable)
+	migrationOpts := newMigrationOptions(cfg)
+	migrationOpts.Writable = server.bucketWritable
+	server.migrationHandler = NewMigrationHandler(storageEngine, repo, "us-east-1", migrationOpts)
+	effective := *cfg
+	server.effective.Store(&effective)
+	if cfg.Server.AdminPort > 0 {
+		server.adminEngine = gin.New()
+		server.adminEngine.Use(gin.Recovery())
+		setTrustedProxies(server.adminEngine, cfg.Server.TrustedProxies)
 	}
 
+	server.registerMetrics()
 	server.setupRoutes()
 	return server
 }
 
+// setTrustedProxies 设置可信代理。客户端 IP 用于按 IP 限流和审计，默认不信任任何代理，
+// 直接使用连接来源地址，避免客户端伪造 X-Forwarded-For 绕过限流
+func setTrustedProxies(engine *gin.Engine, proxies []string) {
+	if err := engine.SetTrustedProxies(proxies); err != nil {
+		logger.Warnf("Invalid server.trusted_proxies %v, trusting no proxies: %v", proxies, err)
+		engine.SetTrustedProxies(nil)
+	}
+}
+
+// newMultipartGC 根据配置创建分片上传清理器，非法配置回退到默认值
+func newMultipartGC(cfg config.MultipartGCConfig, storageEngine storage.Engine, repo metadata.Repository) *maintenance.MultipartGC {
+	maxAge := 7 * 24 * time.Hour
+	if cfg.MaxAge != "" {
+		if d, err := util.ParseDuration(cfg.MaxAge); err == nil && d > 0 {
+			maxAge = d
+		} else {
+			logger.Warnf("Invalid maintenance.multipart_gc.max_age %q, using %s", cfg.MaxAge, maxAge)
+		}
+	}
+
+	interval := time.Hour
+	if cfg.Interval != "" {
+		if d, err := util.ParseDuration(cfg.Interval); err == nil && d > 0 {
+			interval = d
+		} else {
+			logger.Warnf("Invalid maintenance.multipart_gc.interval %q, using %s", cfg.Interval, interval)
+		}
+	}
+
+	return maintenance.NewMultipartGC(storageEngine, repo, maxAge, interval)
+}
+
+// newMigrationOptions 根据配置创建迁移传输参数，未设置的项由迁移管理器补全默认值
+func newMigrationOptions(cfg *config.Config) migration.Options {
+	migrationCfg := cfg.Migration
+	opts := migration.Options{
+		Concurrency:        migrationCfg.Concurrency,
+		MultipartThreshold: migrationCfg.MultipartThreshold,
+		PartSize:           migrationCfg.PartSize,
+		PartConcurrency:    migrationCfg.PartConcurrency,
+		MaxRetries:         migrationCfg.MaxRetries,
+		BytesPerSecond:     migrationCfg.BytesPerSecond,
+	}
+	if migrationCfg.RetryBaseDelay != "" {
+		if d, err := util.ParseDuration(migrationCfg.RetryBaseDelay); err == nil && d > 0 {
+			opts.RetryBaseDelay = d
+		} else {
+			logger.Warnf("Invalid migration.retry_base_delay %q, using default", migrationCfg.RetryBaseDelay)
+		}
+	}
+	return opts
+}
+
+// newScrubber 根据配置创建数据完整性巡检器
+func newScrubber(cfg *config.Config, storageEngine storage.Engine, repo metadata.Repository) *maintenance.Scrubber {
+	scrubCfg := cfg.Maintenance.Scrub
+	opts := maintenance.ScrubOptions{
+		Interval:       24 * time.Hour,
+		ReverifyAfter:  30 * 24 * time.Hour,
+		BatchSize:      scrubCfg.BatchSize,
+		BytesPerSecond: scrubCfg.BytesPerSecond,
+	}
+	if scrubCfg.Interval != "" {
+		if d, err := util.ParseDuration(scrubCfg.Interval); err == nil && d > 0 {
+			opts.Interval = d
+		} else {
+			logger.Warnf("Invalid maintenance.scrub.interval %q, using %s", scrubCfg.Interval, opts.Interval)
+		}
+	}
+	if scrubCfg.ReverifyAfter != "" {
+		if d, err := util.ParseDuration(scrubCfg.ReverifyAfter); err == nil && d > 0 {
+			opts.ReverifyAfter = d
+		} else {
+			logger.Warnf("Invalid maintenance.scrub.reverify_after %q, using %s", scrubCfg.ReverifyAfter, opts.ReverifyAfter)
+		}
+	}
+
+	// 冗余副本只用于读取修复，目录结构与本地存储相同
+	var redundant storage.Engine
+	if path := cfg.Storage.Redundant.BasePath; path != "" {
+		engine, err := local.New(path)
+		if err != nil {
+			logger.Warnf("Failed to open redundant storage at %s, scrub repair disabled: %v", path, err)
+		} else {
+			redundant = engine
+		}
+	}
+
+	return maintenance.NewScrubber(storageEngine, redundant, repo, opts)
+}
+
+// newReplicator 根据配置创建复制处理器，非法的时长配置使用默认值
+func newReplicator(cfg config.ReplicationConfig, storageEngine storage.Engine, repo metadata.Repository, writable func(bucket string) error) *replication.Replicator {
+	opts := replication.Options{
+		Workers:     cfg.Workers,
+		MaxAttempts: cfg.MaxAttempts,
+		Writable:    writable,
+	}
+	if cfg.PollInterval != "" {
+		if d, err := util.ParseDuration(cfg.PollInterval); err == nil && d > 0 {
+			opts.PollInterval = d
+		} else {
+			logger.Warnf("Invalid replication.poll_interval %q, using default", cfg.PollInterval)
+		}
+	}
+	if cfg.RetryBaseDelay != "" {
+		if d, err := util.ParseDuration(cfg.RetryBaseDelay); err == nil && d > 0 {
+			opts.RetryBaseDelay = d
+		} else {
+			logger.Warnf("Invalid replication.retry_base_delay %q, using default", cfg.RetryBaseDelay)
+		}
+	}
+	if cfg.MaxRetryDelay != "" {
+		if d, err := util.ParseDuration(cfg.MaxRetryDelay); err == nil && d > 0 {
+			opts.MaxRetryDelay = d
+		} else {
+			logger.Warnf("Invalid replication.max_retry_delay %q, using default", cfg.MaxRetryDelay)
+		}
+	}
+	return replication.New(storageEngine, repo, opts)
+}
+
+// StartBackgroundJobs 启动后台维护任务，ctx 取消后停止
+func (s *Server) StartBackgroundJobs(ctx context.Context) {
+	if s.cfg.Maintenance.MultipartGC.Enabled {
+		s.multipartGC.Start(ctx)
+		logger.Infof("Multipart upload GC started")
+	}
+	if s.cfg.Maintenance.Scrub.Enabled {
+		s.scrubber.Start(ctx)
+		logger.Infof("Data scrubber started")
+	}
+	if s.cfg.Usage.Enabled {
+		s.usage.Start(ctx)
+		logger.Infof("Usage accounting started")
+	}
+	if s.tls != nil {
+		s.watchTLS(ctx)
+	}
+	s.startReadOnlySync(ctx)
+	s.migrationHandler.Start(ctx)
+	// 在加载只读开关之后启动，避免启动时写入只读的 Bucket
+	if s.cfg.Replication.Enabled {
+		s.replicator.Start(ctx)
+		logger.Infof("Replication worker started")
+	}
+}
+
 func (s *Server) setupRoutes() {
-	// CORS 中间件
-	s.engine.Use(s.corsMiddleware())
+	s.useCommonMiddleware(s.engine)
 
 	// 健康检查
-	s.engine.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	s.engine.GET("/health", s.healthCheck)
 
 	// 认证相关路由（不需要签名）
 	auth := s.engine.Group("/auth")
@@ -62,13 +260,43 @@ func (s *Server) setupRoutes() {
 	user := s.engine.Group("/user")
 	user.Use(s.authMiddleware())
 	user.Use(s.AuditMiddleware())
+	user.Use(s.HandlerSpanMiddleware())
 	{
 		user.POST("/change-password", s.ChangePassword)
 	}
 
+	// 管理接口：配置了管理端口时只在管理端口提供，否则与业务接口共用端口
+	adminRouter := s.engine
+	if s.adminEngine != nil {
+		adminRouter = s.adminEngine
+		s.useCommonMiddleware(adminRouter)
+		adminRouter.GET("/health", s.healthCheck)
+	}
+	// 监控指标：管理端口上为 /metrics；共用端口时为 /admin/metrics，避免遮住名为 metrics 的 Bucket，
+	// 且必须配置 metrics_token，否则不提供
+	if s.adminEngine != nil {
+		s.adminEngine.GET("/metrics", s.requireMetricsToken(), gin.WrapH(s.MetricsHandler()))
+	} else if s.cfg.Server.MetricsToken != "" {
+		s.engine.GET("/admin/metrics", s.requireMetricsToken(), gin.WrapH(s.MetricsHandler()))
+	} else {
+		logger.Warnf("server.metrics_token is not set, metrics are only served on server.admin_port")
+	}
+	if s.cfg.Server.EnablePprof {
+		// 性能分析接口没有用户认证，不能暴露在业务端口上
+		if s.adminEngine != nil {
+			s.registerPprof(s.adminEngine)
+		} else {
+			logger.Warnf("server.enable_pprof requires server.admin_port, pprof is disabled")
+		}
+	}
+
 	// 用户管理路由（需要管理员权限）
-	admin := s.engine.Group("/admin")
+	admin := adminRouter.Group("/admin")
+	admin.Use(s.requireClientCert())
 	admin.Use(s.authMiddleware())
+	admin.Use(s.requireAdmin())
+	admin.Use(s.AuditMiddleware())
+	admin.Use(s.HandlerSpanMiddleware())
 	{
 		admin.GET("/users", s.ListUsers)
 		admin.POST("/users", s.CreateUser)
@@ -82,12 +310,61 @@ func (s *Server) setupRoutes() {
 
 		// 迁移路由
 		admin.POST("/migration/start", s.migrationHandler.StartMigration)
+		admin.GET("/migration", s.migrationHandler.ListMigrations)
+		admin.GET("/migration/:id", s.migrationHandler.GetMigration)
+		admin.GET("/migration/:id/report", s.migrationHandler.GetMigrationReport)
+		admin.POST("/migration/:id/cancel", s.migrationHandler.CancelMigration)
+		admin.POST("/migration/:id/resume", s.migrationHandler.ResumeMigration)
+
+		// 维护任务路由
+		admin.GET("/maintenance/multipart-gc", s.GetMultipartGCStats)
+		admin.POST("/maintenance/multipart-gc/run", s.RunMultipartGC)
+		admin.POST("/maintenance/fsck", s.RunFsck)
+		admin.GET("/maintenance/scrub", s.GetScrubStats)
+		admin.POST("/maintenance/scrub/run", s.RunScrub)
+
+		// 复制路由
+		admin.GET("/replication", s.GetReplicationStatus)
+		admin.GET("/replication/tasks", s.ListReplicationTasks)
+		admin.POST("/replication/retry", s.RetryReplication)
+		admin.GET("/replication/targets", s.ListReplicationTargets)
+		admin.POST("/replication/targets", s.CreateReplicationTarget)
+		admin.DELETE("/replication/targets/:id", s.DeleteReplicationTarget)
+
+		// 配额路由
+		admin.GET("/quotas/buckets/:bucket", s.GetQuota)
+		admin.PUT("/quotas/buckets/:bucket", s.SetQuota)
+		admin.DELETE("/quotas/buckets/:bucket", s.DeleteQuota)
+		admin.GET("/quotas/users/:id", s.GetQuota)
+		admin.PUT("/quotas/users/:id", s.SetQuota)
+		admin.DELETE("/quotas/users/:id", s.DeleteQuota)
+		admin.POST("/quotas/recalculate", s.RecalculateUsage)
+
+		// 用量报表路由
+		admin.GET("/usage", s.GetUsageReport)
+
+		// 当前生效的配置
+		admin.GET("/config", s.GetConfig)
+
+		// 只读模式路由
+		admin.GET("/read-only", s.GetReadOnlyStatus)
+		admin.PUT("/read-only", s.EnableReadOnly)
+		admin.DELETE("/read-only", s.DisableReadOnly)
+		admin.PUT("/read-only/buckets/:bucket", s.EnableReadOnly)
+		admin.DELETE("/read-only/buckets/:bucket", s.DisableReadOnly)
 	}
 
 	// S3 API 路由组
 	s3Group := s.engine.Group("")
+	s3Group.Use(s.IPRateLimitMiddleware())
 	s3Group.Use(s.authMiddleware())
+	s3Group.Use(s.RateLimitMiddleware())
+	if s.cfg.Usage.Enabled {
+		s3Group.Use(s.UsageMiddleware())
+	}
 	s3Group.Use(s.AuditMiddleware())
+	s3Group.Use(s.ReadOnlyMiddleware())
+	s3Group.Use(s.HandlerSpanMiddleware())
 	{
 		// Service 操作
 		s3Group.GET("/", s.s3Handler.ListBuckets)
@@ -105,6 +382,21 @@ func (s *Server) setupRoutes() {
 				s.s3Handler.PutBucketPolicy(c)
 				return
 			}
+			// 检查是否为 Tagging 操作
+			if _, ok := c.GetQuery("tagging"); ok {
+				s.s3Handler.PutBucketTagging(c)
+				return
+			}
+			// 检查是否为 Object Lock 配置
+			if _, ok := c.GetQuery("object-lock"); ok {
+				s.s3Handler.PutObjectLockConfiguration(c)
+				return
+			}
+			// 检查是否为复制配置
+			if _, ok := c.GetQuery("replication"); ok {
+				s.s3Handler.PutBucketReplication(c)
+				return
+			}
 			s.s3Handler.CreateBucket(c)
 		})
 		s3Group.DELETE("/:bucket", func(c *gin.Context) {
@@ -113,6 +405,16 @@ func (s *Server) setupRoutes() {
 				s.s3Handler.DeleteBucketPolicy(c)
 				return
 			}
+			// 检查是否为 Tagging 操作
+			if _, ok := c.GetQuery("tagging"); ok {
+				s.s3Handler.DeleteBucketTagging(c)
+				return
+			}
+			// 检查是否为复制配置
+			if _, ok := c.GetQuery("replication"); ok {
+				s.s3Handler.DeleteBucketReplication(c)
+				return
+			}
 			s.s3Handler.DeleteBucket(c)
 		})
 		s3Group.GET("/:bucket", func(c *gin.Context) {
@@ -126,6 +428,26 @@ func (s *Server) setupRoutes() {
 				s.s3Handler.GetBucketPolicy(c)
 				return
 			}
+			// 检查是否为 Tagging 操作
+			if _, ok := c.GetQuery("tagging"); ok {
+				s.s3Handler.GetBucketTagging(c)
+				return
+			}
+			// 检查是否为 Object Lock 配置
+			if _, ok := c.GetQuery("object-lock"); ok {
+				s.s3Handler.GetObjectLockConfiguration(c)
+				return
+			}
+			// 检查是否为复制配置
+			if _, ok := c.GetQuery("replication"); ok {
+				s.s3Handler.GetBucketReplication(c)
+				return
+			}
+			// 检查是否为列出分片上传
+			if _, ok := c.GetQuery("uploads"); ok {
+				s.s3Handler.ListMultipartUploads(c)
+				return
+			}
 			s.s3Handler.ListObjects(c)
 		})
 
@@ -145,6 +467,20 @@ func (s *Server) bucketOrObjectHandler(c *gin.Context) {
 
 // objectPutHandler 处理 PUT /{bucket}/{key} 请求
 func (s *Server) objectPutHandler(c *gin.Context) {
+	// 检查是否是设置标签
+	if _, ok := c.GetQuery("tagging"); ok {
+		s.s3Handler.PutObjectTagging(c)
+		return
+	}
+	// 检查是否是设置保留或法律保留
+	if _, ok := c.GetQuery("retention"); ok {
+		s.s3Handler.PutObjectRetention(c)
+		return
+	}
+	if _, ok := c.GetQuery("legal-hold"); ok {
+		s.s3Handler.PutObjectLegalHold(c)
+		return
+	}
 	// 检查是否是分片上传
 	if c.Query("partNumber") != "" && c.Query("uploadId") != "" {
 		s.s3Handler.UploadPart(c)
@@ -155,6 +491,20 @@ func (s *Server) objectPutHandler(c *gin.Context) {
 
 // objectGetHandler 处理 GET /{bucket}/{key} 请求
 func (s *Server) objectGetHandler(c *gin.Context) {
+	// 检查是否是获取标签
+	if _, ok := c.GetQuery("tagging"); ok {
+		s.s3Handler.GetObjectTagging(c)
+		return
+	}
+	// 检查是否是获取保留或法律保留
+	if _, ok := c.GetQuery("retention"); ok {
+		s.s3Handler.GetObjectRetention(c)
+		return
+	}
+	if _, ok := c.GetQuery("legal-hold"); ok {
+		s.s3Handler.GetObjectLegalHold(c)
+		return
+	}
 	// 检查是否是列出分片
 	if c.Query("uploadId") != "" {
 		s.s3Handler.ListParts(c)
@@ -165,6 +515,11 @@ func (s *Server) objectGetHandler(c *gin.Context) {
 
 // objectDeleteHandler 处理 DELETE /{bucket}/{key} 请求
 func (s *Server) objectDeleteHandler(c *gin.Context) {
+	// 检查是否是删除标签
+	if _, ok := c.GetQuery("tagging"); ok {
+		s.s3Handler.DeleteObjectTagging(c)
+		return
+	}
 	// 检查是否是取消分片上传
 	if c.Query("uploadId") != "" {
 		s.s3Handler.AbortMultipartUpload(c)
@@ -193,8 +548,8 @@ func (s *Server) corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
 
-		// 从配置中获取允许的来源
-		allowedOrigins := s.cfg.Server.AllowedOrigins
+		// 从配置中获取允许的来源，配置文件修改后立即生效
+		allowedOrigins := s.effectiveConfig().Server.AllowedOrigins
 		if len(allowedOrigins) == 0 {
 			// 如果未配置，默认允许所有来源（向后兼容）
 			allowedOrigins = []string{"*"}
@@ -223,7 +578,7 @@ func (s *Server) corsMiddleware() gin.HandlerFunc {
 
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, HEAD, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Amz-Date, X-Amz-Content-Sha256, X-Amz-Security-Token")
-		c.Header("Access-Control-Expose-Headers", "ETag, X-Amz-Request-Id")
+		c.Header("Access-Control-Expose-Headers", "ETag, X-Amz-Request-Id, X-Amz-Id-2")
 		c.Header("Access-Control-Max-Age", "86400")
 
 		if c.Request.Method == "OPTIONS" {
@@ -237,103 +592,120 @@ func (s *Server) corsMiddleware() gin.HandlerFunc {
 // authMiddleware 认证中间件
 func (s *Server) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 跳过不需要认证的路径
-		if c.Request.URL.Path == "/health" ||
-			strings.HasPrefix(c.Request.URL.Path, "/auth/") {
+		// 认证过程单独记录为一个 span，后续处理仍挂在请求 span 下
+		parent := c.Request.Context()
+		ctx, span := tracing.Start(parent, "auth")
+		c.Request = c.Request.WithContext(ctx)
+		ok := s.authenticate(c)
+		span.SetAttributes(attribute.Bool("oss.authenticated", ok))
+		span.End()
+		c.Request = c.Request.WithContext(parent)
+
+		if ok {
 			c.Next()
-			return
 		}
+	}
+}
 
-		// 检查是否为公开读访问 (只对 GetObject 生效)
-		if c.Request.Method == "GET" && strings.Count(c.Request.URL.Path, "/") >= 2 {
-			// 路径格式: /{bucket}/{key...}
-			parts := strings.SplitN(strings.TrimPrefix(c.Request.URL.Path, "/"), "/", 2)
-			if len(parts) == 2 {
-				bucketName := parts[0]
-				bucket, err := s.repo.GetBucketByName(c.Request.Context(), bucketName)
-				if err == nil && bucket != nil {
-					// 尝试获取 bucket policy
-					policyData, err := s.repo.GetBucketPolicy(c.Request.Context(), bucket.ID)
-					if err == nil && policyData != nil {
-						policy, err := metadata.ParseBucketPolicy(policyData)
-						if err == nil && policy.IsPublicRead() {
-							// 公开读，允许匿名访问
-							c.Set("public_access", true)
-							c.Set("bucket_id", bucket.ID)
-							c.Next()
-							return
-						}
+// authenticate 校验请求身份并写入上下文，失败时已写入错误响应并中止请求
+func (s *Server) authenticate(c *gin.Context) bool {
+	// 跳过不需要认证的路径
+	if c.Request.URL.Path == "/health" ||
+		strings.HasPrefix(c.Request.URL.Path, "/auth/") {
+		return true
+	}
+
+	// 检查是否为公开读访问 (只对 GetObject 生效，?tagging、?retention 等子资源仍需认证)
+	if c.Request.Method == "GET" && strings.Count(c.Request.URL.Path, "/") >= 2 && policyAction(c) == "s3:GetObject" {
+		// 路径格式: /{bucket}/{key...}
+		parts := strings.SplitN(strings.TrimPrefix(c.Request.URL.Path, "/"), "/", 2)
+		if len(parts) == 2 {
+			bucketName := parts[0]
+			bucket, err := s.repo.GetBucketByName(c.Request.Context(), bucketName)
+			if err == nil && bucket != nil {
+				// 尝试获取 bucket policy
+				policyData, err := s.repo.GetBucketPolicy(c.Request.Context(), bucket.ID)
+				if err == nil && policyData != nil {
+					policy, err := metadata.ParseBucketPolicy(policyData)
+					conditions := s3.PolicyConditions(c.Request)
+					if err == nil && policy.IsPublicRead(conditions) && !policy.Denies("", "s3:GetObject", conditions) {
+						// 公开读，允许匿名访问
+						c.Set("public_access", true)
+						c.Set("bucket_id", bucket.ID)
+						return true
 					}
 				}
 			}
 		}
+	}
 
-		// 解析认证信息
-		authHeader := c.GetHeader("Authorization")
-		var accessKey string
+	// 解析认证信息
+	authHeader := c.GetHeader("Authorization")
+	var accessKey string
 
-		if authHeader != "" {
-			// Header 签名
-			parsedAuth, err := auth.ParseAuthorizationHeader(authHeader)
-			if err != nil {
-				c.XML(http.StatusForbidden, response.NewError(response.ErrAccessDenied, err.Error(), c.Request.URL.Path))
-				c.Abort()
-				return
-			}
-			accessKey = parsedAuth.AccessKey
-		} else if c.Query("X-Amz-Algorithm") != "" {
-			// 预签名 URL
-			parsedAuth, err := auth.ParseQueryAuth(c.Request.URL.Query())
-			if err != nil {
-				c.XML(http.StatusForbidden, response.NewError(response.ErrAccessDenied, err.Error(), c.Request.URL.Path))
-				c.Abort()
-				return
-			}
-			accessKey = parsedAuth.AccessKey
-		} else {
-			c.XML(http.StatusForbidden, response.NewError(response.ErrAccessDenied, "Missing authentication", c.Request.URL.Path))
+	if authHeader != "" {
+		// Header 签名
+		parsedAuth, err := auth.ParseAuthorizationHeader(authHeader)
+		if err != nil {
+			c.XML(http.StatusForbidden, newS3Error(c, response.ErrAccessDenied, err.Error()))
 			c.Abort()
-			return
+			return false
 		}
-
-		// 查找凭证
-		cred, err := s.repo.GetCredentialByAccessKey(c.Request.Context(), accessKey)
-		if err != nil || cred == nil {
-			c.XML(http.StatusForbidden, response.NewError(response.ErrInvalidAccessKeyId, "Invalid access key", c.Request.URL.Path))
+		accessKey = parsedAuth.AccessKey
+	} else if c.Query("X-Amz-Algorithm") != "" {
+		// 预签名 URL
+		parsedAuth, err := auth.ParseQueryAuth(c.Request.URL.Query())
+		if err != nil {
+			c.XML(http.StatusForbidden, newS3Error(c, response.ErrAccessDenied, err.Error()))
 			c.Abort()
-			return
+			return false
 		}
+		accessKey = parsedAuth.AccessKey
+	} else {
+		c.XML(http.StatusForbidden, newS3Error(c, response.ErrAccessDenied, "Missing authentication"))
+		c.Abort()
+		return false
+	}
 
-		// 验证签名
-		c.Header("Server", "1103-OSS/1.0")
-		signer := auth.NewSignatureV4(cred.AccessKey, cred.SecretKey, "us-east-1")
-		if err := signer.VerifyRequest(c.Request, cred.SecretKey); err != nil {
-			c.XML(http.StatusForbidden, response.NewError(response.ErrSignatureDoesNotMatch, err.Error(), c.Request.URL.Path))
-			c.Abort()
-			return
-		}
+	// 查找凭证
+	cred, err := s.repo.GetCredentialByAccessKey(c.Request.Context(), accessKey)
+	if err != nil || cred == nil {
+		c.XML(http.StatusForbidden, newS3Error(c, response.ErrInvalidAccessKeyId, "Invalid access key"))
+		c.Abort()
+		return false
+	}
 
-		// 获取用户信息
-		user, err := s.repo.GetUserByID(c.Request.Context(), cred.UserID)
-		if err != nil || user == nil {
-			c.XML(http.StatusForbidden, response.NewError(response.ErrAccessDenied, "User not found", c.Request.URL.Path))
-			c.Abort()
-			return
-		}
+	// 验证签名
+	c.Header("Server", "1103-OSS/1.0")
+	signer := auth.NewSignatureV4(cred.AccessKey, cred.SecretKey, "us-east-1")
+	if err := signer.VerifyRequest(c.Request, cred.SecretKey); err != nil {
+		c.XML(http.StatusForbidden, newS3Error(c, response.ErrSignatureDoesNotMatch, err.Error()))
+		c.Abort()
+		return false
+	}
 
-		// 设置上下文
-		c.Set("user_id", user.ID)
-		c.Set("username", user.Username)
-		c.Set("is_admin", user.IsAdmin)
-		c.Set("access_key", accessKey)
+	// 获取用户信息
+	user, err := s.repo.GetUserByID(c.Request.Context(), cred.UserID)
+	if err != nil || user == nil {
+		c.XML(http.StatusForbidden, newS3Error(c, response.ErrAccessDenied, "User not found"))
+		c.Abort()
+		return false
+	}
 
-		c.Next()
+	// 设置上下文
+	c.Set("user_id", user.ID)
+	c.Set("username", user.Username)
+	c.Set("is_admin", user.IsAdmin)
+	c.Set("access_key", accessKey)
+
+	// Bucket 策略中的显式拒绝（如 aws:SecureTransport 为 false 时拒绝）
+	if s.policyDenied(c, user) {
+		c.XML(http.StatusForbidden, newS3Error(c, response.ErrAccessDenied, "Access denied by bucket policy"))
+		c.Abort()
+		return false
 	}
-}
 
-// Run 启动服务器
-func (s *Server) Run(addr string) error {
-	return s.engine.Run(addr)
+	return true
 }
 
 // Engine 获取 Gin 引擎
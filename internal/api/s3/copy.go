@@ -0,0 +1,175 @@
+package s3
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/response"
+)
+
+// maxCopyPartSize UploadPartCopy 单个分片允许复制的最大字节数（5GB）
+const maxCopyPartSize = 5 * 1024 * 1024 * 1024
+
+// parseCopySource 解析 x-amz-copy-source，格式为 [/]bucket/key[?versionId=...]，key 可能经过 URL 编码
+func parseCopySource(header string) (bucket, key string, err error) {
+	if idx := strings.Index(header, "?"); idx >= 0 {
+		header = header[:idx]
+	}
+
+	decoded, err := url.PathUnescape(header)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid copy source encoding")
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(decoded, "/"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid copy source")
+	}
+	return parts[0], parts[1], nil
+}
+
+// parseCopySourceRange 解析 x-amz-copy-source-range（bytes=first-last），返回闭区间
+func parseCopySourceRange(header string, size int64) (start, end int64, err error) {
+	if !strings.HasPrefix(header, "bytes=") {
+		return 0, 0, fmt.Errorf("range must be in the form bytes=first-last")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(header, "bytes="), "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("range must be in the form bytes=first-last")
+	}
+
+	start, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range start")
+	}
+	end, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range end")
+	}
+
+	if start < 0 || start > end || end >= size {
+		return 0, 0, fmt.Errorf("range %d-%d is outside object size %d", start, end, size)
+	}
+	return start, end, nil
+}
+
+// UploadPartCopy PUT /{bucket}/{key}?partNumber=&uploadId= with x-amz-copy-source - 从已有对象复制分片
+func (h *Handler) UploadPartCopy(c *gin.Context) {
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	uploadID := c.Query("uploadId")
+	partNumber, _ := strconv.Atoi(c.Query("partNumber"))
+
+	if partNumber < 1 || partNumber > 10000 {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Invalid part number")
+		return
+	}
+
+	// 验证上传任务
+	upload, ok := h.getUpload(c, bucketName, key, uploadID)
+	if !ok {
+		return
+	}
+
+	srcBucket, srcKey, err := parseCopySource(c.GetHeader("x-amz-copy-source"))
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, err.Error())
+		return
+	}
+
+	// 验证源对象
+	srcBucketMeta, err := h.repo.GetBucketByName(c.Request.Context(), srcBucket)
+	if err != nil || srcBucketMeta == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Source bucket not found")
+		return
+	}
+	srcObj, err := h.repo.GetObject(c.Request.Context(), srcBucketMeta.ID, srcKey)
+	if err != nil || srcObj == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchKey, "Source object not found")
+		return
+	}
+	if srcObj.IsCorrupt() {
+		h.sendError(c, http.StatusInternalServerError, response.ErrObjectCorrupted, corruptObjectMessage)
+		return
+	}
+
+	if !checkCopySourceConditions(c, srcObj) {
+		h.sendError(c, http.StatusPreconditionFailed, response.ErrPreconditionFailed, "Copy source precondition failed")
+		return
+	}
+
+	// 确定复制范围，未指定时复制整个对象
+	start, end := int64(0), srcObj.Size-1
+	if rangeHeader := c.GetHeader("x-amz-copy-source-range"); rangeHeader != "" {
+		start, end, err = parseCopySourceRange(rangeHeader, srcObj.Size)
+		if err != nil {
+			h.sendError(c, http.StatusRequestedRangeNotSatisfiable, response.ErrInvalidRange, err.Error())
+			return
+		}
+	}
+	length := end - start + 1
+	if length > maxCopyPartSize {
+		h.sendError(c, http.StatusBadRequest, response.ErrEntityTooLarge, "Copy part exceeds maximum allowed size")
+		return
+	}
+	if !h.checkPartQuota(c, upload, partNumber, length) {
+		return
+	}
+
+	// 直接从存储引擎读取源数据写入分片
+	var reader io.ReadCloser
+	if length <= 0 {
+		reader = io.NopCloser(strings.NewReader(""))
+		length = 0
+	} else {
+		reader, _, err = h.storage.GetRange(c.Request.Context(), srcBucket, srcKey, start, end)
+		if err != nil {
+			h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+			return
+		}
+	}
+	defer reader.Close()
+
+	etag, err := h.storage.PutPart(c.Request.Context(), bucketName, key, uploadID, partNumber, reader, length)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	// 保存分片元数据
+	part := &metadata.UploadPart{
+		UploadID:   uploadID,
+		PartNumber: partNumber,
+		Size:       length,
+		ETag:       etag,
+	}
+	if err := h.repo.CreateUploadPart(c.Request.Context(), part); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	c.XML(http.StatusOK, response.CopyPartResult{
+		LastModified: response.FormatTime(time.Now()),
+		ETag:         fmt.Sprintf("\"%s\"", etag),
+	})
+}
+
+// checkCopySourceConditions 校验 x-amz-copy-source-if-match / if-none-match
+func checkCopySourceConditions(c *gin.Context, src *metadata.Object) bool {
+	etag := src.ETag
+	if ifMatch := c.GetHeader("x-amz-copy-source-if-match"); ifMatch != "" && strings.Trim(ifMatch, "\"") != etag {
+		return false
+	}
+	if ifNoneMatch := c.GetHeader("x-amz-copy-source-if-none-match"); ifNoneMatch != "" && strings.Trim(ifNoneMatch, "\"") == etag {
+		return false
+	}
+	return true
+}
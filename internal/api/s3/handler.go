@@ -13,9 +13,14 @@ import (
 	"github.com/gooss/server/internal/auth"
 	"github.com/gooss/server/internal/metadata"
 	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+	"github.com/gooss/server/pkg/requestid"
 	"github.com/gooss/server/pkg/response"
 )
 
+// corruptObjectMessage 对象被后台巡检判定为损坏时返回的错误信息
+const corruptObjectMessage = "Object data failed integrity verification and cannot be served"
+
 // Handler S3 API 处理器
 type Handler struct {
 	storage storage.Engine
@@ -95,11 +100,15 @@ func (h *Handler) CreateBucket(c *gin.Context) {
 	}
 
 	// 创建元数据
+	// 启用 Object Lock 时同时开启版本控制（与 S3 行为一致）
+	lockEnabled := parseBucketLockHeader(c)
 	bucket := &metadata.Bucket{
-		Name:    bucketName,
-		OwnerID: userID,
-		Region:  h.region,
-		ACL:     "private",
+		Name:              bucketName,
+		OwnerID:           userID,
+		Region:            h.region,
+		ACL:               "private",
+		Versioning:        lockEnabled,
+		ObjectLockEnabled: lockEnabled,
 	}
 	if err := h.repo.CreateBucket(c.Request.Context(), bucket); err != nil {
 		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
@@ -149,6 +158,17 @@ func (h *Handler) DeleteBucket(c *gin.Context) {
 		return
 	}
 
+	// 检查是否仍有受保护的对象
+	locked, err := h.repo.CountLockedObjects(c.Request.Context(), bucket.ID)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+	if locked > 0 {
+		h.sendError(c, http.StatusConflict, response.ErrBucketNotEmpty, fmt.Sprintf("Bucket contains %d objects under Object Lock", locked))
+		return
+	}
+
 	// 检查是否为空
 	count, _, err := h.repo.GetBucketStats(c.Request.Context(), bucket.ID)
 	if err != nil {
@@ -287,9 +307,31 @@ func (h *Handler) PutObject(c *gin.Context) {
 		}
 	}
 
+	// 解析对象标签
+	tags, err := parseTaggingHeader(c.GetHeader(taggingHeader))
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidTag, err.Error())
+		return
+	}
+
+	// 解析 Object Lock 设置，并拒绝覆盖受保护的对象
+	lock, err := resolveObjectLock(c, bucket)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidRequest, err.Error())
+		return
+	}
+	if !h.checkRemovable(c, bucket, key) {
+		return
+	}
+
 	// 获取 Content-Length
 	contentLength := c.Request.ContentLength
 
+	// 检查存储配额
+	if !h.checkObjectQuota(c, bucket, key, contentLength) {
+		return
+	}
+
 	// 存储对象
 	objInfo, err := h.storage.Put(c.Request.Context(), bucketName, key, c.Request.Body, contentLength, contentType)
 	if err != nil {
@@ -306,11 +348,18 @@ func (h *Handler) PutObject(c *gin.Context) {
 		ContentType:  contentType,
 		StorageClass: "STANDARD",
 		StoragePath:  objInfo.StoragePath,
+		Metadata:     metadata.MetadataFromHeader(c.Request.Header),
 	}
-	if err := h.repo.CreateObject(c.Request.Context(), obj); err != nil {
+	lock.apply(obj)
+	rules, err := h.replicationRules(c, bucket, key, tags, metadata.ReplicationOpPut)
+	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
 		return
 	}
+	obj.ReplicationStatus = replicationStatus(c, rules)
+	if !h.saveObject(c, bucket, obj, tags, rules) {
+		return
+	}
 
 	c.Header("ETag", fmt.Sprintf("\"%s\"", objInfo.ETag))
 	c.Status(http.StatusOK)
@@ -342,6 +391,10 @@ func (h *Handler) GetObject(c *gin.Context) {
 		h.sendError(c, http.StatusNotFound, response.ErrNoSuchKey, "Object not found")
 		return
 	}
+	if obj.IsCorrupt() {
+		h.sendError(c, http.StatusInternalServerError, response.ErrObjectCorrupted, corruptObjectMessage)
+		return
+	}
 
 	// 处理 Range 请求
 	rangeHeader := c.GetHeader("Range")
@@ -375,6 +428,8 @@ func (h *Handler) GetObject(c *gin.Context) {
 	c.Header("ETag", fmt.Sprintf("\"%s\"", obj.ETag))
 	c.Header("Last-Modified", obj.UpdatedAt.UTC().Format(http.TimeFormat))
 	c.Header("Accept-Ranges", "bytes")
+	setObjectLockHeaders(c, obj)
+	setObjectMetadataHeaders(c, obj)
 
 	c.Status(http.StatusOK)
 	io.Copy(c.Writer, reader)
@@ -428,6 +483,7 @@ func (h *Handler) handleRangeRequest(c *gin.Context, bucket, key string, obj *me
 	c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, obj.Size))
 	c.Header("ETag", fmt.Sprintf("\"%s\"", obj.ETag))
 	c.Header("Accept-Ranges", "bytes")
+	setObjectMetadataHeaders(c, obj)
 
 	c.Status(http.StatusPartialContent)
 	io.Copy(c.Writer, reader)
@@ -475,9 +531,23 @@ func (h *Handler) HeadObject(c *gin.Context) {
 	c.Header("ETag", fmt.Sprintf("\"%s\"", obj.ETag))
 	c.Header("Last-Modified", obj.UpdatedAt.UTC().Format(http.TimeFormat))
 	c.Header("Accept-Ranges", "bytes")
+	setObjectLockHeaders(c, obj)
+	setObjectMetadataHeaders(c, obj)
 	c.Status(http.StatusOK)
 }
 
+// setObjectMetadataHeaders 在 GET/HEAD 响应中输出用户元数据、保存的标准 Header 和复制状态
+func setObjectMetadataHeaders(c *gin.Context, obj *metadata.Object) {
+	for k, v := range obj.Metadata {
+		if metadata.IsObjectMetadataKey(k) {
+			c.Header(k, v)
+		}
+	}
+	if obj.ReplicationStatus != metadata.ReplicationStatusNone {
+		c.Header(replicationStatusHeader, obj.ReplicationStatus)
+	}
+}
+
 // DeleteObject DELETE /{bucket}/{key} - 删除对象
 func (h *Handler) DeleteObject(c *gin.Context) {
 	bucketName := c.Param("bucket")
@@ -494,16 +564,25 @@ func (h *Handler) DeleteObject(c *gin.Context) {
 		return
 	}
 
+	// 检查 Object Lock
+	if !h.checkRemovable(c, bucket, key) {
+		return
+	}
+
 	// 删除存储
 	if err := h.storage.Delete(c.Request.Context(), bucketName, key); err != nil {
 		// 忽略不存在的错误
 	}
 
-	// 删除元数据
-	if err := h.repo.DeleteObject(c.Request.Context(), bucket.ID, key); err != nil {
+	// 删除元数据，并按规则复制删除
+	rules, err := h.replicationRules(c, bucket, key, nil, metadata.ReplicationOpDelete)
+	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
 		return
 	}
+	if !h.removeObject(c, bucket, key, rules) {
+		return
+	}
 
 	c.Status(http.StatusNoContent)
 }
@@ -514,14 +593,11 @@ func (h *Handler) CopyObject(c *gin.Context) {
 	dstKey := c.Param("key")
 	dstKey = strings.TrimPrefix(dstKey, "/")
 
-	copySource := c.GetHeader("x-amz-copy-source")
-	copySource = strings.TrimPrefix(copySource, "/")
-	parts := strings.SplitN(copySource, "/", 2)
-	if len(parts) != 2 {
-		h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Invalid copy source")
+	srcBucket, srcKey, err := parseCopySource(c.GetHeader("x-amz-copy-source"))
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, err.Error())
 		return
 	}
-	srcBucket, srcKey := parts[0], parts[1]
 
 	// 验证源 Bucket
 	srcBucketMeta, err := h.repo.GetBucketByName(c.Request.Context(), srcBucket)
@@ -543,6 +619,61 @@ func (h *Handler) CopyObject(c *gin.Context) {
 		h.sendError(c, http.StatusNotFound, response.ErrNoSuchKey, "Source object not found")
 		return
 	}
+	if srcObj.IsCorrupt() {
+		h.sendError(c, http.StatusInternalServerError, response.ErrObjectCorrupted, corruptObjectMessage)
+		return
+	}
+	if !checkCopySourceConditions(c, srcObj) {
+		h.sendError(c, http.StatusPreconditionFailed, response.ErrPreconditionFailed, "Copy source precondition failed")
+		return
+	}
+
+	// 确定目标标签：默认复制源对象标签，REPLACE 时使用请求 Header
+	var tags []metadata.Tag
+	switch c.GetHeader("x-amz-tagging-directive") {
+	case "", "COPY":
+		tags, err = h.repo.GetObjectTags(c.Request.Context(), srcObj.ID)
+		if err != nil {
+			h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+			return
+		}
+	case "REPLACE":
+		tags, err = parseTaggingHeader(c.GetHeader(taggingHeader))
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, response.ErrInvalidTag, err.Error())
+			return
+		}
+	default:
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Unknown tagging directive")
+		return
+	}
+
+	// 确定目标元数据：默认复制源对象元数据，REPLACE 时使用请求 Header
+	contentType, objMetadata := srcObj.ContentType, srcObj.Metadata
+	switch c.GetHeader("x-amz-metadata-directive") {
+	case "", "COPY":
+	case "REPLACE":
+		objMetadata = metadata.MetadataFromHeader(c.Request.Header)
+		if ct := c.GetHeader("Content-Type"); ct != "" {
+			contentType = ct
+		}
+	default:
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Unknown metadata directive")
+		return
+	}
+
+	// 解析 Object Lock 设置，并拒绝覆盖受保护的目标对象
+	lock, err := resolveObjectLock(c, dstBucketMeta)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidRequest, err.Error())
+		return
+	}
+	if !h.checkRemovable(c, dstBucketMeta, dstKey) {
+		return
+	}
+	if !h.checkObjectQuota(c, dstBucketMeta, dstKey, srcObj.Size) {
+		return
+	}
 
 	// 复制存储
 	objInfo, err := h.storage.Copy(c.Request.Context(), srcBucket, srcKey, dstBucket, dstKey)
@@ -557,14 +688,21 @@ func (h *Handler) CopyObject(c *gin.Context) {
 		Key:          dstKey,
 		Size:         objInfo.Size,
 		ETag:         objInfo.ETag,
-		ContentType:  srcObj.ContentType,
+		ContentType:  contentType,
 		StorageClass: "STANDARD",
 		StoragePath:  objInfo.StoragePath,
+		Metadata:     objMetadata,
 	}
-	if err := h.repo.CreateObject(c.Request.Context(), obj); err != nil {
+	lock.apply(obj)
+	rules, err := h.replicationRules(c, dstBucketMeta, dstKey, tags, metadata.ReplicationOpPut)
+	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
 		return
 	}
+	obj.ReplicationStatus = replicationStatus(c, rules)
+	if !h.saveObject(c, dstBucketMeta, obj, tags, rules) {
+		return
+	}
 
 	result := response.CopyObjectResult{
 		LastModified: response.FormatTime(objInfo.LastModified),
@@ -587,6 +725,20 @@ func (h *Handler) CreateMultipartUpload(c *gin.Context) {
 		return
 	}
 
+	// 校验对象标签，完成上传时再写入
+	taggingValue := c.GetHeader(taggingHeader)
+	if _, err := parseTaggingHeader(taggingValue); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidTag, err.Error())
+		return
+	}
+
+	// 解析 Object Lock 设置，完成上传时再写入
+	lock, err := resolveObjectLock(c, bucket)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
 	uploadID := uuid.New().String()
 
 	// 初始化存储
@@ -601,8 +753,13 @@ func (h *Handler) CreateMultipartUpload(c *gin.Context) {
 		BucketID:    bucket.ID,
 		Key:         key,
 		ContentType: c.GetHeader("Content-Type"),
+		Metadata:    metadata.MetadataFromHeader(c.Request.Header),
 		Status:      "in_progress",
 	}
+	if taggingValue != "" {
+		upload.Metadata[taggingHeader] = taggingValue
+	}
+	lock.toMetadata(upload.Metadata)
 	if err := h.repo.CreateMultipartUpload(c.Request.Context(), upload); err != nil {
 		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
 		return
@@ -617,8 +774,30 @@ func (h *Handler) CreateMultipartUpload(c *gin.Context) {
 	c.XML(http.StatusOK, result)
 }
 
+// getUpload 获取分片上传任务，并确认它属于请求 URL 中的 Bucket 和 Key，
+// 避免以一个 Bucket 的权限操作其他 Bucket 的上传任务。失败时已写入错误响应
+func (h *Handler) getUpload(c *gin.Context, bucketName, key, uploadID string) (*metadata.MultipartUpload, bool) {
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return nil, false
+	}
+	upload, err := h.repo.GetMultipartUpload(c.Request.Context(), uploadID)
+	if err != nil || upload == nil || upload.BucketID != bucket.ID || upload.Key != key {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchUpload, "Upload not found")
+		return nil, false
+	}
+	return upload, true
+}
+
 // UploadPart PUT /{bucket}/{key}?partNumber=&uploadId= - 上传分片
 func (h *Handler) UploadPart(c *gin.Context) {
+	// 检查是否是分片复制
+	if c.GetHeader("x-amz-copy-source") != "" {
+		h.UploadPartCopy(c)
+		return
+	}
+
 	bucketName := c.Param("bucket")
 	key := c.Param("key")
 	key = strings.TrimPrefix(key, "/")
@@ -631,9 +810,13 @@ func (h *Handler) UploadPart(c *gin.Context) {
 	}
 
 	// 验证上传任务
-	upload, err := h.repo.GetMultipartUpload(c.Request.Context(), uploadID)
-	if err != nil || upload == nil {
-		h.sendError(c, http.StatusNotFound, response.ErrNoSuchUpload, "Upload not found")
+	upload, ok := h.getUpload(c, bucketName, key, uploadID)
+	if !ok {
+		return
+	}
+
+	// 检查存储配额
+	if !h.checkPartQuota(c, upload, partNumber, c.Request.ContentLength) {
 		return
 	}
 
@@ -674,7 +857,7 @@ func (h *Handler) CompleteMultipartUpload(c *gin.Context) {
 	}
 
 	upload, err := h.repo.GetMultipartUpload(c.Request.Context(), uploadID)
-	if err != nil || upload == nil {
+	if err != nil || upload == nil || upload.BucketID != bucket.ID || upload.Key != key {
 		h.sendError(c, http.StatusNotFound, response.ErrNoSuchUpload, "Upload not found")
 		return
 	}
@@ -695,6 +878,7 @@ func (h *Handler) CompleteMultipartUpload(c *gin.Context) {
 
 	// 构建分片列表
 	var parts []storage.PartInfo
+	var totalSize int64
 	for _, p := range completeReq.Parts {
 		found := false
 		for _, dbp := range dbParts {
@@ -704,6 +888,7 @@ func (h *Handler) CompleteMultipartUpload(c *gin.Context) {
 					ETag:       dbp.ETag,
 					Size:       dbp.Size,
 				})
+				totalSize += dbp.Size
 				found = true
 				break
 			}
@@ -714,6 +899,16 @@ func (h *Handler) CompleteMultipartUpload(c *gin.Context) {
 		}
 	}
 
+	// 拒绝覆盖受保护的对象
+	if !h.checkRemovable(c, bucket, key) {
+		return
+	}
+
+	// 检查存储配额
+	if !h.checkObjectQuota(c, bucket, key, totalSize) {
+		return
+	}
+
 	// 合并分片
 	objInfo, err := h.storage.CompleteParts(c.Request.Context(), bucketName, key, uploadID, parts)
 	if err != nil {
@@ -730,11 +925,20 @@ func (h *Handler) CompleteMultipartUpload(c *gin.Context) {
 		ContentType:  upload.ContentType,
 		StorageClass: "STANDARD",
 		StoragePath:  objInfo.StoragePath,
+		Metadata:     metadata.ObjectMetadata(upload.Metadata),
 	}
-	if err := h.repo.CreateObject(c.Request.Context(), obj); err != nil {
+	objectLockFromMetadata(upload.Metadata).apply(obj)
+	tags, _ := parseTaggingHeader(upload.Metadata[taggingHeader])
+	rules, err := h.replicationRules(c, bucket, key, tags, metadata.ReplicationOpPut)
+	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
 		return
 	}
+	obj.ReplicationStatus = replicationStatus(c, rules)
+	// 同时写入初始化时指定的标签
+	if !h.saveObject(c, bucket, obj, tags, rules) {
+		return
+	}
 
 	// 清理分片元数据
 	h.repo.DeleteUploadParts(c.Request.Context(), uploadID)
@@ -757,9 +961,7 @@ func (h *Handler) AbortMultipartUpload(c *gin.Context) {
 	key = strings.TrimPrefix(key, "/")
 	uploadID := c.Query("uploadId")
 
-	upload, err := h.repo.GetMultipartUpload(c.Request.Context(), uploadID)
-	if err != nil || upload == nil {
-		h.sendError(c, http.StatusNotFound, response.ErrNoSuchUpload, "Upload not found")
+	if _, ok := h.getUpload(c, bucketName, key, uploadID); !ok {
 		return
 	}
 
@@ -780,24 +982,51 @@ func (h *Handler) ListParts(c *gin.Context) {
 	key = strings.TrimPrefix(key, "/")
 	uploadID := c.Query("uploadId")
 
-	upload, err := h.repo.GetMultipartUpload(c.Request.Context(), uploadID)
-	if err != nil || upload == nil {
-		h.sendError(c, http.StatusNotFound, response.ErrNoSuchUpload, "Upload not found")
+	if _, ok := h.getUpload(c, bucketName, key, uploadID); !ok {
 		return
 	}
 
-	parts, err := h.repo.GetUploadParts(c.Request.Context(), uploadID)
+	// 解析分页参数
+	maxParts := 1000
+	if mp := c.Query("max-parts"); mp != "" {
+		v, err := strconv.Atoi(mp)
+		if err != nil || v < 0 {
+			h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Invalid max-parts")
+			return
+		}
+		if v < maxParts {
+			maxParts = v
+		}
+	}
+	partNumberMarker := 0
+	if pm := c.Query("part-number-marker"); pm != "" {
+		v, err := strconv.Atoi(pm)
+		if err != nil || v < 0 {
+			h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Invalid part-number-marker")
+			return
+		}
+		partNumberMarker = v
+	}
+
+	// 多取一条用于判断是否截断
+	parts, err := h.repo.ListUploadParts(c.Request.Context(), uploadID, partNumberMarker, maxParts+1)
 	if err != nil {
 		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
 		return
 	}
 
 	result := response.ListPartsResult{
-		Xmlns:    response.S3Xmlns,
-		Bucket:   bucketName,
-		Key:      key,
-		UploadId: uploadID,
-		MaxParts: 1000,
+		Xmlns:            response.S3Xmlns,
+		Bucket:           bucketName,
+		Key:              key,
+		UploadId:         uploadID,
+		PartNumberMarker: partNumberMarker,
+		MaxParts:         maxParts,
+	}
+
+	if len(parts) > maxParts {
+		result.IsTruncated = true
+		parts = parts[:maxParts]
 	}
 
 	for _, p := range parts {
@@ -807,12 +1036,103 @@ func (h *Handler) ListParts(c *gin.Context) {
 			ETag:         fmt.Sprintf("\"%s\"", p.ETag),
 			Size:         p.Size,
 		})
+		result.NextPartNumberMarker = p.PartNumber
 	}
 
 	c.XML(http.StatusOK, result)
 }
 
+// ListMultipartUploads GET /{bucket}?uploads - 列出进行中的分片上传
+func (h *Handler) ListMultipartUploads(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+	if bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	// 解析参数
+	prefix := c.Query("prefix")
+	delimiter := c.Query("delimiter")
+	keyMarker := c.Query("key-marker")
+	uploadIDMarker := c.Query("upload-id-marker")
+	maxUploads := 1000
+	if mu := c.Query("max-uploads"); mu != "" {
+		v, err := strconv.Atoi(mu)
+		if err != nil || v < 0 {
+			h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Invalid max-uploads")
+			return
+		}
+		if v < maxUploads {
+			maxUploads = v
+		}
+	}
+
+	// upload-id-marker 仅在指定 key-marker 时生效
+	if keyMarker == "" {
+		uploadIDMarker = ""
+	}
+
+	resp := response.ListMultipartUploadsResult{
+		Xmlns:          response.S3Xmlns,
+		Bucket:         bucketName,
+		KeyMarker:      keyMarker,
+		UploadIdMarker: uploadIDMarker,
+		Prefix:         prefix,
+		Delimiter:      delimiter,
+		MaxUploads:     maxUploads,
+	}
+	if maxUploads == 0 {
+		c.XML(http.StatusOK, resp)
+		return
+	}
+
+	opts := metadata.ListMultipartUploadsOptions{
+		Prefix:         prefix,
+		Delimiter:      delimiter,
+		KeyMarker:      keyMarker,
+		UploadIDMarker: uploadIDMarker,
+		MaxUploads:     maxUploads,
+	}
+	result, err := h.repo.ListMultipartUploads(c.Request.Context(), bucket.ID, opts)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	resp.IsTruncated = result.IsTruncated
+	if result.IsTruncated {
+		resp.NextKeyMarker = result.NextKeyMarker
+		resp.NextUploadIdMarker = result.NextUploadIDMarker
+	}
+
+	for _, u := range result.Uploads {
+		resp.Uploads = append(resp.Uploads, response.UploadInfo{
+			Key:          u.Key,
+			UploadId:     u.UploadID,
+			StorageClass: "STANDARD",
+			Initiated:    response.FormatTime(u.CreatedAt),
+		})
+	}
+
+	for _, prefix := range result.CommonPrefixes {
+		resp.CommonPrefixes = append(resp.CommonPrefixes, response.CommonPrefix{Prefix: prefix})
+	}
+
+	c.XML(http.StatusOK, resp)
+}
+
 // sendError 发送错误响应
 func (h *Handler) sendError(c *gin.Context, status int, code, message string) {
-	c.XML(status, response.NewError(code, message, c.Request.URL.Path))
+	// 服务端错误记录日志，带上请求 ID 和 trace_id 便于与链路对应
+	if status >= http.StatusInternalServerError {
+		logger.Ctx(c.Request.Context()).Errorf("%s %s failed: %s: %s", c.Request.Method, c.Request.URL.Path, code, message)
+	}
+	c.XML(status, response.NewError(code, message, c.Request.URL.Path).
+		WithRequestID(requestid.FromContext(c.Request.Context()), c.Writer.Header().Get(requestid.HeaderHostID)))
 }
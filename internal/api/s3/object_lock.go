@@ -0,0 +1,401 @@
+package s3
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/response"
+)
+
+// Object Lock 相关 Header
+const (
+	lockModeHeader        = "x-amz-object-lock-mode"
+	lockRetainUntilHeader = "x-amz-object-lock-retain-until-date"
+	lockLegalHoldHeader   = "x-amz-object-lock-legal-hold"
+	bucketLockHeader      = "x-amz-bucket-object-lock-enabled"
+	bypassGovernance      = "x-amz-bypass-governance-retention"
+)
+
+// permBypassGovernance 绕过 GOVERNANCE 保留所需的策略权限
+const permBypassGovernance = "s3:BypassGovernanceRetention"
+
+// objectLock 写入对象时生效的锁定设置
+type objectLock struct {
+	Mode        string
+	RetainUntil *time.Time
+	LegalHold   bool
+}
+
+// apply 将锁定设置写入对象元数据
+func (l *objectLock) apply(obj *metadata.Object) {
+	obj.LockMode = l.Mode
+	obj.RetainUntil = l.RetainUntil
+	obj.LegalHold = l.LegalHold
+}
+
+// toMetadata 将锁定设置保存到分片上传元数据中
+func (l *objectLock) toMetadata(m map[string]string) {
+	if l.Mode != "" {
+		m[lockModeHeader] = l.Mode
+		m[lockRetainUntilHeader] = l.RetainUntil.UTC().Format(time.RFC3339)
+	}
+	if l.LegalHold {
+		m[lockLegalHoldHeader] = "ON"
+	}
+}
+
+// objectLockFromMetadata 从分片上传元数据恢复锁定设置
+func objectLockFromMetadata(m map[string]string) *objectLock {
+	lock := &objectLock{LegalHold: m[lockLegalHoldHeader] == "ON"}
+	if mode := m[lockModeHeader]; mode != "" {
+		if t, err := time.Parse(time.RFC3339, m[lockRetainUntilHeader]); err == nil {
+			lock.Mode = mode
+			lock.RetainUntil = &t
+		}
+	}
+	return lock
+}
+
+// resolveObjectLock 根据请求 Header 和 Bucket 默认保留规则确定新对象的锁定设置
+func resolveObjectLock(c *gin.Context, bucket *metadata.Bucket) (*objectLock, error) {
+	mode := c.GetHeader(lockModeHeader)
+	retainUntil := c.GetHeader(lockRetainUntilHeader)
+	legalHold := c.GetHeader(lockLegalHoldHeader)
+
+	if !bucket.ObjectLockEnabled {
+		if mode != "" || retainUntil != "" || legalHold != "" {
+			return nil, fmt.Errorf("bucket is missing Object Lock configuration")
+		}
+		return &objectLock{}, nil
+	}
+
+	lock := &objectLock{}
+	switch legalHold {
+	case "", "OFF":
+	case "ON":
+		lock.LegalHold = true
+	default:
+		return nil, fmt.Errorf("invalid legal hold status: %s", legalHold)
+	}
+
+	if (mode == "") != (retainUntil == "") {
+		return nil, fmt.Errorf("x-amz-object-lock-mode and x-amz-object-lock-retain-until-date must be specified together")
+	}
+
+	if mode != "" {
+		if !metadata.IsValidLockMode(mode) {
+			return nil, fmt.Errorf("invalid object lock mode: %s", mode)
+		}
+		t, err := time.Parse(time.RFC3339, retainUntil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retain until date: %s", retainUntil)
+		}
+		if !t.After(time.Now()) {
+			return nil, fmt.Errorf("retain until date must be in the future")
+		}
+		lock.Mode = mode
+		lock.RetainUntil = &t
+	} else if bucket.DefaultRetentionMode != "" && (bucket.DefaultRetentionDays > 0 || bucket.DefaultRetentionYears > 0) {
+		t := time.Now().AddDate(bucket.DefaultRetentionYears, 0, bucket.DefaultRetentionDays)
+		lock.Mode = bucket.DefaultRetentionMode
+		lock.RetainUntil = &t
+	}
+
+	return lock, nil
+}
+
+// canBypassGovernance 判断请求是否可绕过 GOVERNANCE 保留：
+// 需携带 x-amz-bypass-governance-retention 且为管理员或被 Bucket 策略授予 s3:BypassGovernanceRetention
+func (h *Handler) canBypassGovernance(c *gin.Context, bucket *metadata.Bucket) bool {
+	if !strings.EqualFold(c.GetHeader(bypassGovernance), "true") {
+		return false
+	}
+	if c.GetBool("is_admin") {
+		return true
+	}
+
+	policyData, err := h.repo.GetBucketPolicy(c.Request.Context(), bucket.ID)
+	if err != nil || policyData == nil {
+		return false
+	}
+	policy, err := metadata.ParseBucketPolicy(policyData)
+	if err != nil {
+		return false
+	}
+	return policy.Allows(c.GetString("username"), permBypassGovernance, PolicyConditions(c.Request))
+}
+
+// checkRemovable 检查已有对象能否被删除或覆盖，不允许时写入错误响应并返回 false
+func (h *Handler) checkRemovable(c *gin.Context, bucket *metadata.Bucket, key string) bool {
+	if !bucket.ObjectLockEnabled {
+		return true
+	}
+
+	existing, err := h.repo.GetObject(c.Request.Context(), bucket.ID, key)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return false
+	}
+	if existing == nil {
+		return true
+	}
+
+	if err := existing.CheckRemovable(time.Now(), h.canBypassGovernance(c, bucket)); err != nil {
+		h.sendError(c, http.StatusForbidden, response.ErrAccessDenied, err.Error())
+		return false
+	}
+	return true
+}
+
+// setObjectLockHeaders 在 GET/HEAD 响应中输出对象锁定信息
+func setObjectLockHeaders(c *gin.Context, obj *metadata.Object) {
+	if obj.LockMode != "" && obj.RetainUntil != nil {
+		c.Header(lockModeHeader, obj.LockMode)
+		c.Header(lockRetainUntilHeader, response.FormatTime(*obj.RetainUntil))
+	}
+	if obj.LegalHold {
+		c.Header(lockLegalHoldHeader, "ON")
+	}
+}
+
+// ==================== Bucket Object Lock 配置 ====================
+
+// PutObjectLockConfiguration PUT /{bucket}?object-lock - 设置默认保留规则
+func (h *Handler) PutObjectLockConfiguration(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	if !bucket.ObjectLockEnabled {
+		h.sendError(c, http.StatusConflict, response.ErrInvalidBucketState, "Object Lock must be enabled when the bucket is created")
+		return
+	}
+
+	var config response.ObjectLockConfiguration
+	if err := xml.NewDecoder(c.Request.Body).Decode(&config); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "Invalid XML")
+		return
+	}
+	if config.ObjectLockEnabled != "Enabled" {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "ObjectLockEnabled must be Enabled")
+		return
+	}
+
+	mode, days, years := "", 0, 0
+	if config.Rule != nil {
+		retention := config.Rule.DefaultRetention
+		if !metadata.IsValidLockMode(retention.Mode) {
+			h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Invalid default retention mode")
+			return
+		}
+		if (retention.Days > 0) == (retention.Years > 0) {
+			h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Exactly one of Days or Years must be a positive value")
+			return
+		}
+		mode, days, years = retention.Mode, retention.Days, retention.Years
+	}
+
+	bucket.DefaultRetentionMode = mode
+	bucket.DefaultRetentionDays = days
+	bucket.DefaultRetentionYears = years
+	if err := h.repo.UpdateBucket(c.Request.Context(), bucket); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetObjectLockConfiguration GET /{bucket}?object-lock - 获取 Object Lock 配置
+func (h *Handler) GetObjectLockConfiguration(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	if !bucket.ObjectLockEnabled {
+		h.sendError(c, http.StatusNotFound, response.ErrObjectLockConfigMissing, "Object Lock configuration does not exist for this bucket")
+		return
+	}
+
+	result := response.ObjectLockConfiguration{
+		Xmlns:             response.S3Xmlns,
+		ObjectLockEnabled: "Enabled",
+	}
+	if bucket.DefaultRetentionMode != "" {
+		result.Rule = &response.ObjectLockRule{
+			DefaultRetention: response.DefaultRetention{
+				Mode:  bucket.DefaultRetentionMode,
+				Days:  bucket.DefaultRetentionDays,
+				Years: bucket.DefaultRetentionYears,
+			},
+		}
+	}
+	c.XML(http.StatusOK, result)
+}
+
+// ==================== Object Retention / Legal Hold ====================
+
+// PutObjectRetention PUT /{bucket}/{key}?retention - 设置对象保留
+func (h *Handler) PutObjectRetention(c *gin.Context) {
+	bucket, obj, ok := h.lookupLockedObject(c)
+	if !ok {
+		return
+	}
+
+	var req response.Retention
+	if err := xml.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "Invalid XML")
+		return
+	}
+
+	var retainUntil *time.Time
+	if req.Mode != "" || req.RetainUntilDate != "" {
+		if !metadata.IsValidLockMode(req.Mode) {
+			h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "Invalid retention mode")
+			return
+		}
+		t, err := time.Parse(time.RFC3339, req.RetainUntilDate)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "Invalid RetainUntilDate")
+			return
+		}
+		if !t.After(time.Now()) {
+			h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, "RetainUntilDate must be in the future")
+			return
+		}
+		retainUntil = &t
+	}
+
+	// 仅延长同模式的保留期无需额外权限；其余修改需检查当前保留状态
+	now := time.Now()
+	if obj.IsRetained(now) {
+		extending := req.Mode == obj.LockMode && retainUntil != nil && !retainUntil.Before(*obj.RetainUntil)
+		if !extending {
+			if obj.LockMode == metadata.LockModeCompliance {
+				h.sendError(c, http.StatusForbidden, response.ErrAccessDenied, "COMPLIANCE retention cannot be shortened, removed or changed")
+				return
+			}
+			if !h.canBypassGovernance(c, bucket) {
+				h.sendError(c, http.StatusForbidden, response.ErrAccessDenied, "Changing GOVERNANCE retention requires "+permBypassGovernance)
+				return
+			}
+		}
+	}
+
+	if err := h.repo.SetObjectRetention(c.Request.Context(), obj.ID, req.Mode, retainUntil); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetObjectRetention GET /{bucket}/{key}?retention - 获取对象保留
+func (h *Handler) GetObjectRetention(c *gin.Context) {
+	_, obj, ok := h.lookupLockedObject(c)
+	if !ok {
+		return
+	}
+
+	if obj.LockMode == "" || obj.RetainUntil == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchObjectLockConfig, "The specified object does not have a retention configuration")
+		return
+	}
+
+	c.XML(http.StatusOK, response.Retention{
+		Xmlns:           response.S3Xmlns,
+		Mode:            obj.LockMode,
+		RetainUntilDate: response.FormatTime(*obj.RetainUntil),
+	})
+}
+
+// PutObjectLegalHold PUT /{bucket}/{key}?legal-hold - 设置法律保留
+func (h *Handler) PutObjectLegalHold(c *gin.Context) {
+	_, obj, ok := h.lookupLockedObject(c)
+	if !ok {
+		return
+	}
+
+	var req response.LegalHold
+	if err := xml.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "Invalid XML")
+		return
+	}
+	if req.Status != "ON" && req.Status != "OFF" {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "Status must be ON or OFF")
+		return
+	}
+
+	if err := h.repo.SetObjectLegalHold(c.Request.Context(), obj.ID, req.Status == "ON"); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetObjectLegalHold GET /{bucket}/{key}?legal-hold - 获取法律保留
+func (h *Handler) GetObjectLegalHold(c *gin.Context) {
+	_, obj, ok := h.lookupLockedObject(c)
+	if !ok {
+		return
+	}
+
+	status := "OFF"
+	if obj.LegalHold {
+		status = "ON"
+	}
+	c.XML(http.StatusOK, response.LegalHold{Xmlns: response.S3Xmlns, Status: status})
+}
+
+// lookupLockedObject 获取启用了 Object Lock 的 Bucket 及其对象，失败时已写入错误响应
+func (h *Handler) lookupLockedObject(c *gin.Context) (*metadata.Bucket, *metadata.Object, bool) {
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return nil, nil, false
+	}
+	if bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return nil, nil, false
+	}
+	if !bucket.ObjectLockEnabled {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidRequest, "Bucket is missing Object Lock Configuration")
+		return nil, nil, false
+	}
+
+	obj, err := h.repo.GetObject(c.Request.Context(), bucket.ID, key)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return nil, nil, false
+	}
+	if obj == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchKey, "Object not found")
+		return nil, nil, false
+	}
+
+	return bucket, obj, true
+}
+
+// parseBucketLockHeader 解析创建 Bucket 时的 x-amz-bucket-object-lock-enabled
+func parseBucketLockHeader(c *gin.Context) bool {
+	enabled, _ := strconv.ParseBool(c.GetHeader(bucketLockHeader))
+	return enabled
+}
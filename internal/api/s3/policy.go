@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gooss/server/internal/metadata"
@@ -84,3 +85,10 @@ func (h *Handler) DeleteBucketPolicy(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// PolicyConditions 返回 Bucket 策略条件求值所需的请求属性
+func PolicyConditions(r *http.Request) metadata.ConditionValues {
+	return metadata.ConditionValues{
+		metadata.ConditionSecureTransport: strconv.FormatBool(r.TLS != nil),
+	}
+}
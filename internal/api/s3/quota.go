@@ -0,0 +1,155 @@
+package s3
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/logger"
+	"github.com/gooss/server/pkg/response"
+)
+
+// quotaWarningHeader 超出软配额时在响应中提示
+const quotaWarningHeader = "x-oss-quota-warning"
+
+// quotaTarget 写入需要检查的一项配额
+type quotaTarget struct {
+	scope string
+	id    int64
+	quota *metadata.Quota
+}
+
+// loadQuotas 读取 Bucket 和其所有者的配额，均未设置时返回空
+func (h *Handler) loadQuotas(c *gin.Context, bucket *metadata.Bucket) ([]quotaTarget, error) {
+	var targets []quotaTarget
+	for _, t := range []quotaTarget{
+		{scope: metadata.QuotaScopeBucket, id: bucket.ID},
+		{scope: metadata.QuotaScopeUser, id: bucket.OwnerID},
+	} {
+		quota, err := h.repo.GetQuota(c.Request.Context(), t.scope, t.id)
+		if err != nil {
+			return nil, err
+		}
+		if quota != nil {
+			t.quota = quota
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+// checkObjectQuota 检查向 key 写入 size 字节的对象是否超出配额，覆盖已有对象时只计算差值。
+// size 为负表示长度未知，此时设置了字节硬限制的请求被拒绝。
+// 检查与写入之间不加锁，并发写入可能小幅超出限制
+func (h *Handler) checkObjectQuota(c *gin.Context, bucket *metadata.Bucket, key string, size int64) bool {
+	targets, err := h.loadQuotas(c, bucket)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return false
+	}
+	if len(targets) == 0 {
+		return true
+	}
+
+	if !h.requireLength(c, targets, size) {
+		return false
+	}
+	if size < 0 {
+		size = 0
+	}
+
+	addBytes, addObjects := size, int64(1)
+	existing, err := h.repo.GetObject(c.Request.Context(), bucket.ID, key)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return false
+	}
+	if existing != nil {
+		addBytes -= existing.Size
+		addObjects = 0
+	}
+
+	return h.enforceQuotas(c, bucket, targets, addBytes, addObjects)
+}
+
+// checkPartQuota 检查上传分片后，本次上传已有分片加上新分片是否超出字节配额
+func (h *Handler) checkPartQuota(c *gin.Context, upload *metadata.MultipartUpload, partNumber int, size int64) bool {
+	bucket, err := h.repo.GetBucketByID(c.Request.Context(), upload.BucketID)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return false
+	}
+	targets, err := h.loadQuotas(c, bucket)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return false
+	}
+	if len(targets) == 0 {
+		return true
+	}
+
+	if !h.requireLength(c, targets, size) {
+		return false
+	}
+	if size < 0 {
+		size = 0
+	}
+
+	parts, err := h.repo.GetUploadParts(c.Request.Context(), upload.UploadID)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return false
+	}
+	addBytes := size
+	for _, p := range parts {
+		// 重传同一分片会替换旧数据
+		if p.PartNumber != partNumber {
+			addBytes += p.Size
+		}
+	}
+
+	return h.enforceQuotas(c, bucket, targets, addBytes, 0)
+}
+
+// requireLength 长度未知且设置了字节硬限制时拒绝请求
+func (h *Handler) requireLength(c *gin.Context, targets []quotaTarget, size int64) bool {
+	if size >= 0 {
+		return true
+	}
+	for _, t := range targets {
+		if t.quota.MaxBytes > 0 {
+			h.sendError(c, http.StatusLengthRequired, response.ErrMissingContentLength, "Content-Length is required when a storage quota applies")
+			return false
+		}
+	}
+	return true
+}
+
+// enforceQuotas 按当前用量检查各项配额：超出硬限制返回 QuotaExceeded，超出软限制只告警
+func (h *Handler) enforceQuotas(c *gin.Context, bucket *metadata.Bucket, targets []quotaTarget, addBytes, addObjects int64) bool {
+	ctx := c.Request.Context()
+	for _, t := range targets {
+		var usage *metadata.Usage
+		var err error
+		if t.scope == metadata.QuotaScopeBucket {
+			usage, err = h.repo.GetBucketUsage(ctx, t.id)
+		} else {
+			usage, err = h.repo.GetUserUsage(ctx, t.id)
+		}
+		if err != nil {
+			h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+			return false
+		}
+
+		hard, soft := t.quota.Check(*usage, addBytes, addObjects)
+		if hard != "" {
+			h.sendError(c, http.StatusForbidden, response.ErrQuotaExceeded, t.scope+" "+hard)
+			return false
+		}
+		if soft != "" {
+			c.Header(quotaWarningHeader, t.scope+" "+soft)
+			logger.Ctx(ctx).Warnf("Bucket %s write exceeds %s %s", bucket.Name, t.scope, soft)
+		}
+	}
+	return true
+}
@@ -0,0 +1,316 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/logger"
+	"github.com/gooss/server/pkg/response"
+)
+
+// replicationStatusHeader 对象复制状态。写入请求带 REPLICA 时表示由复制产生，不再向外复制，避免双向复制时循环
+const replicationStatusHeader = "x-amz-replication-status"
+
+// isReplicaWrite 判断请求是否是复制进程写入的副本。只信任管理员凭证（远端复制目标应使用
+// 目标集群的管理员凭证）携带的 REPLICA 标记，普通用户带该请求头时忽略，不能借此跳过复制
+func isReplicaWrite(c *gin.Context) bool {
+	return c.GetHeader(replicationStatusHeader) == metadata.ReplicationStatusReplica && c.GetBool("is_admin")
+}
+
+// replicationRules 返回写入或删除对象时需要复制到的目标规则，副本写入不复制
+func (h *Handler) replicationRules(c *gin.Context, bucket *metadata.Bucket, key string, tags []metadata.Tag, operation string) ([]metadata.ReplicationRule, error) {
+	if isReplicaWrite(c) {
+		return nil, nil
+	}
+	cfg, err := h.repo.GetBucketReplication(c.Request.Context(), bucket.ID)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	return cfg.RulesFor(key, tags, operation), nil
+}
+
+// replicationStatus 返回新写入对象的复制状态
+func replicationStatus(c *gin.Context, rules []metadata.ReplicationRule) string {
+	if isReplicaWrite(c) {
+		return metadata.ReplicationStatusReplica
+	}
+	if len(rules) > 0 {
+		return metadata.ReplicationStatusPending
+	}
+	return metadata.ReplicationStatusNone
+}
+
+// saveObject 在一个事务中保存对象元数据、标签和复制任务，对象不会停留在 PENDING 而没有对应的复制任务。
+// 失败时已写入错误响应
+func (h *Handler) saveObject(c *gin.Context, bucket *metadata.Bucket, obj *metadata.Object, tags []metadata.Tag, rules []metadata.ReplicationRule) bool {
+	return h.inTx(c, bucket, obj.Key, func(ctx context.Context, tx metadata.Repository) error {
+		if err := tx.CreateObject(ctx, obj); err != nil {
+			return err
+		}
+		// 覆盖写入时替换旧标签
+		if err := tx.SetObjectTags(ctx, obj.ID, tags); err != nil {
+			return err
+		}
+		return enqueueReplication(ctx, tx, bucket, obj.Key, rules, metadata.ReplicationOpPut)
+	})
+}
+
+// removeObject 在一个事务中删除对象元数据并加入删除复制任务，失败时已写入错误响应
+func (h *Handler) removeObject(c *gin.Context, bucket *metadata.Bucket, key string, rules []metadata.ReplicationRule) bool {
+	return h.inTx(c, bucket, key, func(ctx context.Context, tx metadata.Repository) error {
+		if err := tx.DeleteObject(ctx, bucket.ID, key); err != nil {
+			return err
+		}
+		return enqueueReplication(ctx, tx, bucket, key, rules, metadata.ReplicationOpDelete)
+	})
+}
+
+// inTx 在事务中执行 fn 并提交，失败时回滚并写入错误响应
+func (h *Handler) inTx(c *gin.Context, bucket *metadata.Bucket, key string, fn func(ctx context.Context, tx metadata.Repository) error) bool {
+	ctx := c.Request.Context()
+	tx, err := h.repo.BeginTx(ctx)
+	if err == nil {
+		defer tx.Rollback()
+		if err = fn(ctx, tx); err == nil {
+			err = tx.Commit()
+		}
+	}
+	if err != nil {
+		logger.Ctx(ctx).Errorf("Failed to save metadata of %s/%s: %v", bucket.Name, key, err)
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return false
+	}
+	return true
+}
+
+// enqueueReplication 将对象按规则加入复制队列
+func enqueueReplication(ctx context.Context, repo metadata.Repository, bucket *metadata.Bucket, key string, rules []metadata.ReplicationRule, operation string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	tasks := make([]metadata.ReplicationTask, 0, len(rules))
+	for _, rule := range rules {
+		tasks = append(tasks, metadata.ReplicationTask{
+			BucketID:    bucket.ID,
+			Key:         key,
+			Destination: rule.Destination,
+			RuleID:      rule.ID,
+			Operation:   operation,
+		})
+	}
+	return repo.EnqueueReplication(ctx, tasks)
+}
+
+// ==================== Bucket 复制配置 ====================
+
+// PutBucketReplication PUT /{bucket}?replication - 设置复制规则
+func (h *Handler) PutBucketReplication(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	var req response.ReplicationConfiguration
+	if err := xml.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "Invalid XML")
+		return
+	}
+	cfg, err := replicationFromXML(&req)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, err.Error())
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidArgument, err.Error())
+		return
+	}
+	if err := h.checkReplicationDestinations(c, bucket, cfg); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := h.repo.SetBucketReplication(c.Request.Context(), bucket.ID, cfg); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// GetBucketReplication GET /{bucket}?replication - 获取复制规则
+func (h *Handler) GetBucketReplication(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	cfg, err := h.repo.GetBucketReplication(c.Request.Context(), bucket.ID)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+	if cfg == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchReplicationConfig, "The replication configuration was not found")
+		return
+	}
+	c.XML(http.StatusOK, replicationToXML(cfg))
+}
+
+// DeleteBucketReplication DELETE /{bucket}?replication - 删除复制规则，未完成的复制任务一并丢弃
+func (h *Handler) DeleteBucketReplication(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	if err := h.repo.DeleteBucketReplication(c.Request.Context(), bucket.ID); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// checkReplicationDestinations 检查目标 Bucket 或远端目标存在，且不能复制到自身
+func (h *Handler) checkReplicationDestinations(c *gin.Context, bucket *metadata.Bucket, cfg *metadata.ReplicationConfiguration) error {
+	checked := make(map[string]bool)
+	for _, rule := range cfg.Rules {
+		if checked[rule.Destination] {
+			continue
+		}
+		checked[rule.Destination] = true
+
+		localBucket, targetID, _ := metadata.ParseReplicationDestination(rule.Destination)
+		if localBucket != "" {
+			if localBucket == bucket.Name {
+				return fmt.Errorf("destination bucket cannot be the source bucket")
+			}
+			dest, err := h.repo.GetBucketByName(c.Request.Context(), localBucket)
+			if err != nil {
+				return err
+			}
+			if dest == nil {
+				return fmt.Errorf("destination bucket %s does not exist", localBucket)
+			}
+			continue
+		}
+		target, err := h.repo.GetReplicationTarget(c.Request.Context(), targetID)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return fmt.Errorf("replication target %s does not exist", targetID)
+		}
+	}
+	return nil
+}
+
+// replicationFromXML 将 S3 复制配置转换为内部格式。规则过滤条件可以是旧版的 Prefix，
+// 或 Filter 中的 Prefix、Tag、And 之一
+func replicationFromXML(req *response.ReplicationConfiguration) (*metadata.ReplicationConfiguration, error) {
+	cfg := &metadata.ReplicationConfiguration{Role: req.Role}
+	for _, r := range req.Rules {
+		if r.Status != "Enabled" && r.Status != "Disabled" {
+			return nil, fmt.Errorf("rule status must be Enabled or Disabled")
+		}
+		if r.Destination.Bucket == "" {
+			return nil, fmt.Errorf("rule destination bucket is required")
+		}
+		if r.Destination.StorageClass != "" && r.Destination.StorageClass != "STANDARD" {
+			return nil, fmt.Errorf("destination storage class %s is not supported", r.Destination.StorageClass)
+		}
+		rule := metadata.ReplicationRule{
+			ID:          r.ID,
+			Priority:    r.Priority,
+			Enabled:     r.Status == "Enabled",
+			Destination: r.Destination.Bucket,
+		}
+
+		if r.Prefix != nil && r.Filter != nil {
+			return nil, fmt.Errorf("rule cannot specify both Prefix and Filter")
+		}
+		if r.Prefix != nil {
+			rule.Prefix = *r.Prefix
+		}
+		if f := r.Filter; f != nil {
+			set := 0
+			if f.Prefix != nil {
+				rule.Prefix = *f.Prefix
+				set++
+			}
+			if f.Tag != nil {
+				rule.Tags = []metadata.Tag{{Key: f.Tag.Key, Value: f.Tag.Value}}
+				set++
+			}
+			if f.And != nil {
+				rule.Prefix = f.And.Prefix
+				for _, tag := range f.And.Tags {
+					rule.Tags = append(rule.Tags, metadata.Tag{Key: tag.Key, Value: tag.Value})
+				}
+				set++
+			}
+			if set > 1 {
+				return nil, fmt.Errorf("filter must contain only one of Prefix, Tag or And")
+			}
+		}
+
+		if d := r.DeleteMarkerReplication; d != nil {
+			if d.Status != "Enabled" && d.Status != "Disabled" {
+				return nil, fmt.Errorf("delete marker replication status must be Enabled or Disabled")
+			}
+			rule.DeleteMarkerReplication = d.Status == "Enabled"
+		}
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+	return cfg, nil
+}
+
+// replicationToXML 将内部复制配置转换为 S3 格式，统一使用 Filter 表示过滤条件
+func replicationToXML(cfg *metadata.ReplicationConfiguration) response.ReplicationConfiguration {
+	result := response.ReplicationConfiguration{
+		Xmlns: response.S3Xmlns,
+		Role:  cfg.Role,
+	}
+	for _, rule := range cfg.Rules {
+		r := response.ReplicationRule{
+			ID:                      rule.ID,
+			Priority:                rule.Priority,
+			Status:                  "Disabled",
+			Filter:                  &response.ReplicationFilter{},
+			DeleteMarkerReplication: &response.DeleteMarkerReplication{Status: "Disabled"},
+			Destination:             response.ReplicationDestination{Bucket: rule.Destination},
+		}
+		if rule.Enabled {
+			r.Status = "Enabled"
+		}
+		if rule.DeleteMarkerReplication {
+			r.DeleteMarkerReplication.Status = "Enabled"
+		}
+		switch {
+		case len(rule.Tags) == 0:
+			prefix := rule.Prefix
+			r.Filter.Prefix = &prefix
+		case len(rule.Tags) == 1 && rule.Prefix == "":
+			r.Filter.Tag = &response.Tag{Key: rule.Tags[0].Key, Value: rule.Tags[0].Value}
+		default:
+			and := &response.ReplicationFilterAnd{Prefix: rule.Prefix}
+			for _, tag := range rule.Tags {
+				and.Tags = append(and.Tags, response.Tag{Key: tag.Key, Value: tag.Value})
+			}
+			r.Filter.And = and
+		}
+		result.Rules = append(result.Rules, r)
+	}
+	return result
+}
@@ -0,0 +1,228 @@
+package s3
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/response"
+)
+
+// taggingHeader 请求中携带对象标签的 Header
+const taggingHeader = "x-amz-tagging"
+
+// parseTaggingHeader 解析 x-amz-tagging Header（URL 查询串格式，如 k1=v1&k2=v2）
+func parseTaggingHeader(value string) ([]metadata.Tag, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	values, err := url.ParseQuery(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tagging header: %w", err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tags := make([]metadata.Tag, 0, len(keys))
+	for _, k := range keys {
+		if len(values[k]) > 1 {
+			return nil, fmt.Errorf("duplicate tag key %q", k)
+		}
+		tags = append(tags, metadata.Tag{Key: k, Value: values[k][0]})
+	}
+
+	if err := metadata.ValidateTags(tags, metadata.MaxObjectTags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// decodeTagging 解析请求体中的 Tagging XML
+func decodeTagging(c *gin.Context) ([]metadata.Tag, error) {
+	var req response.Tagging
+	if err := xml.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+
+	tags := make([]metadata.Tag, 0, len(req.TagSet.Tags))
+	for _, t := range req.TagSet.Tags {
+		tags = append(tags, metadata.Tag{Key: t.Key, Value: t.Value})
+	}
+	return tags, nil
+}
+
+// writeTagging 输出 Tagging XML
+func writeTagging(c *gin.Context, tags []metadata.Tag) {
+	result := response.Tagging{Xmlns: response.S3Xmlns}
+	for _, t := range tags {
+		result.TagSet.Tags = append(result.TagSet.Tags, response.Tag{Key: t.Key, Value: t.Value})
+	}
+	c.XML(http.StatusOK, result)
+}
+
+// ==================== Object Tagging ====================
+
+// PutObjectTagging PUT /{bucket}/{key}?tagging - 设置对象标签
+func (h *Handler) PutObjectTagging(c *gin.Context) {
+	obj, ok := h.lookupObject(c)
+	if !ok {
+		return
+	}
+
+	tags, err := decodeTagging(c)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "Invalid XML")
+		return
+	}
+	if err := metadata.ValidateTags(tags, metadata.MaxObjectTags); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidTag, err.Error())
+		return
+	}
+
+	if err := h.repo.SetObjectTags(c.Request.Context(), obj.ID, tags); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetObjectTagging GET /{bucket}/{key}?tagging - 获取对象标签
+func (h *Handler) GetObjectTagging(c *gin.Context) {
+	obj, ok := h.lookupObject(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.repo.GetObjectTags(c.Request.Context(), obj.ID)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	writeTagging(c, tags)
+}
+
+// DeleteObjectTagging DELETE /{bucket}/{key}?tagging - 删除对象标签
+func (h *Handler) DeleteObjectTagging(c *gin.Context) {
+	obj, ok := h.lookupObject(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteObjectTags(c.Request.Context(), obj.ID); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// lookupObject 根据路径参数获取对象元数据，失败时已写入错误响应
+func (h *Handler) lookupObject(c *gin.Context) (*metadata.Object, bool) {
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return nil, false
+	}
+	if bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return nil, false
+	}
+
+	obj, err := h.repo.GetObject(c.Request.Context(), bucket.ID, key)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return nil, false
+	}
+	if obj == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchKey, "Object not found")
+		return nil, false
+	}
+
+	return obj, true
+}
+
+// ==================== Bucket Tagging ====================
+
+// PutBucketTagging PUT /{bucket}?tagging - 设置 Bucket 标签
+func (h *Handler) PutBucketTagging(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	tags, err := decodeTagging(c)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrMalformedXML, "Invalid XML")
+		return
+	}
+	if err := metadata.ValidateTags(tags, metadata.MaxBucketTags); err != nil {
+		h.sendError(c, http.StatusBadRequest, response.ErrInvalidTag, err.Error())
+		return
+	}
+
+	if err := h.repo.SetBucketTags(c.Request.Context(), bucket.ID, tags); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetBucketTagging GET /{bucket}?tagging - 获取 Bucket 标签
+func (h *Handler) GetBucketTagging(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	tags, err := h.repo.GetBucketTags(c.Request.Context(), bucket.ID)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+	if len(tags) == 0 {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchTagSet, "The TagSet does not exist")
+		return
+	}
+
+	writeTagging(c, tags)
+}
+
+// DeleteBucketTagging DELETE /{bucket}?tagging - 删除 Bucket 标签
+func (h *Handler) DeleteBucketTagging(c *gin.Context) {
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.repo.GetBucketByName(c.Request.Context(), bucketName)
+	if err != nil || bucket == nil {
+		h.sendError(c, http.StatusNotFound, response.ErrNoSuchBucket, "Bucket not found")
+		return
+	}
+
+	if err := h.repo.DeleteBucketTags(c.Request.Context(), bucket.ID); err != nil {
+		h.sendError(c, http.StatusInternalServerError, response.ErrInternalError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
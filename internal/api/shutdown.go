@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gooss/server/internal/util"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// flushTimeout 请求排空后等待审计日志和用量计数写入的时长
+const flushTimeout = 5 * time.Second
+
+// Run 启动服务器，Shutdown 后返回 nil
+func (s *Server) Run(addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.engine,
+		ReadHeaderTimeout: 30 * time.Second,
+		TLSConfig:         s.tlsConfig(),
+	}
+	s.srvMu.Lock()
+	s.httpServer = srv
+	s.srvMu.Unlock()
+
+	return s.serve(srv)
+}
+
+// RunAdmin 在管理端口上提供管理 API、监控指标和性能分析接口，Shutdown 后返回 nil
+func (s *Server) RunAdmin(addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.adminEngine,
+		ReadHeaderTimeout: 30 * time.Second,
+		TLSConfig:         s.tlsConfig(),
+	}
+	s.srvMu.Lock()
+	s.adminServer = srv
+	s.srvMu.Unlock()
+
+	return s.serve(srv)
+}
+
+// serve 按是否配置了 TLS 选择监听方式，证书由 TLSConfig.GetCertificate 提供
+func (s *Server) serve(srv *http.Server) error {
+	var err error
+	if srv.TLSConfig != nil {
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// DrainTimeout 返回配置的优雅关闭总时长
+func (s *Server) DrainTimeout() time.Duration {
+	timeout := 30 * time.Second
+	if v := s.cfg.Server.DrainTimeout; v != "" {
+		if d, err := util.ParseDuration(v); err == nil && d > 0 {
+			timeout = d
+		} else {
+			logger.Warnf("Invalid server.drain_timeout %q, using %s", v, timeout)
+		}
+	}
+	return timeout
+}
+
+// readinessDelay 返回 /health 失败后等待负载均衡摘除实例的时长
+func (s *Server) readinessDelay() time.Duration {
+	delay := 5 * time.Second
+	if v := s.cfg.Server.ReadinessDelay; v != "" {
+		if d, err := util.ParseDuration(v); err == nil && d >= 0 {
+			delay = d
+		} else {
+			logger.Warnf("Invalid server.readiness_delay %q, using %s", v, delay)
+		}
+	}
+	return delay
+}
+
+// Shutdown 优雅关闭：先让 /health 返回 503 并等待负载均衡摘除实例，
+// 然后停止接受新连接并等待进行中的请求完成，最后写入未完成的审计日志和用量计数。
+// ctx 到期时强制关闭剩余连接
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.draining.Store(true)
+
+	if delay := s.readinessDelay(); delay > 0 {
+		logger.Infof("Health check now failing, waiting %s before draining connections", delay)
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+	}
+
+	s.srvMu.Lock()
+	servers := []*http.Server{s.httpServer, s.adminServer}
+	s.srvMu.Unlock()
+
+	var shutdownErr error
+	for _, srv := range servers {
+		if srv == nil {
+			continue
+		}
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Warnf("Forcing close of %s after drain timeout: %v", srv.Addr, err)
+			srv.Close()
+			shutdownErr = err
+		}
+	}
+
+	s.waitAuditWrites(flushTimeout)
+
+	if s.cfg.Usage.Enabled {
+		flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		defer cancel()
+		if err := s.usage.Flush(flushCtx); err != nil {
+			logger.Warnf("Failed to flush usage counters: %v", err)
+		}
+	}
+
+	return shutdownErr
+}
+
+// waitAuditWrites 等待异步审计日志写入完成。
+// 被强制关闭的连接上处理函数可能仍在运行并产生新的写入，因此轮询计数而不使用 WaitGroup
+func (s *Server) waitAuditWrites(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for s.auditPending.Load() > 0 {
+		if time.Now().After(deadline) {
+			logger.Warnf("Timed out waiting for %d pending audit log writes", s.auditPending.Load())
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/tlsutil"
+	"github.com/gooss/server/internal/util"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// serverTLS 监听端口使用的 TLS 配置，证书和客户端 CA 均可在运行时替换
+type serverTLS struct {
+	certs      *tlsutil.CertReloader
+	minVersion uint16
+	clientCAs  atomic.Pointer[x509.CertPool]
+}
+
+// LoadTLS 按配置加载证书，未启用 TLS 时不做处理。需要在 Run 之前调用
+func (s *Server) LoadTLS() error {
+	cfg := s.cfg.Server.TLS
+	if !cfg.Enabled {
+		return nil
+	}
+
+	minVersion, err := tlsutil.ParseMinVersion(cfg.MinVersion)
+	if err != nil {
+		return err
+	}
+	certs, err := tlsutil.NewCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	t := &serverTLS{certs: certs, minVersion: minVersion}
+	if cfg.ClientCAFile != "" {
+		pool, err := tlsutil.LoadCertPool(cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		t.clientCAs.Store(pool)
+	}
+	s.tls = t
+	return nil
+}
+
+// ReloadTLS 重新加载证书和客户端 CA（收到 SIGHUP 时调用），失败时继续使用旧证书
+func (s *Server) ReloadTLS() error {
+	if s.tls == nil {
+		return nil
+	}
+	if err := s.tls.certs.Reload(); err != nil {
+		return err
+	}
+	if path := s.cfg.Server.TLS.ClientCAFile; path != "" {
+		pool, err := tlsutil.LoadCertPool(path)
+		if err != nil {
+			return err
+		}
+		s.tls.clientCAs.Store(pool)
+		logger.Infof("Reloaded TLS client CA %s", path)
+	}
+	return nil
+}
+
+// watchTLS 定期检查证书文件变化
+func (s *Server) watchTLS(ctx context.Context) {
+	interval := 30 * time.Second
+	if v := s.cfg.Server.TLS.ReloadInterval; v != "" {
+		if d, err := util.ParseDuration(v); err == nil && d > 0 {
+			interval = d
+		} else {
+			logger.Warnf("Invalid server.tls.reload_interval %q, using %s", v, interval)
+		}
+	}
+	s.tls.certs.Watch(ctx, interval)
+}
+
+// tlsConfig 构造监听端口的 TLS 配置。配置了客户端 CA 时校验客户端提供的证书，
+// 是否必须提供证书由 requireClientCert 按路由决定，S3 客户端不受影响
+func (s *Server) tlsConfig() *tls.Config {
+	if s.tls == nil {
+		return nil
+	}
+	t := s.tls
+	base := &tls.Config{
+		MinVersion:     t.minVersion,
+		GetCertificate: t.certs.GetCertificate,
+	}
+	if t.clientCAs.Load() == nil {
+		return base
+	}
+	return &tls.Config{
+		MinVersion:     t.minVersion,
+		GetCertificate: t.certs.GetCertificate,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := base.Clone()
+			cfg.ClientAuth = tls.VerifyClientCertIfGiven
+			cfg.ClientCAs = t.clientCAs.Load()
+			return cfg, nil
+		},
+	}
+}
+
+// requireClientCert 配置了客户端 CA 时，要求请求携带已通过校验的客户端证书
+func (s *Server) requireClientCert() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.tls == nil || s.tls.clientCAs.Load() == nil {
+			c.Next()
+			return
+		}
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Client certificate required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// apiEndpoint 返回客户端访问 S3 API 的地址（前端据此生成预签名 URL）。
+// 未配置 server.api_endpoint 时按当前请求的协议和 Host 推断
+func (s *Server) apiEndpoint(c *gin.Context) string {
+	if endpoint := s.cfg.Server.APIEndpoint; endpoint != "" {
+		return endpoint
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
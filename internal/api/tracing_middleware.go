@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// TracingMiddleware 从请求头中提取 W3C trace context 并为每个请求创建 server span
+func (s *Server) TracingMiddleware() gin.HandlerFunc {
+	serviceName := s.cfg.Tracing.ServiceName
+	if serviceName == "" {
+		serviceName = "1103-oss"
+	}
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+	}))
+}
+
+// HandlerSpanMiddleware 为处理函数单独创建 span，与认证、审计阶段区分开
+func (s *Server) HandlerSpanMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, span := tracing.Start(c.Request.Context(), "handler",
+			attribute.String("oss.bucket", c.Param("bucket")),
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(
+			attribute.String("oss.operation", s.metricsOperation(c)),
+			attribute.Int("http.status_code", status),
+		)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+)
+
+// GetUsageReport 获取用量报表（仅管理员）
+// 时间范围使用 month=2006-01 或 from/to=2006-01-02（to 不包含），默认当月；
+// group_by 为 bucket（默认）、user 或 day；format=csv 时导出 CSV
+func (s *Server) GetUsageReport(c *gin.Context) {
+	isAdmin := c.GetBool("is_admin")
+	if !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can view usage reports"})
+		return
+	}
+
+	now := time.Now().UTC()
+	filter := &metadata.UsageReportFilter{
+		From:       time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		BucketName: c.Query("bucket"),
+		GroupBy:    c.DefaultQuery("group_by", "bucket"),
+	}
+	filter.To = filter.From.AddDate(0, 1, 0)
+
+	if month := c.Query("month"); month != "" {
+		start, err := time.Parse("2006-01", month)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month, expected YYYY-MM"})
+			return
+		}
+		filter.From = start
+		filter.To = start.AddDate(0, 1, 0)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from, expected YYYY-MM-DD"})
+			return
+		}
+		filter.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to, expected YYYY-MM-DD"})
+			return
+		}
+		filter.To = t
+	}
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		filter.OwnerID = &userID
+	}
+
+	switch filter.GroupBy {
+	case "bucket", "user", "day":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be bucket, user or day"})
+		return
+	}
+
+	rows, err := s.repo.GetUsageReport(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeUsageCSV(c, filter, rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":     filter.From.Format("2006-01-02"),
+		"to":       filter.To.Format("2006-01-02"),
+		"group_by": filter.GroupBy,
+		"rows":     rows,
+		"count":    len(rows),
+	})
+}
+
+// writeUsageCSV 以 CSV 附件导出用量报表
+func writeUsageCSV(c *gin.Context, filter *metadata.UsageReportFilter, rows []metadata.UsageReportRow) {
+	filename := fmt.Sprintf("usage-%s-%s-by-%s.csv",
+		filter.From.Format("20060102"), filter.To.Format("20060102"), filter.GroupBy)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	header := []string{"owner_id", "username"}
+	if filter.GroupBy == "day" {
+		header = append([]string{"day"}, header...)
+	}
+	if filter.GroupBy != "user" {
+		header = append(header, "bucket_name")
+	}
+	header = append(header, "storage_byte_hours", "class_a_requests", "class_b_requests",
+		"other_requests", "ingress_bytes", "egress_bytes")
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(header)
+	for _, row := range rows {
+		record := []string{strconv.FormatInt(row.OwnerID, 10), row.Username}
+		if filter.GroupBy == "day" && row.Day != nil {
+			record = append([]string{row.Day.Format("2006-01-02")}, record...)
+		}
+		if filter.GroupBy != "user" {
+			record = append(record, row.BucketName)
+		}
+		for _, v := range []int64{row.StorageByteHours, row.ClassARequests, row.ClassBRequests,
+			row.OtherRequests, row.IngressBytes, row.EgressBytes} {
+			record = append(record, strconv.FormatInt(v, 10))
+		}
+		w.Write(record)
+	}
+	w.Flush()
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/usage"
+	"github.com/gooss/server/internal/util"
+	"github.com/gooss/server/pkg/config"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// newAccountant 根据配置创建用量计费器，非法配置回退到默认值
+func newAccountant(cfg config.UsageConfig, repo metadata.Repository) *usage.Accountant {
+	flushInterval := time.Minute
+	if cfg.FlushInterval != "" {
+		if d, err := util.ParseDuration(cfg.FlushInterval); err == nil && d > 0 {
+			flushInterval = d
+		} else {
+			logger.Warnf("Invalid usage.flush_interval %q, using %s", cfg.FlushInterval, flushInterval)
+		}
+	}
+
+	sampleInterval := time.Hour
+	if cfg.SampleInterval != "" {
+		if d, err := util.ParseDuration(cfg.SampleInterval); err == nil && d > 0 {
+			sampleInterval = d
+		} else {
+			logger.Warnf("Invalid usage.sample_interval %q, using %s", cfg.SampleInterval, sampleInterval)
+		}
+	}
+
+	return usage.NewAccountant(repo, flushInterval, sampleInterval)
+}
+
+// UsageMiddleware 按请求类别和流量累计 S3 请求用量
+func (s *Server) UsageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		var body *countingReadCloser
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body = &countingReadCloser{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+
+		c.Next()
+
+		record := usage.Request{
+			BucketName: c.Param("bucket"),
+			Class:      requestClass(c),
+			Time:       startTime,
+		}
+		if uid, exists := c.Get("user_id"); exists {
+			if id, ok := uid.(int64); ok {
+				record.RequesterID = id
+			}
+		}
+		if body != nil {
+			record.Ingress = body.n.Load()
+		}
+		if size := c.Writer.Size(); size > 0 {
+			record.Egress = int64(size)
+		}
+		s.usage.Record(record)
+	}
+}
+
+// requestClass 按 S3 计费习惯划分请求类别：写入、复制和列举为 A 类，读取为 B 类，删除等不计费
+func requestClass(c *gin.Context) string {
+	switch c.Request.Method {
+	case http.MethodPut, http.MethodPost:
+		return metadata.RequestClassA
+	case http.MethodGet:
+		// Service 和 Bucket 级的 GET 主要是列举操作，与列举分片一起计为 A 类
+		key := c.Param("key")
+		if key == "" || key == "/" || c.Query("uploadId") != "" {
+			return metadata.RequestClassA
+		}
+		return metadata.RequestClassB
+	case http.MethodHead:
+		return metadata.RequestClassB
+	}
+	return metadata.RequestClassOther
+}
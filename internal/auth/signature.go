@@ -326,8 +326,8 @@ func hmacSHA256(key, data []byte) []byte {
 	return h.Sum(nil)
 }
 
-// GeneratePresignedURL 生成预签名 URL
-func (s *SignatureV4) GeneratePresignedURL(method, bucket, key string, expires time.Duration, host string) string {
+// GeneratePresignedURL 生成预签名 URL，scheme 为服务实际使用的协议（http 或 https）
+func (s *SignatureV4) GeneratePresignedURL(method, bucket, key string, expires time.Duration, scheme, host string) string {
 	now := time.Now().UTC()
 	dateTime := now.Format(TimeFormat)
 	date := now.Format(DateFormat)
@@ -351,5 +351,77 @@ func (s *SignatureV4) GeneratePresignedURL(method, bucket, key string, expires t
 
 	query.Set("X-Amz-Signature", signature)
 
-	return fmt.Sprintf("http://%s%s?%s", host, path, query.Encode())
+	return fmt.Sprintf("%s://%s%s?%s", scheme, host, path, query.Encode())
+}
+
+// SignRequest 以客户端身份为请求添加 Signature V4 签名头，payloadHash 为空时使用 UNSIGNED-PAYLOAD。
+// 规范 URI 使用请求实际发送的编码路径，路径应由 EncodePath 编码
+func (s *SignatureV4) SignRequest(r *http.Request, payloadHash string) {
+	if payloadHash == "" {
+		payloadHash = "UNSIGNED-PAYLOAD"
+	}
+	now := time.Now().UTC()
+	dateTime := now.Format(TimeFormat)
+	date := now.Format(DateFormat)
+
+	r.Header.Set("X-Amz-Date", dateTime)
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if r.Host == "" {
+		r.Host = r.URL.Host
+	}
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	for name := range r.Header {
+		lower := strings.ToLower(name)
+		if lower != "x-amz-content-sha256" && lower != "x-amz-date" &&
+			(strings.HasPrefix(lower, "x-amz-") || lower == "content-md5" || lower == "content-type") {
+			signedHeaders = append(signedHeaders, lower)
+		}
+	}
+	sort.Strings(signedHeaders)
+
+	// 查询参数按 RFC 3986 编码，空格为 %20
+	var pairs []string
+	query := r.URL.Query()
+	for k, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(pairs)
+
+	uri := r.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	canonicalRequest := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s",
+		r.Method, uri, strings.Join(pairs, "&"), s.buildCanonicalHeaders(r, signedHeaders),
+		strings.Join(signedHeaders, ";"), payloadHash)
+
+	stringToSign := s.buildStringToSign(dateTime, date, s.region, canonicalRequest)
+	signature := s.calculateSignature(s.secretKey, date, s.region, stringToSign)
+
+	r.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s/%s/%s/%s, SignedHeaders=%s, Signature=%s",
+		SignatureV4Algorithm, s.accessKey, date, s.region, ServiceName, TerminationString,
+		strings.Join(signedHeaders, ";"), signature))
+}
+
+// EncodePath 按 S3 签名规范编码对象路径，保留斜杠
+func EncodePath(path string) string {
+	return uriEncode(path, false)
+}
+
+// uriEncode 除 RFC 3986 非保留字符外全部百分号编码
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
 }
@@ -0,0 +1,217 @@
+package filesync
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+)
+
+// ExportOptions 导出选项
+type ExportOptions struct {
+	Bucket        string
+	Dest          string // 目标目录，不存在时创建
+	Prefix        string // 只导出该前缀下的对象，文件路径中去掉前缀
+	Concurrency   int
+	Resume        bool   // 跳过大小和修改时间都与对象一致的已有文件
+	Sidecars      bool   // 为带元数据、标签或非默认 Content-Type 的对象写出 sidecar 文件
+	SidecarSuffix string // 为空时使用 DefaultSidecarSuffix
+}
+
+// Export 将 Bucket 中的对象导出为目录树，文件 mtime 设为对象的最后修改时间。
+// 文件先写入同目录的临时文件，校验 MD5 后再改名，中断不会留下不完整的文件
+func (t *Transfer) Export(ctx context.Context, opts ExportOptions) (*Report, error) {
+	if opts.SidecarSuffix == "" {
+		opts.SidecarSuffix = DefaultSidecarSuffix
+	}
+	bucket, err := t.repo.GetBucketByName(ctx, opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket: %w", err)
+	}
+	if bucket == nil {
+		return nil, fmt.Errorf("bucket %s does not exist", opts.Bucket)
+	}
+	if err := os.MkdirAll(opts.Dest, 0755); err != nil {
+		return nil, err
+	}
+
+	report := newReport(bucket.Name, opts.Dest)
+	progressCtx, stopProgress := context.WithCancel(ctx)
+	defer stopProgress()
+	go report.logProgress(progressCtx, "Exporting from")
+
+	objects := make(chan metadata.Object, workers(opts.Concurrency))
+	var wg sync.WaitGroup
+	for i := 0; i < workers(opts.Concurrency); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range objects {
+				t.exportObject(ctx, bucket, &obj, opts, report)
+			}
+		}()
+	}
+
+	listErr := t.listObjects(ctx, bucket, opts.Prefix, objects)
+	close(objects)
+	wg.Wait()
+
+	if listErr != nil {
+		return nil, listErr
+	}
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// listObjects 按 Key 顺序分页列出对象，同一 Key 只输出一次
+func (t *Transfer) listObjects(ctx context.Context, bucket *metadata.Bucket, prefix string, objects chan<- metadata.Object) error {
+	marker, last := "", ""
+	for {
+		result, err := t.repo.ListObjects(ctx, bucket.ID, metadata.ListObjectsOptions{Prefix: prefix, Marker: marker, MaxKeys: 1000})
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range result.Objects {
+			if obj.Key == last {
+				continue
+			}
+			last = obj.Key
+			select {
+			case objects <- obj:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if !result.IsTruncated || result.NextMarker == "" {
+			return nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// exportObject 导出单个对象，失败计入报告
+func (t *Transfer) exportObject(ctx context.Context, bucket *metadata.Bucket, obj *metadata.Object, opts ExportOptions, report *Report) {
+	if ctx.Err() != nil {
+		return
+	}
+	rel := filepath.FromSlash(strings.TrimPrefix(obj.Key, opts.Prefix))
+	if rel == "" {
+		report.skipped()
+		return
+	}
+	// 拒绝 ../ 等会写到目标目录之外的 Key
+	if !filepath.IsLocal(rel) {
+		report.failed(obj.Key, fmt.Errorf("key cannot be exported as a local path"))
+		return
+	}
+	target := filepath.Join(opts.Dest, rel)
+
+	// 以 / 结尾的空对象是目录占位
+	if strings.HasSuffix(obj.Key, "/") && obj.Size == 0 {
+		if err := os.MkdirAll(target, 0755); err != nil {
+			report.failed(obj.Key, err)
+			return
+		}
+		report.transferred(0)
+		return
+	}
+
+	if opts.Resume {
+		if info, err := os.Stat(target); err == nil && info.Mode().IsRegular() &&
+			info.Size() == obj.Size && info.ModTime().Equal(obj.UpdatedAt) {
+			report.skipped()
+			return
+		}
+	}
+
+	if err := t.writeFile(ctx, bucket, obj, target); err != nil {
+		report.failed(obj.Key, err)
+		return
+	}
+	if opts.Sidecars {
+		if err := t.writeSidecar(ctx, obj, target+opts.SidecarSuffix); err != nil {
+			report.failed(obj.Key, err)
+			return
+		}
+	}
+	report.transferred(obj.Size)
+}
+
+// writeFile 通过临时文件写入对象内容，ETag 为内容 MD5 时校验后再改名
+func (t *Transfer) writeFile(ctx context.Context, bucket *metadata.Bucket, obj *metadata.Object, target string) error {
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	reader, _, err := t.storage.Get(ctx, bucket.Name, obj.Key)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+	defer reader.Close()
+
+	tmp, err := os.CreateTemp(dir, ".ossctl-export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := md5.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if n != obj.Size {
+		return fmt.Errorf("size mismatch: expected %d bytes, read %d", obj.Size, n)
+	}
+	if etag := hex.EncodeToString(hash.Sum(nil)); !strings.Contains(obj.ETag, "-") && !strings.EqualFold(etag, obj.ETag) {
+		return fmt.Errorf("checksum mismatch: ETag %s, content MD5 %s", obj.ETag, etag)
+	}
+
+	if err := os.Chtimes(tmp.Name(), obj.UpdatedAt, obj.UpdatedAt); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// writeSidecar 写出对象的元数据和标签；不需要 sidecar 时删除已有的旧文件
+func (t *Transfer) writeSidecar(ctx context.Context, obj *metadata.Object, name string) error {
+	tags, err := t.repo.GetObjectTags(ctx, obj.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	sidecar := &Sidecar{Metadata: metadata.ObjectMetadata(obj.Metadata)}
+	if obj.ContentType != "" && obj.ContentType != contentTypeFor(obj.Key) {
+		sidecar.ContentType = obj.ContentType
+	}
+	if len(tags) > 0 {
+		sidecar.Tags = make(map[string]string, len(tags))
+		for _, tag := range tags {
+			sidecar.Tags[tag.Key] = tag.Value
+		}
+	}
+
+	if sidecar.empty() {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0644)
+}
@@ -0,0 +1,168 @@
+// Package filesync 在本地目录树与 Bucket 之间导入导出对象，数据经由存储引擎读写，元数据写入元数据库
+package filesync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+)
+
+const (
+	// DefaultSidecarSuffix 元数据 sidecar 文件后缀，如 photo.jpg 的元数据保存在 photo.jpg.meta.json
+	DefaultSidecarSuffix = ".meta.json"
+	// defaultConcurrency 默认并发处理的文件数
+	defaultConcurrency = 8
+	// progressInterval 输出进度日志的间隔
+	progressInterval = 30 * time.Second
+)
+
+// Sidecar 对象的附加元数据。Metadata 的键可以是 Cache-Control 等标准 Header、x-amz-meta-* 或不带前缀的用户元数据名
+type Sidecar struct {
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// empty 判断 sidecar 是否没有任何内容
+func (s *Sidecar) empty() bool {
+	return s.ContentType == "" && len(s.Metadata) == 0 && len(s.Tags) == 0
+}
+
+// objectMetadata 将 sidecar 中的元数据转换为对象元数据，不带前缀的键视为用户元数据
+func (s *Sidecar) objectMetadata() map[string]string {
+	header := http.Header{}
+	for k, v := range s.Metadata {
+		if !metadata.IsObjectMetadataKey(http.CanonicalHeaderKey(k)) && !metadata.IsObjectMetadataKey(k) {
+			k = metadata.UserMetadataPrefix + k
+		}
+		header.Set(k, v)
+	}
+	return metadata.MetadataFromHeader(header)
+}
+
+// objectTags 按键排序返回标签
+func (s *Sidecar) objectTags() []metadata.Tag {
+	tags := make([]metadata.Tag, 0, len(s.Tags))
+	for k, v := range s.Tags {
+		tags = append(tags, metadata.Tag{Key: k, Value: v})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	return tags
+}
+
+// readSidecar 读取 sidecar 文件，不存在时返回 nil
+func readSidecar(name string) (*Sidecar, error) {
+	data, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sidecar Sidecar
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return nil, fmt.Errorf("invalid sidecar %s: %w", name, err)
+	}
+	return &sidecar, nil
+}
+
+// contentTypeFor 按扩展名推断 Content-Type
+func contentTypeFor(name string) string {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// Report 导入或导出结果
+type Report struct {
+	Bucket      string    `json:"bucket"`
+	Path        string    `json:"path"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Files       int64     `json:"files"`
+	Transferred int64     `json:"transferred"`
+	Skipped     int64     `json:"skipped"` // 续传时目标已是最新
+	Failed      int64     `json:"failed"`
+	Bytes       int64     `json:"bytes"`
+	Errors      []string  `json:"errors"`
+
+	mu sync.Mutex
+}
+
+func newReport(bucket, dir string) *Report {
+	return &Report{Bucket: bucket, Path: dir, StartedAt: time.Now(), Errors: []string{}}
+}
+
+func (r *Report) transferred(size int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Files++
+	r.Transferred++
+	r.Bytes += size
+}
+
+func (r *Report) skipped() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Files++
+	r.Skipped++
+}
+
+func (r *Report) failed(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Files++
+	r.Failed++
+	r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", name, err))
+}
+
+// logProgress 定期输出进度，直到 ctx 结束
+func (r *Report) logProgress(ctx context.Context, action string) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			logger.Infof("%s %s: %d files (%d transferred, %d skipped, %d failed), %d bytes",
+				action, r.Bucket, r.Files, r.Transferred, r.Skipped, r.Failed, r.Bytes)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// Transfer 在本地目录与 Bucket 之间导入导出对象
+type Transfer struct {
+	storage storage.Engine
+	repo    metadata.Repository
+}
+
+// New 创建导入导出器
+func New(storage storage.Engine, repo metadata.Repository) *Transfer {
+	return &Transfer{
+		storage: storage,
+		repo:    repo,
+	}
+}
+
+// workers 返回并发数，未设置时使用默认值
+func workers(n int) int {
+	if n <= 0 {
+		return defaultConcurrency
+	}
+	return n
+}
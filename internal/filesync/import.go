@@ -0,0 +1,294 @@
+package filesync
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooss/server/internal/auth"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Bucket        string
+	Source        string // 源目录
+	Prefix        string // 对象 Key 前缀，如 "photos/"
+	Owner         string // Bucket 不存在时以该用户身份创建，为空则要求 Bucket 已存在
+	Region        string
+	Concurrency   int
+	Resume        bool   // 跳过大小和修改时间都与文件一致的已有对象
+	SidecarSuffix string // 为空时使用 DefaultSidecarSuffix
+	// Writable 检查 Bucket 能否写入（如只读模式），为 nil 时不检查
+	Writable func(bucket string) error
+}
+
+// importFile 待导入的文件
+type importFile struct {
+	path string
+	key  string
+	info fs.FileInfo
+}
+
+// Import 将目录树中的文件导入 Bucket：相对路径作为 Key，文件 mtime 作为最后修改时间，
+// Content-Type 按扩展名推断，同名 sidecar 文件中的元数据和标签一并写入
+func (t *Transfer) Import(ctx context.Context, opts ImportOptions) (*Report, error) {
+	if opts.SidecarSuffix == "" {
+		opts.SidecarSuffix = DefaultSidecarSuffix
+	}
+	if info, err := os.Stat(opts.Source); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", opts.Source)
+	}
+
+	if err := opts.checkWritable(opts.Bucket); err != nil {
+		return nil, err
+	}
+	bucket, err := t.importBucket(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(bucket.Name, opts.Source)
+	progressCtx, stopProgress := context.WithCancel(ctx)
+	defer stopProgress()
+	go report.logProgress(progressCtx, "Importing into")
+
+	files := make(chan importFile, workers(opts.Concurrency))
+	var wg sync.WaitGroup
+	for i := 0; i < workers(opts.Concurrency); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range files {
+				t.importFile(ctx, bucket, f, opts, report)
+			}
+		}()
+	}
+
+	walkErr := filepath.WalkDir(opts.Source, func(p string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			report.failed(p, err)
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || strings.HasSuffix(d.Name(), opts.SidecarSuffix) {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			logger.Warnf("Skipping %s: not a regular file", p)
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			report.failed(p, err)
+			return nil
+		}
+		rel, err := filepath.Rel(opts.Source, p)
+		if err != nil {
+			report.failed(p, err)
+			return nil
+		}
+		select {
+		case files <- importFile{path: p, key: opts.Prefix + filepath.ToSlash(rel), info: info}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+	close(files)
+	wg.Wait()
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// checkWritable Bucket 处于只读模式时拒绝写入
+func (o ImportOptions) checkWritable(bucket string) error {
+	if o.Writable == nil {
+		return nil
+	}
+	if err := o.Writable(bucket); err != nil {
+		return fmt.Errorf("bucket %s is not writable: %w", bucket, err)
+	}
+	return nil
+}
+
+// importBucket 获取目标 Bucket，不存在且指定了 Owner 时创建
+func (t *Transfer) importBucket(ctx context.Context, opts ImportOptions) (*metadata.Bucket, error) {
+	bucket, err := t.repo.GetBucketByName(ctx, opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket: %w", err)
+	}
+	if bucket != nil {
+		return bucket, nil
+	}
+	if opts.Owner == "" {
+		return nil, fmt.Errorf("bucket %s does not exist, specify an owner to create it", opts.Bucket)
+	}
+	if err := auth.ValidateBucketName(opts.Bucket); err != nil {
+		return nil, err
+	}
+	owner, err := t.repo.GetUserByUsername(ctx, opts.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if owner == nil {
+		return nil, fmt.Errorf("user %s not found", opts.Owner)
+	}
+
+	if err := t.storage.CreateBucket(ctx, opts.Bucket); err != nil {
+		return nil, fmt.Errorf("failed to create bucket: %w", err)
+	}
+	region := opts.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	bucket = &metadata.Bucket{
+		Name:    opts.Bucket,
+		OwnerID: owner.ID,
+		Region:  region,
+		ACL:     "private",
+	}
+	if err := t.repo.CreateBucket(ctx, bucket); err != nil {
+		return nil, fmt.Errorf("failed to save bucket metadata: %w", err)
+	}
+	logger.Infof("Created bucket %s for %s", bucket.Name, opts.Owner)
+	return bucket, nil
+}
+
+// importFile 导入单个文件，失败计入报告
+func (t *Transfer) importFile(ctx context.Context, bucket *metadata.Bucket, f importFile, opts ImportOptions, report *Report) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := auth.ValidateObjectKey(f.key); err != nil {
+		report.failed(f.path, err)
+		return
+	}
+	// 数据库时间精度为微秒，截断后续传时才能与已导入对象比较
+	modTime := f.info.ModTime().UTC().Truncate(time.Microsecond)
+
+	existing, err := t.repo.GetObject(ctx, bucket.ID, f.key)
+	if err != nil {
+		report.failed(f.path, err)
+		return
+	}
+	if existing != nil {
+		if opts.Resume && existing.Size == f.info.Size() && existing.UpdatedAt.Equal(modTime) {
+			report.skipped()
+			return
+		}
+		if err := existing.CheckRemovable(time.Now(), false); err != nil {
+			report.failed(f.path, err)
+			return
+		}
+	}
+
+	sidecar, err := readSidecar(f.path + opts.SidecarSuffix)
+	if err != nil {
+		report.failed(f.path, err)
+		return
+	}
+	if sidecar == nil {
+		sidecar = &Sidecar{}
+	}
+	contentType := sidecar.ContentType
+	if contentType == "" {
+		contentType = contentTypeFor(f.key)
+	}
+	tags := sidecar.objectTags()
+	if err := metadata.ValidateTags(tags, metadata.MaxObjectTags); err != nil {
+		report.failed(f.path, err)
+		return
+	}
+
+	// 导入过程中开启只读模式时，之后的文件不再写入
+	if err := opts.checkWritable(bucket.Name); err != nil {
+		report.failed(f.path, err)
+		return
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		report.failed(f.path, err)
+		return
+	}
+	defer file.Close()
+
+	// 先写入暂存 Key，确认大小一致后再替换同名对象，避免文件在导入期间变化时
+	// 已有对象的数据被替换而元数据仍是旧的
+	staging := stagingKey(f.key)
+	info, err := t.storage.Put(ctx, bucket.Name, staging, file, f.info.Size(), contentType)
+	if err != nil {
+		report.failed(f.path, fmt.Errorf("failed to store object: %w", err))
+		return
+	}
+	if info.Size != f.info.Size() {
+		t.discardStaging(ctx, bucket.Name, staging)
+		report.failed(f.path, fmt.Errorf("file changed during import: expected %d bytes, stored %d", f.info.Size(), info.Size))
+		return
+	}
+	moved, err := t.storage.Rename(ctx, bucket.Name, staging, f.key)
+	if err != nil {
+		t.discardStaging(ctx, bucket.Name, staging)
+		report.failed(f.path, fmt.Errorf("failed to store object: %w", err))
+		return
+	}
+	info.StoragePath = moved.StoragePath
+
+	obj := &metadata.Object{
+		BucketID:     bucket.ID,
+		Key:          f.key,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		ContentType:  contentType,
+		StorageClass: "STANDARD",
+		StoragePath:  info.StoragePath,
+		Metadata:     sidecar.objectMetadata(),
+		UpdatedAt:    modTime,
+	}
+	if err := t.repo.CreateObject(ctx, obj); err != nil {
+		report.failed(f.path, fmt.Errorf("failed to save object metadata: %w", err))
+		return
+	}
+	if err := t.repo.SetObjectTags(ctx, obj.ID, tags); err != nil {
+		report.failed(f.path, fmt.Errorf("failed to save object tags: %w", err))
+		return
+	}
+	report.transferred(info.Size)
+}
+
+// stagingKey 导入写入使用的暂存 Key，与目标对象在同一目录下。以 .tmp- 开头，
+// 进程中断时残留的文件会被 fsck 识别为临时文件
+func stagingKey(key string) string {
+	name := ".tmp-import-" + uuid.New().String()
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		return key[:i+1] + name
+	}
+	return name
+}
+
+// discardStaging 删除未能替换目标对象的暂存文件
+func (t *Transfer) discardStaging(ctx context.Context, bucket, staging string) {
+	if err := t.storage.Delete(context.WithoutCancel(ctx), bucket, staging); err != nil {
+		logger.Warnf("Failed to remove staged object %s/%s: %v", bucket, staging, err)
+	}
+}
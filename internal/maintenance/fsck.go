@@ -0,0 +1,298 @@
+package maintenance
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage"
+)
+
+// 一致性问题类型
+const (
+	IssueMissingData  = "missing_data"  // 有元数据但数据文件不存在
+	IssueOrphanFile   = "orphan_file"   // 数据文件没有对应元数据
+	IssueSizeMismatch = "size_mismatch" // 文件大小与元数据不一致
+	IssueETagMismatch = "etag_mismatch" // 重新计算的 MD5 与 ETag 不一致
+	IssueTempFile     = "temp_file"     // 写入中断残留的 .tmp- 文件
+)
+
+// fsckGracePeriod 新写入文件的宽限期，避免把 PutObject 写入数据后、
+// 创建元数据前的文件误判为孤儿
+const fsckGracePeriod = time.Hour
+
+// FsckOptions 一致性检查选项
+type FsckOptions struct {
+	Repair   bool   // 修复可自动处理的问题
+	Bucket   string // 仅检查指定 Bucket，为空表示全部
+	SkipHash bool   // 跳过 ETag 校验，只比较大小
+}
+
+// FsckIssue 单个一致性问题
+type FsckIssue struct {
+	Type     string `json:"type"`
+	Bucket   string `json:"bucket"`
+	Key      string `json:"key"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
+
+// FsckReport 一致性检查报告
+type FsckReport struct {
+	Repair         bool           `json:"repair"`
+	StartedAt      time.Time      `json:"started_at"`
+	FinishedAt     time.Time      `json:"finished_at"`
+	BucketsChecked int            `json:"buckets_checked"`
+	ObjectsChecked int64          `json:"objects_checked"`
+	FilesChecked   int64          `json:"files_checked"`
+	BytesHashed    int64          `json:"bytes_hashed"`
+	Summary        map[string]int `json:"summary"`
+	Issues         []FsckIssue    `json:"issues"`
+	Errors         []string       `json:"errors"`
+}
+
+// Unresolved 返回未修复的问题数量
+func (r *FsckReport) Unresolved() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *FsckReport) addIssue(issue FsckIssue) {
+	r.Issues = append(r.Issues, issue)
+	r.Summary[issue.Type]++
+}
+
+// Fsck 元数据与存储数据一致性检查器
+type Fsck struct {
+	storage storage.Engine
+	repo    metadata.Repository
+}
+
+// NewFsck 创建一致性检查器
+func NewFsck(storage storage.Engine, repo metadata.Repository) *Fsck {
+	return &Fsck{
+		storage: storage,
+		repo:    repo,
+	}
+}
+
+// Run 执行一致性检查
+//
+// 修复策略：数据缺失时删除元数据，受 Object Lock 保护的对象只标记为 missing；孤儿文件和临时文件超过宽限期后删除；
+// 大小/ETag 不一致无法自动恢复，只报告
+func (f *Fsck) Run(ctx context.Context, opts FsckOptions) (*FsckReport, error) {
+	report := &FsckReport{
+		Repair:    opts.Repair,
+		StartedAt: time.Now(),
+		Summary:   make(map[string]int),
+		Issues:    []FsckIssue{},
+		Errors:    []string{},
+	}
+
+	buckets, err := f.repo.ListAllBuckets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %w", err)
+	}
+	dirs, err := f.storage.ListBucketDirs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bucket directories: %w", err)
+	}
+
+	known := make(map[string]bool, len(buckets))
+	for _, bucket := range buckets {
+		known[bucket.Name] = true
+		if opts.Bucket != "" && bucket.Name != opts.Bucket {
+			continue
+		}
+		if err := f.checkBucket(ctx, &bucket, opts, report); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.Errors = append(report.Errors, fmt.Sprintf("bucket %s: %v", bucket.Name, err))
+		}
+		report.BucketsChecked++
+	}
+
+	// 没有元数据的 Bucket 目录，其中所有文件都是孤儿
+	for _, dir := range dirs {
+		if known[dir] || (opts.Bucket != "" && dir != opts.Bucket) {
+			continue
+		}
+		err := f.storage.WalkBucket(ctx, dir, func(file storage.StoredFile) error {
+			report.FilesChecked++
+			f.handleStrayFile(ctx, dir, file, opts, report)
+			return nil
+		})
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("walk %s: %v", dir, err))
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// checkBucket 双向比对单个 Bucket 的元数据与文件
+func (f *Fsck) checkBucket(ctx context.Context, bucket *metadata.Bucket, opts FsckOptions, report *FsckReport) error {
+	keys := make(map[string]bool)
+
+	// 1. 元数据 -> 数据
+	marker := ""
+	for {
+		result, err := f.repo.ListObjects(ctx, bucket.ID, metadata.ListObjectsOptions{Marker: marker, MaxKeys: 1000})
+		if err != nil {
+			return err
+		}
+
+		for i := range result.Objects {
+			obj := &result.Objects[i]
+			keys[obj.Key] = true
+			report.ObjectsChecked++
+			f.checkObject(ctx, bucket, obj, opts, report)
+		}
+
+		if !result.IsTruncated || result.NextMarker == "" {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	// 2. 数据 -> 元数据
+	return f.storage.WalkBucket(ctx, bucket.Name, func(file storage.StoredFile) error {
+		report.FilesChecked++
+		if !file.Temp && keys[file.Key] {
+			return nil
+		}
+		f.handleStrayFile(ctx, bucket.Name, file, opts, report)
+		return nil
+	})
+}
+
+// repairMissing 删除数据已丢失对象的元数据。受 Object Lock 保护的对象保留记录，
+// 标记为 missing 并作为未修复问题报告
+func (f *Fsck) repairMissing(ctx context.Context, bucket *metadata.Bucket, obj *metadata.Object, issue *FsckIssue) {
+	if err := obj.CheckRemovable(time.Now(), false); err != nil {
+		issue.Error = "metadata kept: " + err.Error()
+		if _, err := f.repo.SetObjectIntegrity(ctx, obj.ID, obj.ETag, metadata.IntegrityMissing, time.Now()); err != nil {
+			issue.Error += "; failed to mark missing: " + err.Error()
+		}
+		return
+	}
+	if err := f.repo.DeleteObject(ctx, bucket.ID, obj.Key); err != nil {
+		issue.Error = err.Error()
+		return
+	}
+	issue.Repaired = true
+}
+
+// checkObject 校验单个对象的数据文件
+func (f *Fsck) checkObject(ctx context.Context, bucket *metadata.Bucket, obj *metadata.Object, opts FsckOptions, report *FsckReport) {
+	// 以 / 结尾的空对象是目录占位，没有对应文件
+	if strings.HasSuffix(obj.Key, "/") && obj.Size == 0 {
+		return
+	}
+
+	exists, err := f.storage.Exists(ctx, bucket.Name, obj.Key)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("stat %s/%s: %v", bucket.Name, obj.Key, err))
+		return
+	}
+	if !exists {
+		issue := FsckIssue{Type: IssueMissingData, Bucket: bucket.Name, Key: obj.Key, Expected: obj.ETag}
+		if opts.Repair {
+			f.repairMissing(ctx, bucket, obj, &issue)
+		}
+		report.addIssue(issue)
+		return
+	}
+
+	info, err := f.storage.Stat(ctx, bucket.Name, obj.Key)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("stat %s/%s: %v", bucket.Name, obj.Key, err))
+		return
+	}
+	if info.Size != obj.Size {
+		report.addIssue(FsckIssue{
+			Type:     IssueSizeMismatch,
+			Bucket:   bucket.Name,
+			Key:      obj.Key,
+			Expected: fmt.Sprintf("%d", obj.Size),
+			Actual:   fmt.Sprintf("%d", info.Size),
+		})
+		return
+	}
+
+	if opts.SkipHash {
+		return
+	}
+
+	etag, n, err := f.hashObject(ctx, bucket.Name, obj.Key)
+	report.BytesHashed += n
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("hash %s/%s: %v", bucket.Name, obj.Key, err))
+		return
+	}
+	if etag != strings.Trim(obj.ETag, "\"") {
+		report.addIssue(FsckIssue{
+			Type:     IssueETagMismatch,
+			Bucket:   bucket.Name,
+			Key:      obj.Key,
+			Expected: obj.ETag,
+			Actual:   etag,
+		})
+	}
+}
+
+// handleStrayFile 处理没有元数据的文件和临时文件
+func (f *Fsck) handleStrayFile(ctx context.Context, bucket string, file storage.StoredFile, opts FsckOptions, report *FsckReport) {
+	issueType := IssueOrphanFile
+	if file.Temp {
+		issueType = IssueTempFile
+	}
+
+	issue := FsckIssue{
+		Type:   issueType,
+		Bucket: bucket,
+		Key:    file.Key,
+		Actual: fmt.Sprintf("%d", file.Size),
+	}
+
+	if opts.Repair {
+		if time.Since(file.ModTime) < fsckGracePeriod {
+			issue.Error = "modified within grace period, skipped"
+		} else if err := f.storage.Delete(ctx, bucket, file.Key); err != nil {
+			issue.Error = err.Error()
+		} else {
+			issue.Repaired = true
+		}
+	}
+	report.addIssue(issue)
+}
+
+// hashObject 重新读取对象并计算 MD5
+func (f *Fsck) hashObject(ctx context.Context, bucket, key string) (string, int64, error) {
+	reader, _, err := f.storage.Get(ctx, bucket, key)
+	if err != nil {
+		return "", 0, err
+	}
+	defer reader.Close()
+
+	hash := md5.New()
+	n, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", n, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), n, nil
+}
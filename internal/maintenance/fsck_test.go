@@ -0,0 +1,64 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+)
+
+// fsckRepo 记录修复数据缺失时对元数据的操作
+type fsckRepo struct {
+	metadata.Repository
+	deleted  []string
+	statuses map[int64]string
+}
+
+func (r *fsckRepo) DeleteObject(ctx context.Context, bucketID int64, key string) error {
+	r.deleted = append(r.deleted, key)
+	return nil
+}
+
+func (r *fsckRepo) SetObjectIntegrity(ctx context.Context, objectID int64, etag, status string, verifiedAt time.Time) (bool, error) {
+	r.statuses[objectID] = status
+	return true, nil
+}
+
+func TestFsckRepairMissingKeepsLockedObjects(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		obj         metadata.Object
+		wantDeleted bool
+	}{
+		{"unlocked", metadata.Object{ID: 1, Key: "a.txt"}, true},
+		{"expired retention", metadata.Object{ID: 2, Key: "a.txt", LockMode: metadata.LockModeCompliance, RetainUntil: &past}, true},
+		{"compliance", metadata.Object{ID: 3, Key: "a.txt", LockMode: metadata.LockModeCompliance, RetainUntil: &future}, false},
+		{"governance", metadata.Object{ID: 4, Key: "a.txt", LockMode: metadata.LockModeGovernance, RetainUntil: &future}, false},
+		{"legal hold", metadata.Object{ID: 5, Key: "a.txt", LegalHold: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fsckRepo{statuses: map[int64]string{}}
+			fsck := NewFsck(newTestEngine(t), repo)
+			report := &FsckReport{Summary: map[string]int{}}
+			obj := tt.obj
+			fsck.checkObject(context.Background(), &metadata.Bucket{ID: 1, Name: "bucket"}, &obj, FsckOptions{Repair: true}, report)
+
+			if len(report.Issues) != 1 || report.Issues[0].Type != IssueMissingData {
+				t.Fatalf("issues = %+v, want one %s", report.Issues, IssueMissingData)
+			}
+			issue := report.Issues[0]
+			if deleted := len(repo.deleted) > 0; deleted != tt.wantDeleted || issue.Repaired != tt.wantDeleted {
+				t.Errorf("deleted = %v, repaired = %v, want %v", deleted, issue.Repaired, tt.wantDeleted)
+			}
+			if !tt.wantDeleted && repo.statuses[obj.ID] != metadata.IntegrityMissing {
+				t.Errorf("status = %q, want %q", repo.statuses[obj.ID], metadata.IntegrityMissing)
+			}
+		})
+	}
+}
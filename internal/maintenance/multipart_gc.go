@@ -0,0 +1,257 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/metrics"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// orphanGracePeriod 暂存目录没有对应数据库记录时的宽限期，
+// 避免误删 InitMultipartUpload 已建目录但尚未写入元数据的上传
+const orphanGracePeriod = time.Hour
+
+// MultipartGC 过期分片上传清理器
+type MultipartGC struct {
+	storage  storage.Engine
+	repo     metadata.Repository
+	maxAge   time.Duration
+	interval time.Duration
+
+	// runMu 保证同一时间只有一次清理，mu 保护 lastRun
+	runMu   sync.Mutex
+	mu      sync.Mutex
+	lastRun *MultipartGCReport
+
+	runs           atomic.Int64
+	uploadsAborted atomic.Int64
+	orphansRemoved atomic.Int64
+	bytesFreed     atomic.Int64
+	errors         atomic.Int64
+}
+
+// StaleUpload 待清理的分片上传
+type StaleUpload struct {
+	UploadID  string    `json:"upload_id"`
+	Bucket    string    `json:"bucket,omitempty"`
+	Key       string    `json:"key,omitempty"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MultipartGCReport 单次清理结果
+type MultipartGCReport struct {
+	DryRun       bool          `json:"dry_run"`
+	MaxAge       string        `json:"max_age"`
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   time.Time     `json:"finished_at"`
+	StaleUploads []StaleUpload `json:"stale_uploads"`
+	OrphanDirs   []StaleUpload `json:"orphan_dirs"`
+	BytesFreed   int64         `json:"bytes_freed"`
+	Errors       []string      `json:"errors"`
+}
+
+// MultipartGCStats 清理器累计指标
+type MultipartGCStats struct {
+	Runs           int64              `json:"runs"`
+	UploadsAborted int64              `json:"uploads_aborted"`
+	OrphansRemoved int64              `json:"orphans_removed"`
+	BytesFreed     int64              `json:"bytes_freed"`
+	Errors         int64              `json:"errors"`
+	LastRun        *MultipartGCReport `json:"last_run,omitempty"`
+}
+
+// NewMultipartGC 创建分片上传清理器
+func NewMultipartGC(storage storage.Engine, repo metadata.Repository, maxAge, interval time.Duration) *MultipartGC {
+	return &MultipartGC{
+		storage:  storage,
+		repo:     repo,
+		maxAge:   maxAge,
+		interval: interval,
+	}
+}
+
+// Start 按配置间隔在后台运行清理，ctx 取消后退出
+func (g *MultipartGC) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report := g.Run(ctx, false)
+				if len(report.StaleUploads) > 0 || len(report.OrphanDirs) > 0 || len(report.Errors) > 0 {
+					logger.Infof("Multipart GC aborted %d uploads, removed %d orphan dirs, freed %d bytes, %d errors",
+						len(report.StaleUploads), len(report.OrphanDirs), report.BytesFreed, len(report.Errors))
+				}
+			}
+		}
+	}()
+}
+
+// Run 执行一次清理；dryRun 为 true 时只返回将被清理的内容
+func (g *MultipartGC) Run(ctx context.Context, dryRun bool) *MultipartGCReport {
+	g.runMu.Lock()
+	defer g.runMu.Unlock()
+
+	now := time.Now()
+	report := &MultipartGCReport{
+		DryRun:       dryRun,
+		MaxAge:       g.maxAge.String(),
+		StartedAt:    now,
+		StaleUploads: []StaleUpload{},
+		OrphanDirs:   []StaleUpload{},
+		Errors:       []string{},
+	}
+
+	staged, err := g.storage.ListStagedUploads(ctx)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	stagedByID := make(map[string]storage.StagedUpload, len(staged))
+	for _, s := range staged {
+		stagedByID[s.UploadID] = s
+	}
+
+	// 1. 中止超过最大时长的上传
+	uploads, err := g.repo.ListMultipartUploadsBefore(ctx, now.Add(-g.maxAge))
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("list stale uploads: %v", err))
+	}
+
+	bucketNames := make(map[int64]string)
+	for _, upload := range uploads {
+		bucketName, ok := bucketNames[upload.BucketID]
+		if !ok {
+			if bucket, err := g.repo.GetBucketByID(ctx, upload.BucketID); err == nil && bucket != nil {
+				bucketName = bucket.Name
+			}
+			bucketNames[upload.BucketID] = bucketName
+		}
+
+		stale := StaleUpload{
+			UploadID:  upload.UploadID,
+			Bucket:    bucketName,
+			Key:       upload.Key,
+			Size:      stagedByID[upload.UploadID].Size,
+			CreatedAt: upload.CreatedAt,
+		}
+
+		if !dryRun {
+			if err := g.abort(ctx, stale); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("abort %s: %v", upload.UploadID, err))
+				continue
+			}
+			report.BytesFreed += stale.Size
+		}
+		report.StaleUploads = append(report.StaleUploads, stale)
+	}
+
+	// 2. 清理没有数据库记录的暂存目录
+	for _, s := range staged {
+		if now.Sub(s.ModTime) < orphanGracePeriod {
+			continue
+		}
+		upload, err := g.repo.GetMultipartUpload(ctx, s.UploadID)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("lookup %s: %v", s.UploadID, err))
+			continue
+		}
+		if upload != nil {
+			continue
+		}
+
+		orphan := StaleUpload{UploadID: s.UploadID, Size: s.Size, CreatedAt: s.ModTime}
+		if !dryRun {
+			if err := g.storage.AbortMultipartUpload(ctx, "", "", s.UploadID); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("remove orphan %s: %v", s.UploadID, err))
+				continue
+			}
+			g.audit(ctx, orphan, "orphan")
+			report.BytesFreed += orphan.Size
+		}
+		report.OrphanDirs = append(report.OrphanDirs, orphan)
+	}
+
+	report.FinishedAt = time.Now()
+
+	if !dryRun {
+		g.runs.Add(1)
+		g.uploadsAborted.Add(int64(len(report.StaleUploads)))
+		g.orphansRemoved.Add(int64(len(report.OrphanDirs)))
+		g.bytesFreed.Add(report.BytesFreed)
+		g.errors.Add(int64(len(report.Errors)))
+
+		g.mu.Lock()
+		g.lastRun = report
+		g.mu.Unlock()
+	}
+
+	return report
+}
+
+// Stats 返回累计指标
+func (g *MultipartGC) Stats() MultipartGCStats {
+	g.mu.Lock()
+	lastRun := g.lastRun
+	g.mu.Unlock()
+
+	return MultipartGCStats{
+		Runs:           g.runs.Load(),
+		UploadsAborted: g.uploadsAborted.Load(),
+		OrphansRemoved: g.orphansRemoved.Load(),
+		BytesFreed:     g.bytesFreed.Load(),
+		Errors:         g.errors.Load(),
+		LastRun:        lastRun,
+	}
+}
+
+// abort 中止单个分片上传，与 AbortMultipartUpload 接口的清理步骤一致
+func (g *MultipartGC) abort(ctx context.Context, upload StaleUpload) error {
+	if err := g.storage.AbortMultipartUpload(ctx, upload.Bucket, upload.Key, upload.UploadID); err != nil {
+		return err
+	}
+	if err := g.repo.DeleteUploadParts(ctx, upload.UploadID); err != nil {
+		return err
+	}
+	if err := g.repo.DeleteMultipartUpload(ctx, upload.UploadID); err != nil {
+		return err
+	}
+	g.audit(ctx, upload, "stale")
+	return nil
+}
+
+// audit 记录清理操作的审计日志
+func (g *MultipartGC) audit(ctx context.Context, upload StaleUpload, reason string) {
+	meta, _ := json.Marshal(map[string]interface{}{
+		"reason":     reason,
+		"upload_id":  upload.UploadID,
+		"size":       upload.Size,
+		"created_at": upload.CreatedAt,
+	})
+
+	log := &metadata.AuditLog{
+		Username:     metadata.SystemUsername,
+		Action:       metadata.ActionAbortMultipart,
+		ResourceType: metadata.ResourceTypeMultipart,
+		ResourceName: upload.UploadID,
+		BucketName:   upload.Bucket,
+		ObjectKey:    upload.Key,
+		StatusCode:   200,
+		Metadata:     meta,
+	}
+	if err := g.repo.CreateAuditLog(ctx, log); err != nil {
+		metrics.AuditLogWriteFailures.Inc()
+		logger.Warnf("Failed to write audit log for multipart GC: %v", err)
+	}
+}
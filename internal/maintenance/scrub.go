@@ -0,0 +1,378 @@
+package maintenance
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// errObjectChanged 修复前发现对象已被覆盖或删除
+var errObjectChanged = errors.New("object changed during scrub")
+
+// Scrubber 后台数据完整性巡检：按最久未校验优先的顺序重读对象数据并与 ETag 比对
+type Scrubber struct {
+	storage        storage.Engine
+	redundant      storage.Engine // 可选的冗余副本，为 nil 时不修复
+	repo           metadata.Repository
+	interval       time.Duration
+	reverifyAfter  time.Duration
+	batchSize      int
+	bytesPerSecond int64
+
+	// runMu 保证同一时间只有一轮巡检，mu 保护 lastRun
+	runMu   sync.Mutex
+	mu      sync.Mutex
+	lastRun *ScrubReport
+
+	passes          atomic.Int64
+	objectsVerified atomic.Int64
+	bytesRead       atomic.Int64
+	corruptFound    atomic.Int64
+	repaired        atomic.Int64
+	errors          atomic.Int64
+}
+
+// ScrubFinding 巡检发现的异常对象
+type ScrubFinding struct {
+	Bucket   string `json:"bucket"`
+	Key      string `json:"key"`
+	Status   string `json:"status"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ScrubReport 单轮巡检结果
+type ScrubReport struct {
+	StartedAt       time.Time      `json:"started_at"`
+	FinishedAt      time.Time      `json:"finished_at"`
+	ObjectsVerified int64          `json:"objects_verified"`
+	BytesRead       int64          `json:"bytes_read"`
+	Findings        []ScrubFinding `json:"findings"`
+	Errors          []string       `json:"errors"`
+}
+
+// ScrubStats 巡检累计指标
+type ScrubStats struct {
+	Passes          int64            `json:"passes"`
+	ObjectsVerified int64            `json:"objects_verified"`
+	BytesRead       int64            `json:"bytes_read"`
+	CorruptFound    int64            `json:"corrupt_found"`
+	Repaired        int64            `json:"repaired"`
+	Errors          int64            `json:"errors"`
+	ObjectsByStatus map[string]int64 `json:"objects_by_status,omitempty"`
+	LastRun         *ScrubReport     `json:"last_run,omitempty"`
+}
+
+// ScrubOptions 巡检参数
+type ScrubOptions struct {
+	Interval       time.Duration
+	ReverifyAfter  time.Duration
+	BatchSize      int
+	BytesPerSecond int64
+}
+
+// NewScrubber 创建巡检器，redundant 可为 nil
+func NewScrubber(storage, redundant storage.Engine, repo metadata.Repository, opts ScrubOptions) *Scrubber {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	return &Scrubber{
+		storage:        storage,
+		redundant:      redundant,
+		repo:           repo,
+		interval:       opts.Interval,
+		reverifyAfter:  opts.ReverifyAfter,
+		batchSize:      opts.BatchSize,
+		bytesPerSecond: opts.BytesPerSecond,
+	}
+}
+
+// Start 在后台循环巡检：每轮处理完所有到期对象后等待 interval，ctx 取消后退出
+func (s *Scrubber) Start(ctx context.Context) {
+	go func() {
+		for {
+			report := s.Run(ctx)
+			if len(report.Findings) > 0 || len(report.Errors) > 0 {
+				logger.Infof("Scrub verified %d objects (%d bytes), %d findings, %d errors",
+					report.ObjectsVerified, report.BytesRead, len(report.Findings), len(report.Errors))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.interval):
+			}
+		}
+	}()
+}
+
+// Run 执行一轮巡检，处理所有校验已过期的对象
+func (s *Scrubber) Run(ctx context.Context) *ScrubReport {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	report := &ScrubReport{
+		StartedAt: time.Now(),
+		Findings:  []ScrubFinding{},
+		Errors:    []string{},
+	}
+	defer func() {
+		report.FinishedAt = time.Now()
+		s.passes.Add(1)
+
+		s.mu.Lock()
+		s.lastRun = report
+		s.mu.Unlock()
+	}()
+
+	bucketNames := make(map[int64]string)
+	for ctx.Err() == nil {
+		// 每批都会把已处理对象的 verified_at 更新为当前时间，因此以本轮开始时间为基准不会重复处理
+		objects, err := s.repo.ListObjectsToVerify(ctx, report.StartedAt.Add(-s.reverifyAfter), s.batchSize)
+		if err != nil {
+			s.recordError(report, fmt.Sprintf("list objects: %v", err))
+			return report
+		}
+		if len(objects) == 0 {
+			return report
+		}
+
+		progressed := false
+		for i := range objects {
+			obj := &objects[i]
+			bucketName, ok := bucketNames[obj.BucketID]
+			if !ok {
+				bucket, err := s.repo.GetBucketByID(ctx, obj.BucketID)
+				if err != nil || bucket == nil {
+					s.recordError(report, fmt.Sprintf("lookup bucket %d: %v", obj.BucketID, err))
+					return report
+				}
+				bucketName = bucket.Name
+				bucketNames[obj.BucketID] = bucketName
+			}
+
+			if s.verify(ctx, bucketName, obj, report) {
+				progressed = true
+			}
+			if ctx.Err() != nil {
+				return report
+			}
+		}
+
+		// 整批都未能记录结果（例如数据库写入失败），避免原地重试
+		if !progressed {
+			return report
+		}
+	}
+	return report
+}
+
+// Counters 返回累计计数，不查询数据库
+func (s *Scrubber) Counters() ScrubStats {
+	s.mu.Lock()
+	lastRun := s.lastRun
+	s.mu.Unlock()
+
+	return ScrubStats{
+		Passes:          s.passes.Load(),
+		ObjectsVerified: s.objectsVerified.Load(),
+		BytesRead:       s.bytesRead.Load(),
+		CorruptFound:    s.corruptFound.Load(),
+		Repaired:        s.repaired.Load(),
+		Errors:          s.errors.Load(),
+		LastRun:         lastRun,
+	}
+}
+
+// Stats 返回累计指标和各完整性状态的对象数
+func (s *Scrubber) Stats(ctx context.Context) ScrubStats {
+	stats := s.Counters()
+	if counts, err := s.repo.CountObjectsByIntegrity(ctx); err == nil {
+		stats.ObjectsByStatus = counts
+	}
+	return stats
+}
+
+// verify 校验单个对象并记录结果，返回结果是否已写入数据库
+func (s *Scrubber) verify(ctx context.Context, bucket string, obj *metadata.Object, report *ScrubReport) bool {
+	expected := strings.Trim(obj.ETag, "\"")
+	status := metadata.IntegrityOK
+	finding := ScrubFinding{Bucket: bucket, Key: obj.Key, Expected: expected}
+
+	// 以 / 结尾的空对象是目录占位，没有数据文件
+	if !(strings.HasSuffix(obj.Key, "/") && obj.Size == 0) {
+		actual, n, err := s.hash(ctx, s.storage, bucket, obj.Key)
+		report.BytesRead += n
+		s.bytesRead.Add(n)
+		if ctx.Err() != nil {
+			return false
+		}
+
+		switch {
+		case err != nil:
+			// 只有确认文件不存在才标记为缺失；其他读取错误（如 EIO、EMFILE、权限）可能是暂时的，
+			// 不判定为损坏，但仍记录 read_error 和校验时间，避免同一批读不出的对象
+			// 始终排在队首而使本轮巡检无法推进到其他对象
+			exists, existsErr := s.storage.Exists(ctx, bucket, obj.Key)
+			if existsErr != nil || exists {
+				s.recordError(report, fmt.Sprintf("read %s/%s: %v", bucket, obj.Key, err))
+				if _, err := s.repo.SetObjectIntegrity(ctx, obj.ID, obj.ETag, metadata.IntegrityReadError, time.Now()); err != nil {
+					s.recordError(report, fmt.Sprintf("record %s/%s: %v", bucket, obj.Key, err))
+					return false
+				}
+				return true
+			}
+			status = metadata.IntegrityMissing
+			finding.Error = err.Error()
+		case actual != expected:
+			status = metadata.IntegrityCorrupt
+			finding.Actual = actual
+		}
+
+		if status != metadata.IntegrityOK && s.redundant != nil {
+			if err := s.repair(ctx, bucket, obj, expected); errors.Is(err, errObjectChanged) {
+				// 新数据会在之后重新校验，不能用旧副本覆盖或恢复已删除的对象
+				return true
+			} else if err != nil {
+				if finding.Error != "" {
+					finding.Error += "; "
+				}
+				finding.Error += "repair failed: " + err.Error()
+			} else {
+				status = metadata.IntegrityRepaired
+			}
+		}
+	}
+
+	updated, err := s.repo.SetObjectIntegrity(ctx, obj.ID, obj.ETag, status, time.Now())
+	if err != nil {
+		s.recordError(report, fmt.Sprintf("record %s/%s: %v", bucket, obj.Key, err))
+		return false
+	}
+	if !updated {
+		// 校验期间对象被覆盖，新数据会在之后重新校验
+		return true
+	}
+
+	report.ObjectsVerified++
+	s.objectsVerified.Add(1)
+
+	if status != metadata.IntegrityOK {
+		finding.Status = status
+		report.Findings = append(report.Findings, finding)
+		if status == metadata.IntegrityRepaired {
+			s.repaired.Add(1)
+			logger.Warnf("Scrub repaired %s/%s from redundant copy", bucket, obj.Key)
+		} else {
+			s.corruptFound.Add(1)
+			logger.Errorf("Scrub marked %s/%s as %s (expected %s, actual %s, error %s)",
+				bucket, obj.Key, status, expected, finding.Actual, finding.Error)
+		}
+	}
+	return true
+}
+
+// repair 从冗余副本恢复对象：先确认副本完好，且对象在巡检期间未被覆盖或删除，
+// 再写回主存储并校验写入结果
+func (s *Scrubber) repair(ctx context.Context, bucket string, obj *metadata.Object, expected string) error {
+	key := obj.Key
+	actual, _, err := s.hash(ctx, s.redundant, bucket, key)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("redundant copy is also corrupt (etag %s)", actual)
+	}
+
+	reader, info, err := s.redundant.Get(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	current, err := s.repo.GetObject(ctx, obj.BucketID, key)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.ID != obj.ID || current.ETag != obj.ETag {
+		return errObjectChanged
+	}
+
+	written, err := s.storage.Put(ctx, bucket, key, s.throttle(ctx, reader), info.Size, "")
+	if err != nil {
+		return err
+	}
+	if written.ETag != expected {
+		return fmt.Errorf("restored data etag mismatch (etag %s)", written.ETag)
+	}
+	return nil
+}
+
+// hash 以限速方式读取对象并计算 MD5
+func (s *Scrubber) hash(ctx context.Context, engine storage.Engine, bucket, key string) (string, int64, error) {
+	reader, _, err := engine.Get(ctx, bucket, key)
+	if err != nil {
+		return "", 0, err
+	}
+	defer reader.Close()
+
+	h := md5.New()
+	n, err := io.Copy(h, s.throttle(ctx, reader))
+	if err != nil {
+		return "", n, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func (s *Scrubber) throttle(ctx context.Context, r io.Reader) io.Reader {
+	if s.bytesPerSecond <= 0 {
+		return r
+	}
+	return &throttledReader{ctx: ctx, reader: r, rate: s.bytesPerSecond, start: time.Now()}
+}
+
+func (s *Scrubber) recordError(report *ScrubReport, msg string) {
+	report.Errors = append(report.Errors, msg)
+	s.errors.Add(1)
+}
+
+// throttledReader 按字节速率限制读取
+type throttledReader struct {
+	ctx    context.Context
+	reader io.Reader
+	rate   int64
+	start  time.Time
+	read   int64
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	// 单次读取不超过 1/10 秒的配额，使限速更平滑
+	if max := t.rate / 10; max > 0 && int64(len(p)) > max {
+		p = p[:max]
+	}
+
+	n, err := t.reader.Read(p)
+	t.read += int64(n)
+
+	expected := time.Duration(float64(t.read) / float64(t.rate) * float64(time.Second))
+	if wait := expected - time.Since(t.start); wait > 0 {
+		select {
+		case <-t.ctx.Done():
+			return n, t.ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return n, err
+}
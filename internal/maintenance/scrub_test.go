@@ -0,0 +1,146 @@
+package maintenance
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/internal/storage/local"
+	"github.com/gooss/server/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Init("error", "console", "stderr", ""); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// scrubRepo 只实现巡检用到的方法，current 模拟巡检期间对象行的最新状态
+type scrubRepo struct {
+	metadata.Repository
+	current  *metadata.Object
+	statuses map[int64]string
+}
+
+func (r *scrubRepo) GetObject(ctx context.Context, bucketID int64, key string) (*metadata.Object, error) {
+	return r.current, nil
+}
+
+func (r *scrubRepo) SetObjectIntegrity(ctx context.Context, objectID int64, etag, status string, verifiedAt time.Time) (bool, error) {
+	if r.current == nil || r.current.ID != objectID || r.current.ETag != etag {
+		return false, nil
+	}
+	r.statuses[objectID] = status
+	return true, nil
+}
+
+func etagOf(data string) string {
+	sum := md5.Sum([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestEngine(t *testing.T) storage.Engine {
+	t.Helper()
+	engine, err := local.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+func putString(t *testing.T, engine storage.Engine, key, data string) {
+	t.Helper()
+	if _, err := engine.Put(context.Background(), "bucket", key, strings.NewReader(data), int64(len(data)), ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readString(t *testing.T, engine storage.Engine, key string) (string, bool) {
+	t.Helper()
+	reader, _, err := engine.Get(context.Background(), "bucket", key)
+	if err != nil {
+		return "", false
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), true
+}
+
+func TestScrubRepairSkipsChangedObjects(t *testing.T) {
+	const old, fresh = "old data", "new data"
+	scanned := metadata.Object{ID: 1, BucketID: 1, Key: "a.txt", Size: int64(len(old)), ETag: etagOf(old)}
+
+	tests := []struct {
+		name       string
+		primary    string // 为空表示主存储中没有数据文件
+		current    *metadata.Object
+		want       string
+		wantExists bool
+		wantStatus string
+	}{
+		{
+			name:       "unchanged corrupt object is repaired",
+			primary:    "bad data",
+			current:    &scanned,
+			want:       old,
+			wantExists: true,
+			wantStatus: metadata.IntegrityRepaired,
+		},
+		{
+			name:       "overwritten during scrub",
+			primary:    fresh,
+			current:    &metadata.Object{ID: 1, BucketID: 1, Key: "a.txt", Size: int64(len(fresh)), ETag: etagOf(fresh)},
+			want:       fresh,
+			wantExists: true,
+		},
+		{
+			name:       "overwritten with new row during scrub",
+			primary:    fresh,
+			current:    &metadata.Object{ID: 2, BucketID: 1, Key: "a.txt", Size: int64(len(fresh)), ETag: etagOf(fresh)},
+			want:       fresh,
+			wantExists: true,
+		},
+		{
+			name:       "deleted during scrub",
+			current:    nil,
+			wantExists: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, redundant := newTestEngine(t), newTestEngine(t)
+			putString(t, redundant, "a.txt", old)
+			if tt.primary != "" {
+				putString(t, primary, "a.txt", tt.primary)
+			}
+
+			repo := &scrubRepo{current: tt.current, statuses: map[int64]string{}}
+			scrubber := NewScrubber(primary, redundant, repo, ScrubOptions{})
+			obj := scanned
+			report := &ScrubReport{}
+			if !scrubber.verify(context.Background(), "bucket", &obj, report) {
+				t.Fatalf("verify() = false, errors %v", report.Errors)
+			}
+
+			got, exists := readString(t, primary, "a.txt")
+			if exists != tt.wantExists || got != tt.want {
+				t.Errorf("primary = %q (exists %v), want %q (exists %v)", got, exists, tt.want, tt.wantExists)
+			}
+			if status := repo.statuses[scanned.ID]; status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+		})
+	}
+}
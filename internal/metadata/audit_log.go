@@ -27,6 +27,7 @@ const (
 	ActionUploadObject       = "UPLOAD_OBJECT"
 	ActionDeleteObject       = "DELETE_OBJECT"
 	ActionCompleteMultipart  = "COMPLETE_MULTIPART"
+	ActionAbortMultipart     = "ABORT_MULTIPART"
 	ActionCreateUser         = "CREATE_USER"
 	ActionDeleteUser         = "DELETE_USER"
 	ActionUpdateUser         = "UPDATE_USER"
@@ -34,10 +35,21 @@ const (
 	ActionDeleteCredential   = "DELETE_CREDENTIAL"
 	ActionSetBucketPolicy    = "SET_BUCKET_POLICY"
 	ActionDeleteBucketPolicy = "DELETE_BUCKET_POLICY"
+	ActionSetTagging         = "SET_TAGGING"
+	ActionDeleteTagging      = "DELETE_TAGGING"
+	ActionSetObjectLock      = "SET_OBJECT_LOCK"
 	ActionLogin              = "LOGIN"
 	ActionLogout             = "LOGOUT"
+	ActionAdminOperation     = "ADMIN_OPERATION"
+	ActionEnableReadOnly     = "ENABLE_READ_ONLY"
+	ActionDisableReadOnly    = "DISABLE_READ_ONLY"
+	ActionSetReplication     = "SET_REPLICATION"
+	ActionDeleteReplication  = "DELETE_REPLICATION"
 )
 
+// SystemUsername 后台任务写入审计日志时使用的用户名
+const SystemUsername = "system"
+
 // Resource types
 const (
 	ResourceTypeBucket     = "BUCKET"
@@ -45,6 +57,9 @@ const (
 	ResourceTypeUser       = "USER"
 	ResourceTypeCredential = "CREDENTIAL"
 	ResourceTypePolicy     = "POLICY"
+	ResourceTypeMultipart  = "MULTIPART"
+	ResourceTypeAdmin      = "ADMIN"
+	ResourceTypeCluster    = "CLUSTER"
 )
 
 // AuditLogFilter 日志查询过滤器
@@ -53,6 +68,7 @@ type AuditLogFilter struct {
 	Action       string
 	ResourceType string
 	BucketName   string
+	RequestID    string
 	StartTime    *time.Time
 	EndTime      *time.Time
 	Limit        int
@@ -1,6 +1,16 @@
 package metadata
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ConditionSecureTransport 请求是否通过 TLS 到达
+const ConditionSecureTransport = "aws:SecureTransport"
+
+// ConditionValues 策略条件求值时使用的请求属性，如 aws:SecureTransport
+type ConditionValues map[string]string
 
 // BucketPolicy S3 Bucket 策略结构
 type BucketPolicy struct {
@@ -14,17 +24,20 @@ type PolicyStatement struct {
 	Principal interface{} `json:"Principal"` // "*" for public access
 	Action    interface{} `json:"Action"`    // s3:GetObject, s3:PutObject, etc.
 	Resource  interface{} `json:"Resource"`  // arn:aws:s3:::bucket/*
+	// Condition 如 {"Bool": {"aws:SecureTransport": "false"}}，
+	// 支持 Bool、StringEquals、StringNotEquals 及其 IfExists 形式
+	Condition map[string]map[string]interface{} `json:"Condition,omitempty"`
 }
 
 // IsPublicRead 判断是否为公开读策略
-func (p *BucketPolicy) IsPublicRead() bool {
+func (p *BucketPolicy) IsPublicRead(values ConditionValues) bool {
 	if p == nil || p.Statement == nil {
 		return false
 	}
 
 	for _, stmt := range p.Statement {
 		// 检查是否允许所有人
-		if stmt.Effect != "Allow" {
+		if stmt.Effect != "Allow" || !stmt.conditionsMet(values) {
 			continue
 		}
 
@@ -57,6 +70,164 @@ func (p *BucketPolicy) IsPublicRead() bool {
 	return false
 }
 
+// Allows 判断策略是否向指定主体授予某个操作。
+// Principal 支持 "*"、用户名字符串或 {"AWS": "user"} / {"AWS": ["user", ...]} 形式。
+func (p *BucketPolicy) Allows(principal, action string, values ConditionValues) bool {
+	return p.matches("Allow", principal, action, values)
+}
+
+// Denies 判断策略是否显式拒绝指定主体的某个操作，匿名请求的 principal 为空
+func (p *BucketPolicy) Denies(principal, action string, values ConditionValues) bool {
+	return p.matches("Deny", principal, action, values)
+}
+
+func (p *BucketPolicy) matches(effect, principal, action string, values ConditionValues) bool {
+	if p == nil {
+		return false
+	}
+
+	for _, stmt := range p.Statement {
+		if stmt.Effect != effect {
+			continue
+		}
+		if !matchesAny(stmt.Principal, principal, "AWS") {
+			continue
+		}
+		if !stmt.conditionsMet(values) {
+			continue
+		}
+		for _, a := range toStrings(stmt.Action) {
+			if actionMatches(a, action) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// actionMatches 支持精确匹配、"*"、"s3:*" 以及 "s3:Get*" 形式的前缀通配
+func actionMatches(pattern, action string) bool {
+	if pattern == "*" || pattern == "s3:*" || pattern == action {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(action, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// conditionsMet 判断声明的所有条件是否满足，不支持的条件运算符视为不满足
+func (s *PolicyStatement) conditionsMet(values ConditionValues) bool {
+	for op, conditions := range s.Condition {
+		ifExists := strings.HasSuffix(op, "IfExists")
+		op = strings.TrimSuffix(op, "IfExists")
+
+		for key, expected := range conditions {
+			actual, ok := values.get(key)
+			if !ok {
+				if ifExists {
+					continue
+				}
+				return false
+			}
+
+			wanted := conditionStrings(expected)
+			switch op {
+			case "Bool":
+				if !containsFold(wanted, actual) {
+					return false
+				}
+			case "StringEquals":
+				if !contains(wanted, actual) {
+					return false
+				}
+			case "StringNotEquals":
+				if contains(wanted, actual) {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// get 条件键不区分大小写
+func (v ConditionValues) get(key string) (string, bool) {
+	for k, val := range v {
+		if strings.EqualFold(k, key) {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// conditionStrings 条件值可以是字符串、布尔值或它们的数组
+func conditionStrings(v interface{}) []string {
+	switch val := v.(type) {
+	case []interface{}:
+		result := make([]string, 0, len(val))
+		for _, item := range val {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	case nil:
+		return nil
+	}
+	return []string{fmt.Sprint(v)}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny 判断 Principal 字段是否包含目标主体
+func matchesAny(field interface{}, target, mapKey string) bool {
+	values := toStrings(field)
+	if m, ok := field.(map[string]interface{}); ok {
+		values = toStrings(m[mapKey])
+	}
+	for _, v := range values {
+		if v == "*" || v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// toStrings 将 string 或 []interface{} 转换为字符串列表
+func toStrings(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []interface{}:
+		result := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
 // PublicReadPolicy 创建公开读策略
 func PublicReadPolicy(bucketName string) *BucketPolicy {
 	return &BucketPolicy{
@@ -0,0 +1,86 @@
+package metadata
+
+import "testing"
+
+func TestConditionsMet(t *testing.T) {
+	https := ConditionValues{ConditionSecureTransport: "true"}
+	http := ConditionValues{ConditionSecureTransport: "false"}
+
+	tests := []struct {
+		name      string
+		condition map[string]map[string]interface{}
+		values    ConditionValues
+		want      bool
+	}{
+		{"no condition", nil, http, true},
+		{"bool string match", map[string]map[string]interface{}{"Bool": {"aws:SecureTransport": "false"}}, http, true},
+		{"bool string mismatch", map[string]map[string]interface{}{"Bool": {"aws:SecureTransport": "false"}}, https, false},
+		{"bool literal", map[string]map[string]interface{}{"Bool": {"aws:SecureTransport": false}}, http, true},
+		{"bool case insensitive", map[string]map[string]interface{}{"Bool": {"aws:SecureTransport": "FALSE"}}, http, true},
+		{"key case insensitive", map[string]map[string]interface{}{"Bool": {"AWS:securetransport": "true"}}, https, true},
+		{"string equals", map[string]map[string]interface{}{"StringEquals": {"aws:SecureTransport": "true"}}, https, true},
+		{"string equals array", map[string]map[string]interface{}{"StringEquals": {"aws:SecureTransport": []interface{}{"x", "true"}}}, https, true},
+		{"string equals mismatch", map[string]map[string]interface{}{"StringEquals": {"aws:SecureTransport": "true"}}, http, false},
+		{"string not equals", map[string]map[string]interface{}{"StringNotEquals": {"aws:SecureTransport": "true"}}, http, true},
+		{"string not equals mismatch", map[string]map[string]interface{}{"StringNotEquals": {"aws:SecureTransport": "true"}}, https, false},
+		{"missing key", map[string]map[string]interface{}{"StringEquals": {"aws:SourceIp": "10.0.0.1"}}, https, false},
+		{"missing key if exists", map[string]map[string]interface{}{"StringEqualsIfExists": {"aws:SourceIp": "10.0.0.1"}}, https, true},
+		{"present key if exists", map[string]map[string]interface{}{"BoolIfExists": {"aws:SecureTransport": "false"}}, https, false},
+		{"unsupported operator", map[string]map[string]interface{}{"IpAddress": {"aws:SecureTransport": "true"}}, https, false},
+		{"all conditions required", map[string]map[string]interface{}{
+			"Bool":         {"aws:SecureTransport": "true"},
+			"StringEquals": {"aws:SourceIp": "10.0.0.1"},
+		}, https, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := &PolicyStatement{Condition: tt.condition}
+			if got := stmt.conditionsMet(tt.values); got != tt.want {
+				t.Errorf("conditionsMet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDenies(t *testing.T) {
+	insecure := &BucketPolicy{Statement: []PolicyStatement{{
+		Effect:    "Deny",
+		Principal: "*",
+		Action:    "s3:*",
+		Condition: map[string]map[string]interface{}{"Bool": {"aws:SecureTransport": "false"}},
+	}}}
+	getOnly := &BucketPolicy{Statement: []PolicyStatement{{
+		Effect:    "Deny",
+		Principal: map[string]interface{}{"AWS": []interface{}{"alice"}},
+		Action:    []interface{}{"s3:Get*"},
+	}}}
+	https := ConditionValues{ConditionSecureTransport: "true"}
+	http := ConditionValues{ConditionSecureTransport: "false"}
+
+	tests := []struct {
+		name      string
+		policy    *BucketPolicy
+		principal string
+		action    string
+		values    ConditionValues
+		want      bool
+	}{
+		{"secure transport over http", insecure, "alice", "s3:GetObject", http, true},
+		{"secure transport over https", insecure, "alice", "s3:GetObject", https, false},
+		{"secure transport anonymous", insecure, "", "s3:PutObject", http, true},
+		{"get wildcard object", getOnly, "alice", "s3:GetObject", https, true},
+		{"get wildcard bucket policy", getOnly, "alice", "s3:GetBucketPolicy", https, true},
+		{"get wildcard put", getOnly, "alice", "s3:PutObject", https, false},
+		{"get wildcard other principal", getOnly, "bob", "s3:GetObject", https, false},
+		{"nil policy", nil, "alice", "s3:GetObject", http, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Denies(tt.principal, tt.action, tt.values); got != tt.want {
+				t.Errorf("Denies(%q, %q) = %v, want %v", tt.principal, tt.action, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package metadata
+
+// 对象数据完整性状态
+const (
+	IntegrityUnverified = ""           // 尚未校验
+	IntegrityOK         = "ok"         // 数据与 ETag 一致
+	IntegrityCorrupt    = "corrupt"    // 数据损坏
+	IntegrityMissing    = "missing"    // 数据文件丢失
+	IntegrityRepaired   = "repaired"   // 已从冗余副本修复
+	IntegrityReadError  = "read_error" // 读取失败，无法判断数据是否完好
+)
+
+// IsCorrupt 对象数据是否已被巡检判定为不可用
+func (o *Object) IsCorrupt() bool {
+	return o.IntegrityStatus == IntegrityCorrupt || o.IntegrityStatus == IntegrityMissing
+}
@@ -0,0 +1,171 @@
+package metadata
+
+import (
+	"path"
+	"time"
+)
+
+// 迁移任务状态
+const (
+	MigrationStatusPending   = "pending"
+	MigrationStatusRunning   = "running"
+	MigrationStatusCompleted = "completed"
+	MigrationStatusFailed    = "failed"
+	MigrationStatusCancelled = "cancelled"
+	MigrationStatusScheduled = "scheduled" // 同步任务等待下一轮
+)
+
+// 迁移模式
+const (
+	MigrationModeCopy = "copy" // 复制全部对象
+	MigrationModeSync = "sync" // 只复制新增或变化的对象
+)
+
+// MigrationJob 从外部 S3 服务迁移数据的任务
+type MigrationJob struct {
+	ID             string           `json:"id"`
+	SourceEndpoint string           `json:"source_endpoint"`
+	SourceRegion   string           `json:"source_region"`
+	AccessKey      string           `json:"access_key"`
+	SecretKey      string           `json:"-"`
+	OwnerID        int64            `json:"owner_id"`
+	CreatedBy      string           `json:"created_by"`
+	Mode           string           `json:"mode"`
+	DeleteMissing  bool             `json:"delete_missing"`             // 同步时删除源端已不存在的对象
+	SyncInterval   string           `json:"sync_interval,omitempty"`    // 非空时按间隔重复同步
+	Concurrency    int              `json:"concurrency,omitempty"`      // 0 表示使用配置
+	BytesPerSecond int64            `json:"bytes_per_second,omitempty"` // 0 表示使用配置
+	Filters        MigrationFilters `json:"filters"`
+	DryRun         bool             `json:"dry_run"` // 只统计对象数和字节数，不迁移数据
+	RunCount       int              `json:"run_count"`
+	Status         string           `json:"status"`
+	Error          string           `json:"error,omitempty"`
+	TotalBuckets   int              `json:"total_buckets"`
+	CreatedAt      time.Time        `json:"created_at"`
+	StartedAt      *time.Time       `json:"started_at,omitempty"`
+	FinishedAt     *time.Time       `json:"finished_at,omitempty"`
+	HeartbeatAt    *time.Time       `json:"heartbeat_at,omitempty"`
+	NextRunAt      *time.Time       `json:"next_run_at,omitempty"`
+}
+
+// Finished 任务是否已结束
+func (j *MigrationJob) Finished() bool {
+	switch j.Status {
+	case MigrationStatusCompleted, MigrationStatusFailed, MigrationStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// MigrationFilters 迁移范围和目标 Bucket 名称
+type MigrationFilters struct {
+	IncludeBuckets []string          `json:"include_buckets,omitempty"` // Bucket 名称或通配符（path.Match 语法），为空表示全部
+	ExcludeBuckets []string          `json:"exclude_buckets,omitempty"`
+	Prefixes       map[string]string `json:"prefixes,omitempty"`       // 源 Bucket → 只迁移该前缀下的对象
+	BucketMapping  map[string]string `json:"bucket_mapping,omitempty"` // 源 Bucket → 目标 Bucket，用于避免重名
+}
+
+// Selects 判断源 Bucket 是否在迁移范围内：匹配 IncludeBuckets（为空时全部匹配）且不匹配 ExcludeBuckets
+func (f *MigrationFilters) Selects(bucket string) bool {
+	if len(f.IncludeBuckets) > 0 && !matchAny(f.IncludeBuckets, bucket) {
+		return false
+	}
+	return !matchAny(f.ExcludeBuckets, bucket)
+}
+
+// TargetBucket 返回源 Bucket 对应的目标 Bucket 名称
+func (f *MigrationFilters) TargetBucket(bucket string) string {
+	if target, ok := f.BucketMapping[bucket]; ok && target != "" {
+		return target
+	}
+	return bucket
+}
+
+// Prefix 返回源 Bucket 的前缀过滤，为空表示全部对象
+func (f *MigrationFilters) Prefix(bucket string) string {
+	return f.Prefixes[bucket]
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrationBucket 单个 Bucket 的迁移进度，LastKey 及之前的对象均已处理
+type MigrationBucket struct {
+	JobID            string    `json:"-"`
+	SourceBucket     string    `json:"source_bucket"`
+	TargetBucket     string    `json:"target_bucket"`
+	Status           string    `json:"status"`
+	LastKey          string    `json:"last_key"`
+	ListedObjects    int64     `json:"listed_objects"`
+	ListedBytes      int64     `json:"listed_bytes"`
+	CompletedObjects int64     `json:"completed_objects"`
+	FailedObjects    int64     `json:"failed_objects"`
+	SkippedObjects   int64     `json:"skipped_objects"` // 同步时未变化而跳过
+	DeletedObjects   int64     `json:"deleted_objects"` // 同步时因源端不存在而删除
+	CompletedBytes   int64     `json:"completed_bytes"`
+	Error            string    `json:"error,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	// Settings Bucket 配置的迁移结果，如 {"policy": "copied", "cors": "not supported by target"}
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// MigrationError 迁移失败的对象，ObjectKey 为空表示 Bucket 级错误
+type MigrationError struct {
+	ID           int64     `json:"id"`
+	JobID        string    `json:"-"`
+	SourceBucket string    `json:"source_bucket"`
+	ObjectKey    string    `json:"object_key,omitempty"`
+	Error        string    `json:"error"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// 对象迁移后的校验结果
+const (
+	MigrationChecksumVerified = "verified"  // 写入内容的 MD5 与源端 ETag 一致
+	MigrationChecksumSizeOnly = "size_only" // 源端为分片上传的 ETag，只能校验大小
+)
+
+// MigrationObject 单个对象的迁移报告：校验结果、复制的元数据和标签数量，以及未能保留的属性
+type MigrationObject struct {
+	JobID         string    `json:"-"`
+	SourceBucket  string    `json:"source_bucket"`
+	ObjectKey     string    `json:"object_key"`
+	Size          int64     `json:"size"`
+	SourceETag    string    `json:"source_etag"`
+	TargetETag    string    `json:"target_etag"`
+	Checksum      string    `json:"checksum"`
+	MetadataCount int       `json:"metadata_count"`
+	TagCount      int       `json:"tag_count"`
+	Issues        string    `json:"issues,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// MigrationObjectFilter 迁移报告查询条件，IssuesOnly 只返回有未保留属性的对象
+type MigrationObjectFilter struct {
+	SourceBucket string
+	IssuesOnly   bool
+	Limit        int
+	Offset       int
+}
+
+// MigrationObjectSummary 迁移报告汇总
+type MigrationObjectSummary struct {
+	Total      int64 `json:"total"`
+	Verified   int64 `json:"verified"`
+	SizeOnly   int64 `json:"size_only"`
+	WithIssues int64 `json:"with_issues"`
+}
+
+// MigrationJobFilter 迁移任务查询条件
+type MigrationJobFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,43 @@
+package metadata
+
+import (
+	"fmt"
+	"time"
+)
+
+// Object Lock 保留模式
+const (
+	LockModeGovernance = "GOVERNANCE"
+	LockModeCompliance = "COMPLIANCE"
+)
+
+// IsValidLockMode 判断是否为合法的保留模式
+func IsValidLockMode(mode string) bool {
+	return mode == LockModeGovernance || mode == LockModeCompliance
+}
+
+// IsRetained 判断对象在指定时间是否仍处于保留期内
+func (o *Object) IsRetained(now time.Time) bool {
+	return o.LockMode != "" && o.RetainUntil != nil && now.Before(*o.RetainUntil)
+}
+
+// IsLocked 判断对象是否受保留期或法律保留保护
+func (o *Object) IsLocked(now time.Time) bool {
+	return o.LegalHold || o.IsRetained(now)
+}
+
+// CheckRemovable 检查对象能否被删除或覆盖，bypassGovernance 表示调用方具备绕过 GOVERNANCE 模式的权限。
+// 生命周期等后台任务应以 bypassGovernance=false 调用。
+func (o *Object) CheckRemovable(now time.Time, bypassGovernance bool) error {
+	if o.LegalHold {
+		return fmt.Errorf("object %s is under legal hold", o.Key)
+	}
+	if !o.IsRetained(now) {
+		return nil
+	}
+	if o.LockMode == LockModeGovernance && bypassGovernance {
+		return nil
+	}
+	return fmt.Errorf("object %s is protected by %s retention until %s",
+		o.Key, o.LockMode, o.RetainUntil.UTC().Format(time.RFC3339))
+}
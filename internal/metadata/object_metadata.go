@@ -0,0 +1,62 @@
+package metadata
+
+import (
+	"net/http"
+	"strings"
+)
+
+// UserMetadataPrefix 用户自定义元数据 Header 前缀
+const UserMetadataPrefix = "x-amz-meta-"
+
+// StoredHeaders 随对象保存、下载时原样返回的标准 HTTP Header
+var StoredHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Expires",
+}
+
+// IsObjectMetadataKey 判断元数据键是否为对象的用户元数据或保存的标准 Header。
+// 分片上传的元数据中还保存了标签和 Object Lock 设置，需要用它过滤
+func IsObjectMetadataKey(key string) bool {
+	if strings.HasPrefix(key, UserMetadataPrefix) {
+		return true
+	}
+	for _, name := range StoredHeaders {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MetadataFromHeader 从请求或响应 Header 中提取对象元数据，用户元数据键统一为小写
+func MetadataFromHeader(header http.Header) map[string]string {
+	m := map[string]string{}
+	for name, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, UserMetadataPrefix) {
+			m[lower] = strings.Join(values, ",")
+		}
+	}
+	for _, name := range StoredHeaders {
+		if v := header.Get(name); v != "" {
+			m[name] = v
+		}
+	}
+	return m
+}
+
+// ObjectMetadata 从元数据中筛出对象元数据
+func ObjectMetadata(m map[string]string) map[string]string {
+	result := map[string]string{}
+	for k, v := range m {
+		if IsObjectMetadataKey(k) {
+			result[k] = v
+		}
+	}
+	return result
+}
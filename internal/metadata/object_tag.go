@@ -0,0 +1,49 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Tag 对象或 Bucket 标签
+type Tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// S3 标签限制
+const (
+	MaxObjectTags     = 10
+	MaxBucketTags     = 50
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+// ValidateTags 校验标签集合是否符合 S3 限制
+func ValidateTags(tags []Tag, maxTags int) error {
+	if len(tags) > maxTags {
+		return fmt.Errorf("tag count %d exceeds limit of %d", len(tags), maxTags)
+	}
+
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag.Key == "" {
+			return fmt.Errorf("tag key cannot be empty")
+		}
+		if utf8.RuneCountInString(tag.Key) > MaxTagKeyLength {
+			return fmt.Errorf("tag key %q exceeds %d characters", tag.Key, MaxTagKeyLength)
+		}
+		if utf8.RuneCountInString(tag.Value) > MaxTagValueLength {
+			return fmt.Errorf("tag value for key %q exceeds %d characters", tag.Key, MaxTagValueLength)
+		}
+		if strings.HasPrefix(strings.ToLower(tag.Key), "aws:") {
+			return fmt.Errorf("tag key %q uses reserved prefix aws:", tag.Key)
+		}
+		if seen[tag.Key] {
+			return fmt.Errorf("duplicate tag key %q", tag.Key)
+		}
+		seen[tag.Key] = true
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ func NewPostgresRepository(dsn string) (*PostgresRepository, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse dsn: %w", err)
 	}
+	config.ConnConfig.Tracer = queryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
@@ -208,19 +209,20 @@ func (r *PostgresRepository) DeleteCredential(ctx context.Context, id int64) err
 // ==================== Bucket 操作 ====================
 
 func (r *PostgresRepository) CreateBucket(ctx context.Context, bucket *Bucket) error {
-	query := `INSERT INTO buckets (name, owner_id, region, acl, versioning, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	query := `INSERT INTO buckets (name, owner_id, region, acl, versioning, object_lock_enabled, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
 	return r.conn(ctx).QueryRow(ctx, query,
-		bucket.Name, bucket.OwnerID, bucket.Region, bucket.ACL, bucket.Versioning, time.Now(),
+		bucket.Name, bucket.OwnerID, bucket.Region, bucket.ACL, bucket.Versioning, bucket.ObjectLockEnabled, time.Now(),
 	).Scan(&bucket.ID)
 }
 
 func (r *PostgresRepository) GetBucketByName(ctx context.Context, name string) (*Bucket, error) {
-	query := `SELECT id, name, owner_id, region, acl, versioning, default_expiry, created_at FROM buckets WHERE name = $1`
+	query := `SELECT id, name, owner_id, region, acl, versioning, default_expiry, object_lock_enabled, lock_default_mode, lock_default_days, lock_default_years, created_at FROM buckets WHERE name = $1`
 	bucket := &Bucket{}
 	err := r.conn(ctx).QueryRow(ctx, query, name).Scan(
 		&bucket.ID, &bucket.Name, &bucket.OwnerID, &bucket.Region,
-		&bucket.ACL, &bucket.Versioning, &bucket.DefaultExpiry, &bucket.CreatedAt,
+		&bucket.ACL, &bucket.Versioning, &bucket.DefaultExpiry,
+		&bucket.ObjectLockEnabled, &bucket.DefaultRetentionMode, &bucket.DefaultRetentionDays, &bucket.DefaultRetentionYears, &bucket.CreatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -229,11 +231,12 @@ func (r *PostgresRepository) GetBucketByName(ctx context.Context, name string) (
 }
 
 func (r *PostgresRepository) GetBucketByID(ctx context.Context, id int64) (*Bucket, error) {
-	query := `SELECT id, name, owner_id, region, acl, versioning, default_expiry, created_at FROM buckets WHERE id = $1`
+	query := `SELECT id, name, owner_id, region, acl, versioning, default_expiry, object_lock_enabled, lock_default_mode, lock_default_days, lock_default_years, created_at FROM buckets WHERE id = $1`
 	bucket := &Bucket{}
 	err := r.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&bucket.ID, &bucket.Name, &bucket.OwnerID, &bucket.Region,
-		&bucket.ACL, &bucket.Versioning, &bucket.DefaultExpiry, &bucket.CreatedAt,
+		&bucket.ACL, &bucket.Versioning, &bucket.DefaultExpiry,
+		&bucket.ObjectLockEnabled, &bucket.DefaultRetentionMode, &bucket.DefaultRetentionDays, &bucket.DefaultRetentionYears, &bucket.CreatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -242,7 +245,7 @@ func (r *PostgresRepository) GetBucketByID(ctx context.Context, id int64) (*Buck
 }
 
 func (r *PostgresRepository) ListBuckets(ctx context.Context, ownerID int64) ([]Bucket, error) {
-	query := `SELECT id, name, owner_id, region, acl, versioning, default_expiry, created_at FROM buckets WHERE owner_id = $1 ORDER BY name`
+	query := `SELECT id, name, owner_id, region, acl, versioning, default_expiry, object_lock_enabled, lock_default_mode, lock_default_days, lock_default_years, created_at FROM buckets WHERE owner_id = $1 ORDER BY name`
 	rows, err := r.conn(ctx).Query(ctx, query, ownerID)
 	if err != nil {
 		return nil, err
@@ -253,7 +256,8 @@ func (r *PostgresRepository) ListBuckets(ctx context.Context, ownerID int64) ([]
 	for rows.Next() {
 		var bucket Bucket
 		if err := rows.Scan(&bucket.ID, &bucket.Name, &bucket.OwnerID, &bucket.Region,
-			&bucket.ACL, &bucket.Versioning, &bucket.DefaultExpiry, &bucket.CreatedAt); err != nil {
+			&bucket.ACL, &bucket.Versioning, &bucket.DefaultExpiry,
+			&bucket.ObjectLockEnabled, &bucket.DefaultRetentionMode, &bucket.DefaultRetentionDays, &bucket.DefaultRetentionYears, &bucket.CreatedAt); err != nil {
 			return nil, err
 		}
 		buckets = append(buckets, bucket)
@@ -262,7 +266,7 @@ func (r *PostgresRepository) ListBuckets(ctx context.Context, ownerID int64) ([]
 }
 
 func (r *PostgresRepository) ListAllBuckets(ctx context.Context) ([]Bucket, error) {
-	query := `SELECT id, name, owner_id, region, acl, versioning, default_expiry, created_at FROM buckets ORDER BY name`
+	query := `SELECT id, name, owner_id, region, acl, versioning, default_expiry, object_lock_enabled, lock_default_mode, lock_default_days, lock_default_years, created_at FROM buckets ORDER BY name`
 	rows, err := r.conn(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, err
@@ -273,7 +277,8 @@ func (r *PostgresRepository) ListAllBuckets(ctx context.Context) ([]Bucket, erro
 	for rows.Next() {
 		var bucket Bucket
 		if err := rows.Scan(&bucket.ID, &bucket.Name, &bucket.OwnerID, &bucket.Region,
-			&bucket.ACL, &bucket.Versioning, &bucket.DefaultExpiry, &bucket.CreatedAt); err != nil {
+			&bucket.ACL, &bucket.Versioning, &bucket.DefaultExpiry,
+			&bucket.ObjectLockEnabled, &bucket.DefaultRetentionMode, &bucket.DefaultRetentionDays, &bucket.DefaultRetentionYears, &bucket.CreatedAt); err != nil {
 			return nil, err
 		}
 		buckets = append(buckets, bucket)
@@ -282,8 +287,11 @@ func (r *PostgresRepository) ListAllBuckets(ctx context.Context) ([]Bucket, erro
 }
 
 func (r *PostgresRepository) UpdateBucket(ctx context.Context, bucket *Bucket) error {
-	query := `UPDATE buckets SET acl = $1, versioning = $2, default_expiry = $3 WHERE id = $4`
-	_, err := r.conn(ctx).Exec(ctx, query, bucket.ACL, bucket.Versioning, bucket.DefaultExpiry, bucket.ID)
+	query := `UPDATE buckets SET acl = $1, versioning = $2, default_expiry = $3, lock_default_mode = $4, lock_default_days = $5,
+		lock_default_years = $6
+		WHERE id = $7`
+	_, err := r.conn(ctx).Exec(ctx, query, bucket.ACL, bucket.Versioning, bucket.DefaultExpiry,
+		bucket.DefaultRetentionMode, bucket.DefaultRetentionDays, bucket.DefaultRetentionYears, bucket.ID)
 	return err
 }
 
@@ -296,33 +304,46 @@ func (r *PostgresRepository) DeleteBucket(ctx context.Context, id int64) error {
 
 func (r *PostgresRepository) CreateObject(ctx context.Context, obj *Object) error {
 	metadataJSON, _ := json.Marshal(obj.Metadata)
-	query := `INSERT INTO objects (bucket_id, key, version_id, size, etag, content_type, storage_class, storage_path, metadata, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	query := `INSERT INTO objects (bucket_id, key, version_id, size, etag, content_type, storage_class, storage_path, metadata,
+			lock_mode, lock_retain_until, legal_hold, replication_status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		ON CONFLICT (bucket_id, key, version_id) DO UPDATE SET
 			size = EXCLUDED.size, etag = EXCLUDED.etag, content_type = EXCLUDED.content_type,
-			storage_path = EXCLUDED.storage_path, metadata = EXCLUDED.metadata, updated_at = EXCLUDED.updated_at
+			storage_path = EXCLUDED.storage_path, metadata = EXCLUDED.metadata,
+			lock_mode = EXCLUDED.lock_mode, lock_retain_until = EXCLUDED.lock_retain_until, legal_hold = EXCLUDED.legal_hold,
+			integrity_status = '', verified_at = NULL, replication_status = EXCLUDED.replication_status,
+			updated_at = EXCLUDED.updated_at
 		RETURNING id`
 	now := time.Now()
 	versionID := obj.VersionID
 	if versionID == "" {
 		versionID = "null"
 	}
+	// 调用方设置了 UpdatedAt 时（如从文件导入时保留 mtime）作为对象的最后修改时间
+	modified := now
+	if !obj.UpdatedAt.IsZero() {
+		modified = obj.UpdatedAt
+	}
 	return r.conn(ctx).QueryRow(ctx, query,
 		obj.BucketID, obj.Key, versionID, obj.Size, obj.ETag, obj.ContentType,
-		obj.StorageClass, obj.StoragePath, metadataJSON, now, now,
+		obj.StorageClass, obj.StoragePath, metadataJSON,
+		obj.LockMode, obj.RetainUntil, obj.LegalHold, obj.ReplicationStatus, now, modified,
 	).Scan(&obj.ID)
 }
 
 func (r *PostgresRepository) GetObject(ctx context.Context, bucketID int64, key string) (*Object, error) {
-	query := `SELECT id, bucket_id, key, version_id, size, etag, content_type, storage_class, storage_path, metadata, created_at, updated_at
+	query := `SELECT id, bucket_id, key, version_id, size, etag, content_type, storage_class, storage_path, metadata,
+		lock_mode, lock_retain_until, legal_hold, integrity_status, verified_at, replication_status, created_at, updated_at
 		FROM objects WHERE bucket_id = $1 AND key = $2 AND is_delete_marker = FALSE ORDER BY updated_at DESC LIMIT 1`
 	obj := &Object{}
 	var metadataJSON []byte
 	var versionID sql.NullString
+	var retainUntil, verifiedAt sql.NullTime
 	err := r.conn(ctx).QueryRow(ctx, query, bucketID, key).Scan(
 		&obj.ID, &obj.BucketID, &obj.Key, &versionID, &obj.Size, &obj.ETag,
 		&obj.ContentType, &obj.StorageClass, &obj.StoragePath, &metadataJSON,
-		&obj.CreatedAt, &obj.UpdatedAt,
+		&obj.LockMode, &retainUntil, &obj.LegalHold, &obj.IntegrityStatus, &verifiedAt,
+		&obj.ReplicationStatus, &obj.CreatedAt, &obj.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -333,6 +354,12 @@ func (r *PostgresRepository) GetObject(ctx context.Context, bucketID int64, key
 	if versionID.Valid {
 		obj.VersionID = versionID.String
 	}
+	if retainUntil.Valid {
+		obj.RetainUntil = &retainUntil.Time
+	}
+	if verifiedAt.Valid {
+		obj.VerifiedAt = &verifiedAt.Time
+	}
 	if len(metadataJSON) > 0 {
 		json.Unmarshal(metadataJSON, &obj.Metadata)
 	}
@@ -369,7 +396,8 @@ func (r *PostgresRepository) ListObjects(ctx context.Context, bucketID int64, op
 		argIdx++
 	}
 
-	query := fmt.Sprintf(`SELECT id, bucket_id, key, version_id, size, etag, content_type, storage_class, storage_path, metadata, created_at, updated_at
+	query := fmt.Sprintf(`SELECT id, bucket_id, key, version_id, size, etag, content_type, storage_class, storage_path, metadata,
+		lock_mode, lock_retain_until, legal_hold, created_at, updated_at
 		FROM objects WHERE %s ORDER BY key LIMIT $%d`, strings.Join(conditions, " AND "), argIdx)
 	args = append(args, opts.MaxKeys+1)
 
@@ -386,14 +414,19 @@ func (r *PostgresRepository) ListObjects(ctx context.Context, bucketID int64, op
 		var obj Object
 		var metadataJSON []byte
 		var versionID sql.NullString
+		var retainUntil sql.NullTime
 		if err := rows.Scan(&obj.ID, &obj.BucketID, &obj.Key, &versionID, &obj.Size, &obj.ETag,
 			&obj.ContentType, &obj.StorageClass, &obj.StoragePath, &metadataJSON,
+			&obj.LockMode, &retainUntil, &obj.LegalHold,
 			&obj.CreatedAt, &obj.UpdatedAt); err != nil {
 			return nil, err
 		}
 		if versionID.Valid {
 			obj.VersionID = versionID.String
 		}
+		if retainUntil.Valid {
+			obj.RetainUntil = &retainUntil.Time
+		}
 		if len(metadataJSON) > 0 {
 			json.Unmarshal(metadataJSON, &obj.Metadata)
 		}
@@ -426,7 +459,8 @@ func (r *PostgresRepository) ListObjects(ctx context.Context, bucketID int64, op
 
 func (r *PostgresRepository) UpdateObject(ctx context.Context, obj *Object) error {
 	metadataJSON, _ := json.Marshal(obj.Metadata)
-	query := `UPDATE objects SET size = $1, etag = $2, content_type = $3, storage_path = $4, metadata = $5, updated_at = $6
+	query := `UPDATE objects SET size = $1, etag = $2, content_type = $3, storage_path = $4, metadata = $5, updated_at = $6,
+		integrity_status = '', verified_at = NULL
 		WHERE id = $7`
 	_, err := r.conn(ctx).Exec(ctx, query, obj.Size, obj.ETag, obj.ContentType, obj.StoragePath, metadataJSON, time.Now(), obj.ID)
 	return err
@@ -438,7 +472,10 @@ func (r *PostgresRepository) DeleteObject(ctx context.Context, bucketID int64, k
 }
 
 func (r *PostgresRepository) DeleteObjectsByBucketID(ctx context.Context, bucketID int64) error {
-	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM objects WHERE bucket_id = $1`, bucketID)
+	// 跳过处于 Object Lock 保护下的对象
+	query := `DELETE FROM objects WHERE bucket_id = $1
+		AND legal_hold = FALSE AND (lock_retain_until IS NULL OR lock_retain_until <= NOW())`
+	_, err := r.conn(ctx).Exec(ctx, query, bucketID)
 	return err
 }
 
@@ -481,10 +518,110 @@ func (r *PostgresRepository) GetMultipartUpload(ctx context.Context, uploadID st
 	return upload, err
 }
 
-func (r *PostgresRepository) ListMultipartUploads(ctx context.Context, bucketID int64) ([]MultipartUpload, error) {
+func (r *PostgresRepository) ListMultipartUploads(ctx context.Context, bucketID int64, opts ListMultipartUploadsOptions) (*ListMultipartUploadsResult, error) {
+	if opts.MaxUploads <= 0 {
+		opts.MaxUploads = 1000
+	}
+
+	result := &ListMultipartUploadsResult{}
+
+	// 构建查询
+	var conditions []string
+	var args []interface{}
+	argIdx := 1
+
+	conditions = append(conditions, fmt.Sprintf("bucket_id = $%d", argIdx))
+	args = append(args, bucketID)
+	argIdx++
+
+	conditions = append(conditions, "status = 'in_progress'")
+
+	if opts.Prefix != "" {
+		conditions = append(conditions, fmt.Sprintf("key LIKE $%d", argIdx))
+		args = append(args, opts.Prefix+"%")
+		argIdx++
+	}
+
+	if opts.KeyMarker != "" {
+		if opts.UploadIDMarker != "" {
+			conditions = append(conditions, fmt.Sprintf("(key > $%d OR (key = $%d AND upload_id > $%d))", argIdx, argIdx, argIdx+1))
+			args = append(args, opts.KeyMarker, opts.UploadIDMarker)
+			argIdx += 2
+		} else {
+			conditions = append(conditions, fmt.Sprintf("key > $%d", argIdx))
+			args = append(args, opts.KeyMarker)
+			argIdx++
+		}
+
+		// 上一页以公共前缀结束时，跳过该前缀下的全部上传
+		if opts.Delimiter != "" && strings.HasSuffix(opts.KeyMarker, opts.Delimiter) {
+			conditions = append(conditions, fmt.Sprintf("left(key, length($%d)) <> $%d", argIdx, argIdx))
+			args = append(args, opts.KeyMarker)
+			argIdx++
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT id, upload_id, bucket_id, key, content_type, metadata, status, created_at
+		FROM multipart_uploads WHERE %s ORDER BY key, upload_id LIMIT $%d`, strings.Join(conditions, " AND "), argIdx)
+	args = append(args, opts.MaxUploads+1)
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefixSet := make(map[string]bool)
+	count := 0
+
+	for rows.Next() {
+		var upload MultipartUpload
+		var metadataJSON []byte
+		var contentType sql.NullString
+		if err := rows.Scan(&upload.ID, &upload.UploadID, &upload.BucketID, &upload.Key,
+			&contentType, &metadataJSON, &upload.Status, &upload.CreatedAt); err != nil {
+			return nil, err
+		}
+		if contentType.Valid {
+			upload.ContentType = contentType.String
+		}
+		if len(metadataJSON) > 0 {
+			json.Unmarshal(metadataJSON, &upload.Metadata)
+		}
+
+		count++
+		if count > opts.MaxUploads {
+			result.IsTruncated = true
+			break
+		}
+
+		// 处理 delimiter
+		if opts.Delimiter != "" {
+			keyWithoutPrefix := strings.TrimPrefix(upload.Key, opts.Prefix)
+			if idx := strings.Index(keyWithoutPrefix, opts.Delimiter); idx >= 0 {
+				prefix := opts.Prefix + keyWithoutPrefix[:idx+len(opts.Delimiter)]
+				if !prefixSet[prefix] {
+					prefixSet[prefix] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, prefix)
+				}
+				result.NextKeyMarker = prefix
+				result.NextUploadIDMarker = ""
+				continue
+			}
+		}
+
+		result.Uploads = append(result.Uploads, upload)
+		result.NextKeyMarker = upload.Key
+		result.NextUploadIDMarker = upload.UploadID
+	}
+
+	return result, rows.Err()
+}
+
+func (r *PostgresRepository) ListMultipartUploadsBefore(ctx context.Context, before time.Time) ([]MultipartUpload, error) {
 	query := `SELECT id, upload_id, bucket_id, key, content_type, metadata, status, created_at
-		FROM multipart_uploads WHERE bucket_id = $1 AND status = 'in_progress'`
-	rows, err := r.conn(ctx).Query(ctx, query, bucketID)
+		FROM multipart_uploads WHERE created_at < $1 ORDER BY created_at`
+	rows, err := r.conn(ctx).Query(ctx, query, before)
 	if err != nil {
 		return nil, err
 	}
@@ -507,7 +644,13 @@ func (r *PostgresRepository) ListMultipartUploads(ctx context.Context, bucketID
 		}
 		uploads = append(uploads, upload)
 	}
-	return uploads, nil
+	return uploads, rows.Err()
+}
+
+func (r *PostgresRepository) CountMultipartUploads(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM multipart_uploads`).Scan(&count)
+	return count, err
 }
 
 func (r *PostgresRepository) DeleteMultipartUpload(ctx context.Context, uploadID string) error {
@@ -548,6 +691,27 @@ func (r *PostgresRepository) GetUploadParts(ctx context.Context, uploadID string
 	return parts, nil
 }
 
+func (r *PostgresRepository) ListUploadParts(ctx context.Context, uploadID string, partNumberMarker, maxParts int) ([]UploadPart, error) {
+	query := `SELECT id, upload_id, part_number, size, etag, storage_path, created_at
+		FROM upload_parts WHERE upload_id = $1 AND part_number > $2 ORDER BY part_number LIMIT $3`
+	rows, err := r.conn(ctx).Query(ctx, query, uploadID, partNumberMarker, maxParts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var parts []UploadPart
+	for rows.Next() {
+		var part UploadPart
+		if err := rows.Scan(&part.ID, &part.UploadID, &part.PartNumber, &part.Size,
+			&part.ETag, &part.StoragePath, &part.CreatedAt); err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	return parts, rows.Err()
+}
+
 func (r *PostgresRepository) DeleteUploadParts(ctx context.Context, uploadID string) error {
 	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM upload_parts WHERE upload_id = $1`, uploadID)
 	return err
@@ -563,6 +727,23 @@ func (r *PostgresRepository) BeginTx(ctx context.Context) (Repository, error) {
 	return &PostgresRepository{pool: r.pool, tx: tx}, nil
 }
 
+// inTx 在事务中执行 fn。已处于 BeginTx 开启的事务中时直接复用，由调用方提交
+func (r *PostgresRepository) inTx(ctx context.Context, fn func(tx *PostgresRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&PostgresRepository{pool: r.pool, tx: tx}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 func (r *PostgresRepository) Commit() error {
 	if r.tx != nil {
 		return r.tx.Commit(context.Background())
@@ -577,6 +758,11 @@ func (r *PostgresRepository) Rollback() error {
 	return nil
 }
 
+// PoolStat 返回连接池状态，用于监控指标
+func (r *PostgresRepository) PoolStat() *pgxpool.Stat {
+	return r.pool.Stat()
+}
+
 func (r *PostgresRepository) Close() error {
 	r.pool.Close()
 	return nil
@@ -66,6 +66,12 @@ func (r *PostgresRepository) GetAuditLogs(ctx context.Context, filter *AuditLogF
 		argIndex++
 	}
 
+	if filter.RequestID != "" {
+		query += fmt.Sprintf(" AND metadata->>'request_id' = $%d", argIndex)
+		args = append(args, filter.RequestID)
+		argIndex++
+	}
+
 	if filter.StartTime != nil {
 		query += fmt.Sprintf(" AND created_at >= $%d", argIndex)
 		args = append(args, *filter.StartTime)
@@ -0,0 +1,71 @@
+package metadata
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ListObjectsToVerify 列出需要巡检的对象：从未校验或上次校验早于 verifiedBefore，最久未校验的优先
+func (r *PostgresRepository) ListObjectsToVerify(ctx context.Context, verifiedBefore time.Time, limit int) ([]Object, error) {
+	query := `SELECT id, bucket_id, key, size, etag, integrity_status, verified_at
+		FROM objects
+		WHERE is_delete_marker = FALSE AND (verified_at IS NULL OR verified_at < $1)
+		ORDER BY verified_at NULLS FIRST, id
+		LIMIT $2`
+	rows, err := r.conn(ctx).Query(ctx, query, verifiedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []Object
+	for rows.Next() {
+		var obj Object
+		var verifiedAt sql.NullTime
+		if err := rows.Scan(&obj.ID, &obj.BucketID, &obj.Key, &obj.Size, &obj.ETag,
+			&obj.IntegrityStatus, &verifiedAt); err != nil {
+			return nil, err
+		}
+		if verifiedAt.Valid {
+			obj.VerifiedAt = &verifiedAt.Time
+		}
+		objects = append(objects, obj)
+	}
+	return objects, rows.Err()
+}
+
+// SetObjectIntegrity 记录巡检结果；仅当对象 ETag 未变化时更新，
+// 避免校验期间被覆盖写入的新数据被误标记。返回是否已更新
+func (r *PostgresRepository) SetObjectIntegrity(ctx context.Context, objectID int64, etag, status string, verifiedAt time.Time) (bool, error) {
+	query := `UPDATE objects SET integrity_status = $1, verified_at = $2 WHERE id = $3 AND etag = $4`
+	tag, err := r.conn(ctx).Exec(ctx, query, status, verifiedAt, objectID, etag)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CountObjectsByIntegrity 按完整性状态统计对象数
+func (r *PostgresRepository) CountObjectsByIntegrity(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.conn(ctx).Query(ctx,
+		`SELECT integrity_status, COUNT(*) FROM objects WHERE is_delete_marker = FALSE GROUP BY integrity_status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		if status == IntegrityUnverified {
+			status = "unverified"
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,28 @@
+package metadata
+
+import (
+	"context"
+	"time"
+)
+
+// SetObjectRetention 设置对象保留模式和截止时间，mode 为空表示清除保留
+func (r *PostgresRepository) SetObjectRetention(ctx context.Context, objectID int64, mode string, retainUntil *time.Time) error {
+	query := `UPDATE objects SET lock_mode = $1, lock_retain_until = $2 WHERE id = $3`
+	_, err := r.conn(ctx).Exec(ctx, query, mode, retainUntil, objectID)
+	return err
+}
+
+// SetObjectLegalHold 设置对象法律保留状态
+func (r *PostgresRepository) SetObjectLegalHold(ctx context.Context, objectID int64, legalHold bool) error {
+	_, err := r.conn(ctx).Exec(ctx, `UPDATE objects SET legal_hold = $1 WHERE id = $2`, legalHold, objectID)
+	return err
+}
+
+// CountLockedObjects 统计 Bucket 内仍受 Object Lock 保护的对象数
+func (r *PostgresRepository) CountLockedObjects(ctx context.Context, bucketID int64) (int64, error) {
+	query := `SELECT COUNT(*) FROM objects WHERE bucket_id = $1
+		AND (legal_hold = TRUE OR lock_retain_until > NOW())`
+	var count int64
+	err := r.conn(ctx).QueryRow(ctx, query, bucketID).Scan(&count)
+	return count, err
+}
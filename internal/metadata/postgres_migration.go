@@ -0,0 +1,372 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const migrationJobColumns = `id, source_endpoint, source_region, access_key, secret_key, COALESCE(owner_id, 0), created_by,
+	mode, delete_missing, sync_interval, concurrency, bytes_per_second, filters, dry_run, run_count, status, error, total_buckets,
+	created_at, started_at, finished_at, heartbeat_at, next_run_at`
+
+func scanMigrationJob(row pgx.Row) (*MigrationJob, error) {
+	var j MigrationJob
+	var filtersJSON []byte
+	err := row.Scan(&j.ID, &j.SourceEndpoint, &j.SourceRegion, &j.AccessKey, &j.SecretKey, &j.OwnerID, &j.CreatedBy,
+		&j.Mode, &j.DeleteMissing, &j.SyncInterval, &j.Concurrency, &j.BytesPerSecond, &filtersJSON, &j.DryRun, &j.RunCount, &j.Status, &j.Error, &j.TotalBuckets,
+		&j.CreatedAt, &j.StartedAt, &j.FinishedAt, &j.HeartbeatAt, &j.NextRunAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(filtersJSON) > 0 {
+		if err := json.Unmarshal(filtersJSON, &j.Filters); err != nil {
+			return nil, err
+		}
+	}
+	return &j, nil
+}
+
+// CreateMigrationJob 创建迁移任务
+func (r *PostgresRepository) CreateMigrationJob(ctx context.Context, job *MigrationJob) error {
+	filtersJSON, err := json.Marshal(job.Filters)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO migration_jobs (id, source_endpoint, source_region, access_key, secret_key, owner_id, created_by,
+			mode, delete_missing, sync_interval, concurrency, bytes_per_second, filters, dry_run, status, created_at, heartbeat_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW(), NOW())
+		RETURNING created_at
+	`
+	return r.conn(ctx).QueryRow(ctx, query, job.ID, job.SourceEndpoint, job.SourceRegion, job.AccessKey, job.SecretKey,
+		job.OwnerID, job.CreatedBy, job.Mode, job.DeleteMissing, job.SyncInterval, job.Concurrency, job.BytesPerSecond,
+		filtersJSON, job.DryRun, job.Status).Scan(&job.CreatedAt)
+}
+
+// GetMigrationJob 获取迁移任务，不存在时返回 nil
+func (r *PostgresRepository) GetMigrationJob(ctx context.Context, id string) (*MigrationJob, error) {
+	job, err := scanMigrationJob(r.conn(ctx).QueryRow(ctx, `SELECT `+migrationJobColumns+` FROM migration_jobs WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// ListMigrationJobs 按创建时间倒序列出迁移任务
+func (r *PostgresRepository) ListMigrationJobs(ctx context.Context, filter *MigrationJobFilter) ([]MigrationJob, error) {
+	query := `SELECT ` + migrationJobColumns + ` FROM migration_jobs WHERE 1=1`
+	args := []interface{}{}
+	argIdx := 1
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIdx)
+		args = append(args, filter.Status)
+		argIdx++
+	}
+	query += " ORDER BY created_at DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIdx)
+		args = append(args, filter.Limit)
+		argIdx++
+	}
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIdx)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []MigrationJob{}
+	for rows.Next() {
+		job, err := scanMigrationJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// StartMigrationJob 将任务标记为运行中并刷新心跳，任务已结束时返回 false
+func (r *PostgresRepository) StartMigrationJob(ctx context.Context, id string) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `
+		UPDATE migration_jobs
+		SET status = 'running', error = '', started_at = COALESCE(started_at, NOW()), finished_at = NULL,
+			heartbeat_at = NOW(), next_run_at = NULL
+		WHERE id = $1 AND status IN ('pending', 'running')
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// HeartbeatMigrationJob 刷新运行中任务的心跳，任务已不在运行（如被其他实例取消）时返回 false
+func (r *PostgresRepository) HeartbeatMigrationJob(ctx context.Context, id string) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `UPDATE migration_jobs SET heartbeat_at = NOW() WHERE id = $1 AND status = 'running'`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetMigrationJobTotal 记录源端 Bucket 数量
+func (r *PostgresRepository) SetMigrationJobTotal(ctx context.Context, id string, totalBuckets int) error {
+	_, err := r.conn(ctx).Exec(ctx, `UPDATE migration_jobs SET total_buckets = $2 WHERE id = $1`, id, totalBuckets)
+	return err
+}
+
+// FinishMigrationJob 结束任务（包括等待下一轮的同步任务）。只更新未结束的任务，返回是否更新成功
+func (r *PostgresRepository) FinishMigrationJob(ctx context.Context, id, status, errMsg string) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `
+		UPDATE migration_jobs
+		SET status = $2, error = $3, finished_at = NOW(), next_run_at = NULL,
+			run_count = run_count + CASE WHEN status = 'running' THEN 1 ELSE 0 END
+		WHERE id = $1 AND status IN ('pending', 'running', 'scheduled')
+	`, id, status, errMsg)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ScheduleMigrationJob 结束本轮同步并在 nextRun 开始下一轮，errMsg 记录本轮的错误。任务已不在运行时返回 false
+func (r *PostgresRepository) ScheduleMigrationJob(ctx context.Context, id string, nextRun time.Time, errMsg string) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `
+		UPDATE migration_jobs
+		SET status = 'scheduled', error = $3, finished_at = NOW(), next_run_at = $2, run_count = run_count + 1
+		WHERE id = $1 AND status = 'running'
+	`, id, nextRun, errMsg)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ResumeMigrationJob 将失败或已取消的任务重新置为待执行，返回是否更新成功
+func (r *PostgresRepository) ResumeMigrationJob(ctx context.Context, id string) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `
+		UPDATE migration_jobs SET status = 'pending', error = '', finished_at = NULL
+		WHERE id = $1 AND status IN ('failed', 'cancelled')
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClaimStaleMigrationJobs 接管心跳超时的运行中任务和未开始的任务（实例崩溃或重启后），以及到期的同步任务。
+// 被接管的任务心跳刷新为当前时间，避免多个实例同时接管；到期的同步任务置为待执行并清空上一轮的 Bucket 进度
+func (r *PostgresRepository) ClaimStaleMigrationJobs(ctx context.Context, staleBefore time.Time) ([]MigrationJob, error) {
+	rows, err := r.conn(ctx).Query(ctx, `
+		WITH claimed AS (
+			UPDATE migration_jobs j
+			SET heartbeat_at = NOW(), status = CASE WHEN c.status = 'scheduled' THEN 'pending' ELSE j.status END
+			FROM (
+				SELECT id, status FROM migration_jobs
+				WHERE (status IN ('pending', 'running') AND (heartbeat_at IS NULL OR heartbeat_at < $1))
+					OR (status = 'scheduled' AND next_run_at <= NOW())
+				FOR UPDATE SKIP LOCKED
+			) c
+			WHERE j.id = c.id
+			RETURNING j.*, c.status AS previous_status
+		), reset AS (
+			DELETE FROM migration_buckets
+			WHERE job_id IN (SELECT id FROM claimed WHERE previous_status = 'scheduled')
+		)
+		SELECT `+migrationJobColumns+` FROM claimed`, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []MigrationJob
+	for rows.Next() {
+		job, err := scanMigrationJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// UpsertMigrationBucket 保存 Bucket 迁移进度
+func (r *PostgresRepository) UpsertMigrationBucket(ctx context.Context, b *MigrationBucket) error {
+	settings := b.Settings
+	if settings == nil {
+		settings = map[string]string{}
+	}
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO migration_buckets (job_id, source_bucket, target_bucket, status, last_key, listed_objects, listed_bytes,
+			completed_objects, failed_objects, skipped_objects, deleted_objects, completed_bytes, error, settings, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW())
+		ON CONFLICT (job_id, source_bucket) DO UPDATE SET
+			target_bucket = $3, status = $4, last_key = $5, listed_objects = $6, listed_bytes = $7, completed_objects = $8,
+			failed_objects = $9, skipped_objects = $10, deleted_objects = $11, completed_bytes = $12, error = $13,
+			settings = $14, updated_at = NOW()
+		RETURNING updated_at
+	`
+	return r.conn(ctx).QueryRow(ctx, query, b.JobID, b.SourceBucket, b.TargetBucket, b.Status, b.LastKey, b.ListedObjects,
+		b.ListedBytes, b.CompletedObjects, b.FailedObjects, b.SkippedObjects, b.DeletedObjects, b.CompletedBytes,
+		b.Error, settingsJSON).Scan(&b.UpdatedAt)
+}
+
+// ListMigrationBuckets 列出任务下各 Bucket 的进度
+func (r *PostgresRepository) ListMigrationBuckets(ctx context.Context, jobID string) ([]MigrationBucket, error) {
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT job_id, source_bucket, target_bucket, status, last_key, listed_objects, listed_bytes,
+			completed_objects, failed_objects, skipped_objects, deleted_objects, completed_bytes, error, settings, updated_at
+		FROM migration_buckets WHERE job_id = $1 ORDER BY source_bucket
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []MigrationBucket{}
+	for rows.Next() {
+		var b MigrationBucket
+		var settingsJSON []byte
+		if err := rows.Scan(&b.JobID, &b.SourceBucket, &b.TargetBucket, &b.Status, &b.LastKey, &b.ListedObjects, &b.ListedBytes,
+			&b.CompletedObjects, &b.FailedObjects, &b.SkippedObjects, &b.DeletedObjects, &b.CompletedBytes, &b.Error,
+			&settingsJSON, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal(settingsJSON, &b.Settings)
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// AddMigrationError 记录迁移失败的对象
+func (r *PostgresRepository) AddMigrationError(ctx context.Context, e *MigrationError) error {
+	query := `
+		INSERT INTO migration_errors (job_id, source_bucket, object_key, error, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at
+	`
+	return r.conn(ctx).QueryRow(ctx, query, e.JobID, e.SourceBucket, e.ObjectKey, e.Error).Scan(&e.ID, &e.CreatedAt)
+}
+
+// ListMigrationErrors 按时间倒序列出任务的错误记录
+func (r *PostgresRepository) ListMigrationErrors(ctx context.Context, jobID string, limit, offset int) ([]MigrationError, error) {
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT id, job_id, source_bucket, object_key, error, created_at
+		FROM migration_errors WHERE job_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3
+	`, jobID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	errs := []MigrationError{}
+	for rows.Next() {
+		var e MigrationError
+		if err := rows.Scan(&e.ID, &e.JobID, &e.SourceBucket, &e.ObjectKey, &e.Error, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		errs = append(errs, e)
+	}
+	return errs, rows.Err()
+}
+
+// UpsertMigrationObject 保存对象的迁移报告，同一对象再次迁移时覆盖
+func (r *PostgresRepository) UpsertMigrationObject(ctx context.Context, o *MigrationObject) error {
+	query := `
+		INSERT INTO migration_objects (job_id, source_bucket, object_key, size, source_etag, target_etag, checksum,
+			metadata_count, tag_count, issues, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		ON CONFLICT (job_id, source_bucket, object_key) DO UPDATE SET
+			size = $4, source_etag = $5, target_etag = $6, checksum = $7, metadata_count = $8, tag_count = $9,
+			issues = $10, updated_at = NOW()
+		RETURNING updated_at
+	`
+	return r.conn(ctx).QueryRow(ctx, query, o.JobID, o.SourceBucket, o.ObjectKey, o.Size, o.SourceETag, o.TargetETag,
+		o.Checksum, o.MetadataCount, o.TagCount, o.Issues).Scan(&o.UpdatedAt)
+}
+
+// ListMigrationObjects 按 Bucket 和 Key 列出任务的对象迁移报告
+func (r *PostgresRepository) ListMigrationObjects(ctx context.Context, jobID string, filter *MigrationObjectFilter) ([]MigrationObject, error) {
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT job_id, source_bucket, object_key, size, source_etag, target_etag, checksum, metadata_count, tag_count,
+			issues, updated_at
+		FROM migration_objects
+		WHERE job_id = $1 AND ($2 = '' OR source_bucket = $2) AND (NOT $3 OR issues <> '')
+		ORDER BY source_bucket, object_key LIMIT $4 OFFSET $5
+	`, jobID, filter.SourceBucket, filter.IssuesOnly, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := []MigrationObject{}
+	for rows.Next() {
+		var o MigrationObject
+		if err := rows.Scan(&o.JobID, &o.SourceBucket, &o.ObjectKey, &o.Size, &o.SourceETag, &o.TargetETag, &o.Checksum,
+			&o.MetadataCount, &o.TagCount, &o.Issues, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+		objects = append(objects, o)
+	}
+	return objects, rows.Err()
+}
+
+// SummarizeMigrationObjects 统计任务的对象迁移报告
+func (r *PostgresRepository) SummarizeMigrationObjects(ctx context.Context, jobID string) (*MigrationObjectSummary, error) {
+	var s MigrationObjectSummary
+	err := r.conn(ctx).QueryRow(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE checksum = $2),
+			COUNT(*) FILTER (WHERE checksum = $3),
+			COUNT(*) FILTER (WHERE issues <> '')
+		FROM migration_objects WHERE job_id = $1
+	`, jobID, MigrationChecksumVerified, MigrationChecksumSizeOnly).Scan(&s.Total, &s.Verified, &s.SizeOnly, &s.WithIssues)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListObjectsInKeyRange 按字节序列出 Bucket 中以 prefix 开头且 after < key <= through 的对象（through 为空表示不设上限），
+// 与 S3 列举顺序一致，不受数据库排序规则影响（模式匹配操作符可使用 idx_objects_bucket_prefix 索引）
+func (r *PostgresRepository) ListObjectsInKeyRange(ctx context.Context, bucketID int64, prefix, after, through string, limit int) ([]Object, error) {
+	query := `
+		SELECT id, bucket_id, key, COALESCE(version_id, ''), size, COALESCE(etag, ''), lock_mode, lock_retain_until,
+			legal_hold, integrity_status, created_at, updated_at
+		FROM objects
+		WHERE bucket_id = $1 AND is_delete_marker = FALSE AND key ~>~ $2
+			AND ($3 = '' OR key ~<=~ $3) AND ($5 = '' OR starts_with(key, $5))
+		ORDER BY key USING ~<~, updated_at
+		LIMIT $4
+	`
+	rows, err := r.conn(ctx).Query(ctx, query, bucketID, after, through, limit, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []Object
+	for rows.Next() {
+		var obj Object
+		if err := rows.Scan(&obj.ID, &obj.BucketID, &obj.Key, &obj.VersionID, &obj.Size, &obj.ETag, &obj.LockMode,
+			&obj.RetainUntil, &obj.LegalHold, &obj.IntegrityStatus, &obj.CreatedAt, &obj.UpdatedAt); err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+	return objects, rows.Err()
+}
@@ -0,0 +1,45 @@
+package metadata
+
+import "context"
+
+// ListReadOnlyModes 列出所有已开启的只读开关
+func (r *PostgresRepository) ListReadOnlyModes(ctx context.Context) ([]ReadOnlyMode, error) {
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT scope, bucket_name, reason, enabled_by, created_at
+		FROM read_only_modes ORDER BY scope, bucket_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	modes := []ReadOnlyMode{}
+	for rows.Next() {
+		var m ReadOnlyMode
+		if err := rows.Scan(&m.Scope, &m.BucketName, &m.Reason, &m.EnabledBy, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		modes = append(modes, m)
+	}
+	return modes, rows.Err()
+}
+
+// SetReadOnlyMode 开启只读，已开启时更新原因和操作人
+func (r *PostgresRepository) SetReadOnlyMode(ctx context.Context, mode *ReadOnlyMode) error {
+	query := `
+		INSERT INTO read_only_modes (scope, bucket_name, reason, enabled_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (scope, bucket_name) DO UPDATE SET reason = $3, enabled_by = $4
+		RETURNING created_at
+	`
+	return r.conn(ctx).QueryRow(ctx, query, mode.Scope, mode.BucketName, mode.Reason, mode.EnabledBy).Scan(&mode.CreatedAt)
+}
+
+// DeleteReadOnlyMode 关闭只读，返回此前是否处于开启状态
+func (r *PostgresRepository) DeleteReadOnlyMode(ctx context.Context, scope, bucketName string) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `DELETE FROM read_only_modes WHERE scope = $1 AND bucket_name = $2`, scope, bucketName)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
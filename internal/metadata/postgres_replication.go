@@ -0,0 +1,348 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const replicationTaskColumns = `id, bucket_id, object_key, destination, rule_id, operation, status, version, attempts,
+	last_error, next_attempt_at, created_at, updated_at`
+
+func scanReplicationTask(row pgx.Row) (*ReplicationTask, error) {
+	var t ReplicationTask
+	err := row.Scan(&t.ID, &t.BucketID, &t.Key, &t.Destination, &t.RuleID, &t.Operation, &t.Status, &t.Version, &t.Attempts,
+		&t.LastError, &t.NextAttemptAt, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SetBucketReplication 保存 Bucket 复制配置，覆盖已有配置
+func (r *PostgresRepository) SetBucketReplication(ctx context.Context, bucketID int64, cfg *ReplicationConfiguration) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO bucket_replication (bucket_id, configuration, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (bucket_id) DO UPDATE SET configuration = $2, updated_at = NOW()
+	`
+	_, err = r.conn(ctx).Exec(ctx, query, bucketID, data)
+	return err
+}
+
+// GetBucketReplication 获取 Bucket 复制配置，未配置时返回 nil
+func (r *PostgresRepository) GetBucketReplication(ctx context.Context, bucketID int64) (*ReplicationConfiguration, error) {
+	var data []byte
+	err := r.conn(ctx).QueryRow(ctx, `SELECT configuration FROM bucket_replication WHERE bucket_id = $1`, bucketID).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg ReplicationConfiguration
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// DeleteBucketReplication 删除 Bucket 复制配置，同时丢弃尚未完成的复制任务并清除对象的待复制状态
+func (r *PostgresRepository) DeleteBucketReplication(ctx context.Context, bucketID int64) error {
+	query := `
+		WITH dropped AS (
+			DELETE FROM replication_queue WHERE bucket_id = $1
+		), objects_reset AS (
+			UPDATE objects SET replication_status = ''
+			WHERE bucket_id = $1 AND replication_status IN ('PENDING', 'FAILED')
+		)
+		DELETE FROM bucket_replication WHERE bucket_id = $1
+	`
+	_, err := r.conn(ctx).Exec(ctx, query, bucketID)
+	return err
+}
+
+// CreateReplicationTarget 创建远端复制目标
+func (r *PostgresRepository) CreateReplicationTarget(ctx context.Context, target *ReplicationTarget) error {
+	query := `
+		INSERT INTO replication_targets (id, endpoint, region, access_key, secret_key, bucket, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`
+	target.ARN = ReplicationTargetARN(target.ID)
+	return r.conn(ctx).QueryRow(ctx, query, target.ID, target.Endpoint, target.Region, target.AccessKey, target.SecretKey,
+		target.Bucket, target.CreatedBy).Scan(&target.CreatedAt)
+}
+
+// GetReplicationTarget 获取远端复制目标，不存在时返回 nil
+func (r *PostgresRepository) GetReplicationTarget(ctx context.Context, id string) (*ReplicationTarget, error) {
+	var t ReplicationTarget
+	err := r.conn(ctx).QueryRow(ctx, `
+		SELECT id, endpoint, region, access_key, secret_key, bucket, created_by, created_at
+		FROM replication_targets WHERE id = $1
+	`, id).Scan(&t.ID, &t.Endpoint, &t.Region, &t.AccessKey, &t.SecretKey, &t.Bucket, &t.CreatedBy, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.ARN = ReplicationTargetARN(t.ID)
+	return &t, nil
+}
+
+// ListReplicationTargets 按创建时间列出远端复制目标
+func (r *PostgresRepository) ListReplicationTargets(ctx context.Context) ([]ReplicationTarget, error) {
+	rows, err := r.conn(ctx).Query(ctx, `
+		SELECT id, endpoint, region, access_key, secret_key, bucket, created_by, created_at
+		FROM replication_targets ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := []ReplicationTarget{}
+	for rows.Next() {
+		var t ReplicationTarget
+		if err := rows.Scan(&t.ID, &t.Endpoint, &t.Region, &t.AccessKey, &t.SecretKey, &t.Bucket, &t.CreatedBy, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		t.ARN = ReplicationTargetARN(t.ID)
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// DeleteReplicationTarget 删除远端复制目标，返回是否存在
+func (r *PostgresRepository) DeleteReplicationTarget(ctx context.Context, id string) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `DELETE FROM replication_targets WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReplicationTargetInUse 判断是否有 Bucket 的复制规则指向该目标 ARN
+func (r *PostgresRepository) ReplicationTargetInUse(ctx context.Context, arn string) (bool, error) {
+	var inUse bool
+	err := r.conn(ctx).QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM bucket_replication b, jsonb_array_elements(b.configuration->'rules') elem
+			WHERE elem->>'destination' = $1
+		)
+	`, arn).Scan(&inUse)
+	return inUse, err
+}
+
+// EnqueueReplication 将对象加入复制队列。同一对象和目标已有记录时改为新的操作并重置重试次数；
+// 正在处理中的记录保留租约，由处理方在结束时发现版本变化后重新放回队列，避免同一对象被并发复制
+func (r *PostgresRepository) EnqueueReplication(ctx context.Context, tasks []ReplicationTask) error {
+	query := `
+		INSERT INTO replication_queue (bucket_id, object_key, destination, rule_id, operation, status,
+			next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 'pending', NOW(), NOW(), NOW())
+		ON CONFLICT (bucket_id, object_key, destination) DO UPDATE SET
+			rule_id = $4, operation = $5, version = replication_queue.version + 1, attempts = 0, last_error = '',
+			status = CASE WHEN replication_queue.status = 'running' AND replication_queue.next_attempt_at > NOW()
+				THEN 'running' ELSE 'pending' END,
+			next_attempt_at = CASE WHEN replication_queue.status = 'running' AND replication_queue.next_attempt_at > NOW()
+				THEN replication_queue.next_attempt_at ELSE NOW() END,
+			updated_at = NOW()
+	`
+	for _, t := range tasks {
+		if _, err := r.conn(ctx).Exec(ctx, query, t.BucketID, t.Key, t.Destination, t.RuleID, t.Operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClaimReplicationTasks 领取到期的复制任务（包括租约已过期的处理中任务，即处理实例崩溃的情况），
+// 领取后状态为 running，租约期间其他实例不会再领取
+func (r *PostgresRepository) ClaimReplicationTasks(ctx context.Context, limit int, lease time.Duration) ([]ReplicationTask, error) {
+	rows, err := r.conn(ctx).Query(ctx, `
+		UPDATE replication_queue q
+		SET status = 'running', next_attempt_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+		FROM (
+			SELECT id AS due_id FROM replication_queue
+			WHERE status IN ('pending', 'running') AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE q.id = due.due_id
+		RETURNING `+replicationTaskColumns, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []ReplicationTask
+	for rows.Next() {
+		task, err := scanReplicationTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+// CompleteReplicationTask 复制成功后删除任务。处理期间对象被再次写入（版本已变化）时保留任务并放回队列，
+// 返回任务是否已删除
+func (r *PostgresRepository) CompleteReplicationTask(ctx context.Context, id, version int64) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `DELETE FROM replication_queue WHERE id = $1 AND version = $2`, id, version)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() > 0 {
+		return true, nil
+	}
+	_, err = r.conn(ctx).Exec(ctx, `
+		UPDATE replication_queue SET status = 'pending', next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`, id)
+	return false, err
+}
+
+// RetryReplicationTask 记录复制失败和累计失败次数，status 为 pending 时在 nextAttempt 重试，为 failed 时不再重试。
+// 处理期间版本已变化时不记录失败，直接放回队列。返回失败是否已记录
+func (r *PostgresRepository) RetryReplicationTask(ctx context.Context, id, version int64, attempts int, status, errMsg string, nextAttempt time.Time) (bool, error) {
+	var recorded bool
+	err := r.conn(ctx).QueryRow(ctx, `
+		UPDATE replication_queue SET
+			attempts = CASE WHEN version = $2 THEN $6 ELSE attempts END,
+			status = CASE WHEN version = $2 THEN $3 ELSE 'pending' END,
+			last_error = CASE WHEN version = $2 THEN $4 ELSE last_error END,
+			next_attempt_at = CASE WHEN version = $2 THEN $5 ELSE NOW() END,
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'running'
+		RETURNING version = $2
+	`, id, version, status, errMsg, nextAttempt, attempts).Scan(&recorded)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return recorded, err
+}
+
+// ListReplicationTasks 列出复制队列中的任务，最早需要处理的在前
+func (r *PostgresRepository) ListReplicationTasks(ctx context.Context, filter *ReplicationTaskFilter) ([]ReplicationTask, error) {
+	query := `SELECT ` + replicationTaskColumns + ` FROM replication_queue WHERE 1=1`
+	args := []interface{}{}
+	argIdx := 1
+
+	if filter.BucketID > 0 {
+		query += fmt.Sprintf(" AND bucket_id = $%d", argIdx)
+		args = append(args, filter.BucketID)
+		argIdx++
+	}
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIdx)
+		args = append(args, filter.Status)
+		argIdx++
+	}
+	query += " ORDER BY next_attempt_at, id"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIdx)
+		args = append(args, filter.Limit)
+		argIdx++
+	}
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIdx)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []ReplicationTask{}
+	for rows.Next() {
+		task, err := scanReplicationTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+// CountReplicationTasks 按状态统计复制队列中的任务数
+func (r *PostgresRepository) CountReplicationTasks(ctx context.Context) (map[string]int64, error) {
+	return r.countByStatus(ctx, `SELECT status, COUNT(*) FROM replication_queue GROUP BY status`)
+}
+
+// RequeueFailedReplication 将重试次数用尽的任务重新放回队列，bucketID 为 0 时处理全部 Bucket。返回任务数
+func (r *PostgresRepository) RequeueFailedReplication(ctx context.Context, bucketID int64) (int64, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `
+		WITH requeued AS (
+			UPDATE replication_queue
+			SET status = 'pending', attempts = 0, last_error = '', next_attempt_at = NOW(), updated_at = NOW()
+			WHERE status = 'failed' AND ($1 = 0 OR bucket_id = $1)
+			RETURNING bucket_id, object_key, operation
+		), objects_reset AS (
+			UPDATE objects o SET replication_status = 'PENDING'
+			FROM requeued q
+			WHERE o.bucket_id = q.bucket_id AND o.key = q.object_key AND q.operation = 'put'
+				AND o.replication_status = 'FAILED'
+		)
+		SELECT 1 FROM requeued
+	`, bucketID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// SetObjectReplicationStatus 更新对象复制状态，仅当对象 ETag 未变化时更新；
+// COMPLETED 和空状态只在该对象已没有其他未完成的复制任务时写入。返回是否已更新
+func (r *PostgresRepository) SetObjectReplicationStatus(ctx context.Context, bucketID int64, key, etag, status string) (bool, error) {
+	tag, err := r.conn(ctx).Exec(ctx, `
+		UPDATE objects SET replication_status = $4
+		WHERE bucket_id = $1 AND key = $2 AND etag = $3 AND replication_status <> 'REPLICA'
+			AND ($4 NOT IN ('COMPLETED', '') OR NOT EXISTS (
+				SELECT 1 FROM replication_queue q WHERE q.bucket_id = $1 AND q.object_key = $2
+			))
+	`, bucketID, key, etag, status)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CountObjectsByReplicationStatus 按复制状态统计对象数，不统计无需复制的对象
+func (r *PostgresRepository) CountObjectsByReplicationStatus(ctx context.Context) (map[string]int64, error) {
+	return r.countByStatus(ctx, `SELECT replication_status, COUNT(*) FROM objects
+		WHERE is_delete_marker = FALSE AND replication_status <> '' GROUP BY replication_status`)
+}
+
+// countByStatus 执行返回 (状态, 数量) 的分组查询
+func (r *PostgresRepository) countByStatus(ctx context.Context, query string) (map[string]int64, error) {
+	rows, err := r.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,120 @@
+package metadata
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+// SetObjectTags 替换对象的全部标签
+func (r *PostgresRepository) SetObjectTags(ctx context.Context, objectID int64, tags []Tag) error {
+	return r.replaceTags(ctx, "object_tags", "object_id", objectID, tags)
+}
+
+// GetObjectTags 获取对象标签
+func (r *PostgresRepository) GetObjectTags(ctx context.Context, objectID int64) ([]Tag, error) {
+	query := `SELECT tag_key, tag_value FROM object_tags WHERE object_id = $1 ORDER BY tag_key`
+	return r.queryTags(ctx, query, objectID)
+}
+
+// DeleteObjectTags 删除对象的全部标签
+func (r *PostgresRepository) DeleteObjectTags(ctx context.Context, objectID int64) error {
+	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM object_tags WHERE object_id = $1`, objectID)
+	return err
+}
+
+// ListObjectsByTag 按标签查找 Bucket 内的对象
+func (r *PostgresRepository) ListObjectsByTag(ctx context.Context, bucketID int64, tagKey, tagValue string) ([]Object, error) {
+	query := `SELECT o.id, o.bucket_id, o.key, o.version_id, o.size, o.etag, o.content_type, o.storage_class, o.storage_path, o.metadata,
+		o.lock_mode, o.lock_retain_until, o.legal_hold, o.created_at, o.updated_at
+		FROM objects o JOIN object_tags t ON t.object_id = o.id
+		WHERE o.bucket_id = $1 AND o.is_delete_marker = FALSE AND t.tag_key = $2 AND t.tag_value = $3
+		ORDER BY o.key`
+	rows, err := r.conn(ctx).Query(ctx, query, bucketID, tagKey, tagValue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []Object
+	for rows.Next() {
+		var obj Object
+		var metadataJSON []byte
+		var versionID sql.NullString
+		var retainUntil sql.NullTime
+		if err := rows.Scan(&obj.ID, &obj.BucketID, &obj.Key, &versionID, &obj.Size, &obj.ETag,
+			&obj.ContentType, &obj.StorageClass, &obj.StoragePath, &metadataJSON,
+			&obj.LockMode, &retainUntil, &obj.LegalHold,
+			&obj.CreatedAt, &obj.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if versionID.Valid {
+			obj.VersionID = versionID.String
+		}
+		if retainUntil.Valid {
+			obj.RetainUntil = &retainUntil.Time
+		}
+		if len(metadataJSON) > 0 {
+			json.Unmarshal(metadataJSON, &obj.Metadata)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, rows.Err()
+}
+
+// SetBucketTags 替换 Bucket 的全部标签
+func (r *PostgresRepository) SetBucketTags(ctx context.Context, bucketID int64, tags []Tag) error {
+	return r.replaceTags(ctx, "bucket_tags", "bucket_id", bucketID, tags)
+}
+
+// GetBucketTags 获取 Bucket 标签
+func (r *PostgresRepository) GetBucketTags(ctx context.Context, bucketID int64) ([]Tag, error) {
+	query := `SELECT tag_key, tag_value FROM bucket_tags WHERE bucket_id = $1 ORDER BY tag_key`
+	return r.queryTags(ctx, query, bucketID)
+}
+
+// DeleteBucketTags 删除 Bucket 的全部标签
+func (r *PostgresRepository) DeleteBucketTags(ctx context.Context, bucketID int64) error {
+	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM bucket_tags WHERE bucket_id = $1`, bucketID)
+	return err
+}
+
+// replaceTags 在一个事务中删除旧标签并批量写入新标签，失败时保留原有标签
+func (r *PostgresRepository) replaceTags(ctx context.Context, table, idColumn string, id int64, tags []Tag) error {
+	keys := make([]string, len(tags))
+	values := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i], values[i] = tag.Key, tag.Value
+	}
+
+	return r.inTx(ctx, func(tx *PostgresRepository) error {
+		if _, err := tx.conn(ctx).Exec(ctx, `DELETE FROM `+table+` WHERE `+idColumn+` = $1`, id); err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		query := `INSERT INTO ` + table + ` (` + idColumn + `, tag_key, tag_value)
+			SELECT $1, k, v FROM unnest($2::text[], $3::text[]) AS t(k, v)`
+		_, err := tx.conn(ctx).Exec(ctx, query, id, keys, values)
+		return err
+	})
+}
+
+func (r *PostgresRepository) queryTags(ctx context.Context, query string, id int64) ([]Tag, error) {
+	rows, err := r.conn(ctx).Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.Key, &tag.Value); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
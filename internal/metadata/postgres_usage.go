@@ -0,0 +1,207 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// usageDay 用量按 UTC 日期汇总
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// AddUsageCounters 将请求数和流量累加到按天汇总表
+func (r *PostgresRepository) AddUsageCounters(ctx context.Context, counters []UsageCounter) error {
+	if len(counters) == 0 {
+		return nil
+	}
+
+	n := len(counters)
+	days := make([]string, n)
+	owners := make([]int64, n)
+	buckets := make([]string, n)
+	classA := make([]int64, n)
+	classB := make([]int64, n)
+	other := make([]int64, n)
+	ingress := make([]int64, n)
+	egress := make([]int64, n)
+	for i, c := range counters {
+		days[i] = usageDay(c.Day)
+		owners[i] = c.OwnerID
+		buckets[i] = c.BucketName
+		classA[i] = c.ClassARequests
+		classB[i] = c.ClassBRequests
+		other[i] = c.OtherRequests
+		ingress[i] = c.IngressBytes
+		egress[i] = c.EgressBytes
+	}
+
+	query := `
+		INSERT INTO usage_daily (day, owner_id, bucket_name, class_a_requests, class_b_requests,
+		                         other_requests, ingress_bytes, egress_bytes, updated_at)
+		SELECT d, o, b, a, cb, ot, i, e, NOW()
+		FROM unnest($1::date[], $2::bigint[], $3::text[], $4::bigint[], $5::bigint[],
+		            $6::bigint[], $7::bigint[], $8::bigint[]) AS t(d, o, b, a, cb, ot, i, e)
+		ON CONFLICT (day, owner_id, bucket_name) DO UPDATE SET
+			class_a_requests = usage_daily.class_a_requests + EXCLUDED.class_a_requests,
+			class_b_requests = usage_daily.class_b_requests + EXCLUDED.class_b_requests,
+			other_requests = usage_daily.other_requests + EXCLUDED.other_requests,
+			ingress_bytes = usage_daily.ingress_bytes + EXCLUDED.ingress_bytes,
+			egress_bytes = usage_daily.egress_bytes + EXCLUDED.egress_bytes,
+			updated_at = NOW()
+	`
+	_, err := r.conn(ctx).Exec(ctx, query, days, owners, buckets, classA, classB, other, ingress, egress)
+	return err
+}
+
+// SampleStorageUsage 按各 Bucket 当前用量累加自上次采样以来的存储字节小时数。
+// 采样进度保存在数据库中并加行锁，多个实例同时采样时每段时间只累计一次；
+// 距上次采样不足 minInterval 时不做处理。跨越 UTC 零点的采样区间按天拆分；
+// 停机等原因导致间隔超过 maxGap 时只按当前用量累计最近的 maxGap，更早的时间无法确定用量。
+// 返回本次累计的小时数
+func (r *PostgresRepository) SampleStorageUsage(ctx context.Context, now time.Time, minInterval, maxGap time.Duration) (float64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var last time.Time
+	err = tx.QueryRow(ctx, `SELECT sampled_at FROM usage_sampler_state WHERE id = 1 FOR UPDATE`).Scan(&last)
+	if err == pgx.ErrNoRows {
+		// 首次采样只记录起点
+		if _, err := tx.Exec(ctx, `INSERT INTO usage_sampler_state (id, sampled_at) VALUES (1, $1) ON CONFLICT (id) DO NOTHING`, now); err != nil {
+			return 0, err
+		}
+		return 0, tx.Commit(ctx)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	elapsed := now.Sub(last)
+	if elapsed < minInterval || elapsed <= 0 {
+		return 0, nil
+	}
+	from := last
+	if maxGap > 0 && elapsed > maxGap {
+		from = now.Add(-maxGap)
+	}
+
+	query := `
+		INSERT INTO usage_daily (day, owner_id, bucket_name, storage_byte_hours, updated_at)
+		SELECT $1::date, COALESCE(b.owner_id, 0), b.name, (u.bytes * $2::float8)::BIGINT, NOW()
+		FROM bucket_usage u
+		JOIN buckets b ON b.id = u.bucket_id
+		WHERE u.bytes > 0
+		ON CONFLICT (day, owner_id, bucket_name) DO UPDATE SET
+			storage_byte_hours = usage_daily.storage_byte_hours + EXCLUDED.storage_byte_hours,
+			updated_at = NOW()
+	`
+	var hours float64
+	for _, span := range splitByDay(from, now) {
+		if _, err := tx.Exec(ctx, query, span.day, span.hours); err != nil {
+			return 0, err
+		}
+		hours += span.hours
+	}
+	if _, err := tx.Exec(ctx, `UPDATE usage_sampler_state SET sampled_at = $1 WHERE id = 1`, now); err != nil {
+		return 0, err
+	}
+	return hours, tx.Commit(ctx)
+}
+
+// daySpan 采样区间落在某个 UTC 日期内的部分
+type daySpan struct {
+	day   string
+	hours float64
+}
+
+// splitByDay 将 [from, to) 按 UTC 零点拆分
+func splitByDay(from, to time.Time) []daySpan {
+	var spans []daySpan
+	for from.Before(to) {
+		start := from.UTC()
+		end := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, time.UTC)
+		if end.After(to) {
+			end = to
+		}
+		spans = append(spans, daySpan{day: usageDay(start), hours: end.Sub(start).Hours()})
+		from = end
+	}
+	return spans
+}
+
+// GetUsageReport 查询用量报表
+func (r *PostgresRepository) GetUsageReport(ctx context.Context, filter *UsageReportFilter) ([]UsageReportRow, error) {
+	var columns []string
+	switch filter.GroupBy {
+	case "day":
+		columns = []string{"d.day", "d.owner_id", "d.bucket_name"}
+	case "user":
+		columns = []string{"d.owner_id"}
+	case "", "bucket":
+		columns = []string{"d.owner_id", "d.bucket_name"}
+	default:
+		return nil, fmt.Errorf("unsupported group_by: %s", filter.GroupBy)
+	}
+	group := strings.Join(columns, ", ")
+
+	query := `
+		SELECT ` + group + `, COALESCE(u.username, ''),
+		       SUM(d.storage_byte_hours)::BIGINT, SUM(d.class_a_requests)::BIGINT, SUM(d.class_b_requests)::BIGINT,
+		       SUM(d.other_requests)::BIGINT, SUM(d.ingress_bytes)::BIGINT, SUM(d.egress_bytes)::BIGINT
+		FROM usage_daily d
+		LEFT JOIN users u ON u.id = d.owner_id
+		WHERE d.day >= $1::date AND d.day < $2::date
+	`
+	args := []interface{}{usageDay(filter.From), usageDay(filter.To)}
+	argIndex := 3
+
+	if filter.OwnerID != nil {
+		query += fmt.Sprintf(" AND d.owner_id = $%d", argIndex)
+		args = append(args, *filter.OwnerID)
+		argIndex++
+	}
+	if filter.BucketName != "" {
+		query += fmt.Sprintf(" AND d.bucket_name = $%d", argIndex)
+		args = append(args, filter.BucketName)
+		argIndex++
+	}
+	query += " GROUP BY " + group + ", u.username ORDER BY " + group
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []UsageReportRow{}
+	for rows.Next() {
+		var row UsageReportRow
+		var day time.Time
+		dest := []interface{}{}
+		switch filter.GroupBy {
+		case "day":
+			dest = append(dest, &day, &row.OwnerID, &row.BucketName)
+		case "user":
+			dest = append(dest, &row.OwnerID)
+		default:
+			dest = append(dest, &row.OwnerID, &row.BucketName)
+		}
+		dest = append(dest, &row.Username, &row.StorageByteHours, &row.ClassARequests, &row.ClassBRequests,
+			&row.OtherRequests, &row.IngressBytes, &row.EgressBytes)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if filter.GroupBy == "day" {
+			row.Day = &day
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
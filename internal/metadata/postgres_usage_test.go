@@ -0,0 +1,38 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitByDay(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []daySpan
+	}{
+		{"same day", "2024-03-01T10:00:00Z", "2024-03-01T11:30:00Z", []daySpan{{"2024-03-01", 1.5}}},
+		{"crosses midnight", "2024-03-01T23:30:00Z", "2024-03-02T00:15:00Z", []daySpan{{"2024-03-01", 0.5}, {"2024-03-02", 0.25}}},
+		{"ends at midnight", "2024-03-01T22:00:00Z", "2024-03-02T00:00:00Z", []daySpan{{"2024-03-01", 2}}},
+		{"several days", "2024-02-28T12:00:00Z", "2024-03-01T06:00:00Z", []daySpan{{"2024-02-28", 12}, {"2024-02-29", 24}, {"2024-03-01", 6}}},
+		{"non-UTC offset", "2024-03-02T07:30:00+08:00", "2024-03-02T08:30:00+08:00", []daySpan{{"2024-03-01", 0.5}, {"2024-03-02", 0.5}}},
+		{"empty", "2024-03-01T10:00:00Z", "2024-03-01T10:00:00Z", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitByDay(at(tt.from), at(tt.to)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitByDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package metadata
+
+import "fmt"
+
+// 配额作用范围
+const (
+	QuotaScopeBucket = "bucket"
+	QuotaScopeUser   = "user"
+)
+
+// Quota 存储配额，各项为 0 表示不限制。
+// 超过硬限制的写入被拒绝，超过软限制只告警
+type Quota struct {
+	MaxBytes    int64 `json:"max_bytes"`
+	SoftBytes   int64 `json:"soft_bytes"`
+	MaxObjects  int64 `json:"max_objects"`
+	SoftObjects int64 `json:"soft_objects"`
+}
+
+// Usage 存储用量
+type Usage struct {
+	Bytes   int64 `json:"bytes"`
+	Objects int64 `json:"objects"`
+}
+
+// Validate 检查配额设置是否合法
+func (q *Quota) Validate() error {
+	if q.MaxBytes < 0 || q.SoftBytes < 0 || q.MaxObjects < 0 || q.SoftObjects < 0 {
+		return fmt.Errorf("quota values must not be negative")
+	}
+	if q.MaxBytes > 0 && q.SoftBytes > q.MaxBytes {
+		return fmt.Errorf("soft_bytes must not exceed max_bytes")
+	}
+	if q.MaxObjects > 0 && q.SoftObjects > q.MaxObjects {
+		return fmt.Errorf("soft_objects must not exceed max_objects")
+	}
+	return nil
+}
+
+// Check 检查在当前用量上增加 addBytes 字节、addObjects 个对象后是否超出配额。
+// 返回超出的硬限制说明（为空表示允许写入）和超出的软限制说明
+func (q *Quota) Check(usage Usage, addBytes, addObjects int64) (hard, soft string) {
+	bytes := usage.Bytes + addBytes
+	objects := usage.Objects + addObjects
+
+	// 只拦截会增加用量的写入，已超限时仍允许覆盖为更小的对象
+	switch {
+	case q.MaxBytes > 0 && addBytes > 0 && bytes > q.MaxBytes:
+		return fmt.Sprintf("storage quota of %d bytes exceeded", q.MaxBytes), ""
+	case q.MaxObjects > 0 && addObjects > 0 && objects > q.MaxObjects:
+		return fmt.Sprintf("object quota of %d objects exceeded", q.MaxObjects), ""
+	}
+
+	switch {
+	case q.SoftBytes > 0 && bytes > q.SoftBytes:
+		soft = fmt.Sprintf("storage soft quota of %d bytes exceeded", q.SoftBytes)
+	case q.SoftObjects > 0 && objects > q.SoftObjects:
+		soft = fmt.Sprintf("object soft quota of %d objects exceeded", q.SoftObjects)
+	}
+	return "", soft
+}
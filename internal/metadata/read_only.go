@@ -0,0 +1,18 @@
+package metadata
+
+import "time"
+
+// 只读模式范围
+const (
+	ReadOnlyScopeCluster = "cluster"
+	ReadOnlyScopeBucket  = "bucket"
+)
+
+// ReadOnlyMode 集群或 Bucket 的只读开关，开启期间拒绝所有修改操作
+type ReadOnlyMode struct {
+	Scope      string    `json:"scope"`
+	BucketName string    `json:"bucket_name,omitempty"` // 集群级为空
+	Reason     string    `json:"reason,omitempty"`
+	EnabledBy  string    `json:"enabled_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
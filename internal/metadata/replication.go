@@ -0,0 +1,214 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 对象复制状态，通过 x-amz-replication-status 返回
+const (
+	ReplicationStatusNone      = ""          // 不匹配任何复制规则
+	ReplicationStatusPending   = "PENDING"   // 等待复制
+	ReplicationStatusCompleted = "COMPLETED" // 已复制到所有目标
+	ReplicationStatusFailed    = "FAILED"    // 重试次数用尽
+	ReplicationStatusReplica   = "REPLICA"   // 由其他 Bucket 复制写入的副本
+)
+
+// 复制队列中的操作和状态
+const (
+	ReplicationOpPut    = "put"
+	ReplicationOpDelete = "delete"
+
+	ReplicationTaskPending = "pending"
+	ReplicationTaskRunning = "running" // 已被某个实例领取，next_attempt_at 为租约到期时间
+	ReplicationTaskFailed  = "failed"  // 重试次数用尽，需要手动重新入队
+)
+
+// 复制目标 ARN：本地 Bucket 使用 S3 Bucket ARN，远端目标使用管理 API 创建的目标 ID
+const (
+	LocalBucketARNPrefix       = "arn:aws:s3:::"
+	ReplicationTargetARNPrefix = "arn:gooss:replication:::"
+)
+
+// MaxReplicationRules 每个 Bucket 的复制规则数上限
+const MaxReplicationRules = 1000
+
+// ReplicationConfiguration Bucket 复制配置
+type ReplicationConfiguration struct {
+	Role  string            `json:"role,omitempty"`
+	Rules []ReplicationRule `json:"rules"`
+}
+
+// ReplicationRule 复制规则。Prefix 和 Tags 同时设置时要求全部匹配
+type ReplicationRule struct {
+	ID                      string `json:"id"`
+	Priority                int    `json:"priority"`
+	Enabled                 bool   `json:"enabled"`
+	Prefix                  string `json:"prefix,omitempty"`
+	Tags                    []Tag  `json:"tags,omitempty"`
+	DeleteMarkerReplication bool   `json:"delete_marker_replication"`
+	Destination             string `json:"destination"` // 目标 ARN
+}
+
+// ReplicationTarget 远端复制目标：S3 兼容服务上的一个 Bucket
+type ReplicationTarget struct {
+	ID        string    `json:"id"`
+	ARN       string    `json:"arn"`
+	Endpoint  string    `json:"endpoint"`
+	Region    string    `json:"region"`
+	AccessKey string    `json:"access_key"`
+	SecretKey string    `json:"-"`
+	Bucket    string    `json:"bucket"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReplicationTask 复制队列中的一项：将对象的当前状态复制到一个目标
+type ReplicationTask struct {
+	ID            int64     `json:"id"`
+	BucketID      int64     `json:"bucket_id"`
+	Key           string    `json:"key"`
+	Destination   string    `json:"destination"`
+	RuleID        string    `json:"rule_id"`
+	Operation     string    `json:"operation"`
+	Status        string    `json:"status"`
+	Version       int64     `json:"-"` // 每次重新入队加一，处理期间对象被再次写入时旧结果不会覆盖新任务
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ReplicationTaskFilter 复制队列查询条件
+type ReplicationTaskFilter struct {
+	BucketID int64 // 0 表示全部
+	Status   string
+	Limit    int
+	Offset   int
+}
+
+// LocalBucketARN 返回本地 Bucket 的 ARN
+func LocalBucketARN(bucket string) string {
+	return LocalBucketARNPrefix + bucket
+}
+
+// ReplicationTargetARN 返回远端复制目标的 ARN
+func ReplicationTargetARN(id string) string {
+	return ReplicationTargetARNPrefix + id
+}
+
+// ParseReplicationDestination 解析目标 ARN，返回本地 Bucket 名称或远端目标 ID 之一
+func ParseReplicationDestination(arn string) (localBucket, targetID string, err error) {
+	switch {
+	case strings.HasPrefix(arn, LocalBucketARNPrefix) && len(arn) > len(LocalBucketARNPrefix):
+		return strings.TrimPrefix(arn, LocalBucketARNPrefix), "", nil
+	case strings.HasPrefix(arn, ReplicationTargetARNPrefix) && len(arn) > len(ReplicationTargetARNPrefix):
+		return "", strings.TrimPrefix(arn, ReplicationTargetARNPrefix), nil
+	}
+	return "", "", fmt.Errorf("invalid destination bucket %q", arn)
+}
+
+// Validate 校验规则 ID、优先级、过滤条件和目标 ARN
+func (c *ReplicationConfiguration) Validate() error {
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	if len(c.Rules) > MaxReplicationRules {
+		return fmt.Errorf("rule count %d exceeds limit of %d", len(c.Rules), MaxReplicationRules)
+	}
+	ids := make(map[string]bool, len(c.Rules))
+	priorities := make(map[string]bool, len(c.Rules))
+	for _, rule := range c.Rules {
+		if len(rule.ID) > 255 {
+			return fmt.Errorf("rule ID %q exceeds 255 characters", rule.ID)
+		}
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				return fmt.Errorf("duplicate rule ID %q", rule.ID)
+			}
+			ids[rule.ID] = true
+		}
+		if rule.Priority < 0 {
+			return fmt.Errorf("rule %q has a negative priority", rule.ID)
+		}
+		// 同一目标的规则按优先级选择，优先级不能相同
+		key := fmt.Sprintf("%s/%d", rule.Destination, rule.Priority)
+		if priorities[key] {
+			return fmt.Errorf("rules for destination %s have the same priority %d", rule.Destination, rule.Priority)
+		}
+		priorities[key] = true
+		if err := ValidateTags(rule.Tags, MaxObjectTags); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.ID, err)
+		}
+		if rule.DeleteMarkerReplication && len(rule.Tags) > 0 {
+			return fmt.Errorf("rule %q: delete marker replication is not supported for tag-based rules", rule.ID)
+		}
+		if _, _, err := ParseReplicationDestination(rule.Destination); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// matches 判断对象 Key 和标签是否满足规则的过滤条件
+func (r *ReplicationRule) matches(key string, tags []Tag) bool {
+	if !strings.HasPrefix(key, r.Prefix) {
+		return false
+	}
+	for _, want := range r.Tags {
+		found := false
+		for _, tag := range tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// RulesFor 返回对象写入或删除时需要复制到的目标，每个目标取匹配的已启用规则中优先级最高的一条。
+// 删除只复制到该规则开启了删除标记复制的目标，删除时对象标签不可用，只按前缀匹配
+func (c *ReplicationConfiguration) RulesFor(key string, tags []Tag, operation string) []ReplicationRule {
+	best := make(map[string]ReplicationRule)
+	for _, rule := range c.Rules {
+		if !rule.Enabled {
+			continue
+		}
+		if operation == ReplicationOpDelete && len(rule.Tags) > 0 {
+			continue
+		}
+		if !rule.matches(key, tags) {
+			continue
+		}
+		if current, ok := best[rule.Destination]; !ok || rule.Priority > current.Priority {
+			best[rule.Destination] = rule
+		}
+	}
+
+	rules := make([]ReplicationRule, 0, len(best))
+	for _, rule := range best {
+		if operation == ReplicationOpDelete && !rule.DeleteMarkerReplication {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Destination < rules[j].Destination })
+	return rules
+}
+
+// HasDestination 判断配置中是否有启用的规则指向该目标
+func (c *ReplicationConfiguration) HasDestination(destination string) bool {
+	for _, rule := range c.Rules {
+		if rule.Enabled && rule.Destination == destination {
+			return true
+		}
+	}
+	return false
+}
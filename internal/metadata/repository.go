@@ -38,24 +38,36 @@ type Bucket struct {
 	ACL           string
 	Versioning    bool
 	DefaultExpiry string // 预签名URL默认过期时间，如 "7d", "4w", "2h30m"
-	CreatedAt     time.Time
+	// Object Lock 配置，只能在创建 Bucket 时启用
+	ObjectLockEnabled     bool
+	DefaultRetentionMode  string // GOVERNANCE | COMPLIANCE，为空表示无默认保留
+	DefaultRetentionDays  int    // 默认保留期按天或按年设置，两者只有一个非零
+	DefaultRetentionYears int
+	CreatedAt             time.Time
 }
 
 // Object 对象
 type Object struct {
-	ID             int64
-	BucketID       int64
-	Key            string
-	VersionID      string
-	Size           int64
-	ETag           string
-	ContentType    string
-	StorageClass   string
-	StoragePath    string
-	Metadata       map[string]string
-	IsDeleteMarker bool
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	ID              int64
+	BucketID        int64
+	Key             string
+	VersionID       string
+	Size            int64
+	ETag            string
+	ContentType     string
+	StorageClass    string
+	StoragePath     string
+	Metadata        map[string]string
+	IsDeleteMarker  bool
+	LockMode        string     // GOVERNANCE | COMPLIANCE，为空表示无保留
+	RetainUntil     *time.Time // 保留截止时间
+	LegalHold       bool
+	IntegrityStatus string     // 后台巡检结果，为空表示尚未校验
+	VerifiedAt      *time.Time // 最近一次校验时间
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	ReplicationStatus string // 复制状态，见 ReplicationStatus* 常量
 }
 
 // MultipartUpload 分片上传
@@ -99,6 +111,24 @@ type ListObjectsResult struct {
 	NextContinuationToken string
 }
 
+// ListMultipartUploadsOptions 分片上传列表选项
+type ListMultipartUploadsOptions struct {
+	Prefix         string
+	Delimiter      string
+	KeyMarker      string
+	UploadIDMarker string
+	MaxUploads     int
+}
+
+// ListMultipartUploadsResult 分片上传列表结果
+type ListMultipartUploadsResult struct {
+	Uploads            []MultipartUpload
+	CommonPrefixes     []string
+	IsTruncated        bool
+	NextKeyMarker      string
+	NextUploadIDMarker string
+}
+
 // Repository 元数据仓库接口
 type Repository interface {
 	// User 操作
@@ -129,6 +159,11 @@ type Repository interface {
 	GetBucketPolicy(ctx context.Context, bucketID int64) ([]byte, error)
 	DeleteBucketPolicy(ctx context.Context, bucketID int64) error
 
+	// Bucket Tagging 操作
+	SetBucketTags(ctx context.Context, bucketID int64, tags []Tag) error
+	GetBucketTags(ctx context.Context, bucketID int64) ([]Tag, error)
+	DeleteBucketTags(ctx context.Context, bucketID int64) error
+
 	// Audit Logs
 	CreateAuditLog(ctx context.Context, log *AuditLog) error
 	GetAuditLogs(ctx context.Context, filter *AuditLogFilter) ([]*AuditLog, error)
@@ -144,15 +179,91 @@ type Repository interface {
 	DeleteObjectsByBucketID(ctx context.Context, bucketID int64) error
 	GetBucketStats(ctx context.Context, bucketID int64) (objectCount int64, totalSize int64, err error)
 
+	// Object Tagging 操作
+	SetObjectTags(ctx context.Context, objectID int64, tags []Tag) error
+	GetObjectTags(ctx context.Context, objectID int64) ([]Tag, error)
+	DeleteObjectTags(ctx context.Context, objectID int64) error
+	ListObjectsByTag(ctx context.Context, bucketID int64, tagKey, tagValue string) ([]Object, error)
+
+	// Object Lock 操作
+	SetObjectRetention(ctx context.Context, objectID int64, mode string, retainUntil *time.Time) error
+	SetObjectLegalHold(ctx context.Context, objectID int64, legalHold bool) error
+	CountLockedObjects(ctx context.Context, bucketID int64) (int64, error)
+
+	// 数据完整性巡检
+	ListObjectsToVerify(ctx context.Context, verifiedBefore time.Time, limit int) ([]Object, error)
+	SetObjectIntegrity(ctx context.Context, objectID int64, etag, status string, verifiedAt time.Time) (bool, error)
+	CountObjectsByIntegrity(ctx context.Context) (map[string]int64, error)
+
+	// 配额与用量
+	GetQuota(ctx context.Context, scope string, id int64) (*Quota, error)
+	SetQuota(ctx context.Context, scope string, id int64, quota *Quota) error
+	DeleteQuota(ctx context.Context, scope string, id int64) error
+	GetBucketUsage(ctx context.Context, bucketID int64) (*Usage, error)
+	GetUserUsage(ctx context.Context, userID int64) (*Usage, error)
+	RecalculateUsage(ctx context.Context) error
+
+	// 用量计费
+	AddUsageCounters(ctx context.Context, counters []UsageCounter) error
+	SampleStorageUsage(ctx context.Context, now time.Time, minInterval, maxGap time.Duration) (float64, error)
+	GetUsageReport(ctx context.Context, filter *UsageReportFilter) ([]UsageReportRow, error)
+
+	// 只读模式
+	ListReadOnlyModes(ctx context.Context) ([]ReadOnlyMode, error)
+	SetReadOnlyMode(ctx context.Context, mode *ReadOnlyMode) error
+	DeleteReadOnlyMode(ctx context.Context, scope, bucketName string) (bool, error)
+
+	// 迁移任务
+	CreateMigrationJob(ctx context.Context, job *MigrationJob) error
+	GetMigrationJob(ctx context.Context, id string) (*MigrationJob, error)
+	ListMigrationJobs(ctx context.Context, filter *MigrationJobFilter) ([]MigrationJob, error)
+	StartMigrationJob(ctx context.Context, id string) (bool, error)
+	HeartbeatMigrationJob(ctx context.Context, id string) (bool, error)
+	SetMigrationJobTotal(ctx context.Context, id string, totalBuckets int) error
+	FinishMigrationJob(ctx context.Context, id, status, errMsg string) (bool, error)
+	ScheduleMigrationJob(ctx context.Context, id string, nextRun time.Time, errMsg string) (bool, error)
+	ResumeMigrationJob(ctx context.Context, id string) (bool, error)
+	ClaimStaleMigrationJobs(ctx context.Context, staleBefore time.Time) ([]MigrationJob, error)
+	UpsertMigrationBucket(ctx context.Context, b *MigrationBucket) error
+	ListMigrationBuckets(ctx context.Context, jobID string) ([]MigrationBucket, error)
+	AddMigrationError(ctx context.Context, e *MigrationError) error
+	ListMigrationErrors(ctx context.Context, jobID string, limit, offset int) ([]MigrationError, error)
+	UpsertMigrationObject(ctx context.Context, o *MigrationObject) error
+	ListMigrationObjects(ctx context.Context, jobID string, filter *MigrationObjectFilter) ([]MigrationObject, error)
+	SummarizeMigrationObjects(ctx context.Context, jobID string) (*MigrationObjectSummary, error)
+	ListObjectsInKeyRange(ctx context.Context, bucketID int64, prefix, after, through string, limit int) ([]Object, error)
+
+	// Bucket 复制
+	SetBucketReplication(ctx context.Context, bucketID int64, cfg *ReplicationConfiguration) error
+	GetBucketReplication(ctx context.Context, bucketID int64) (*ReplicationConfiguration, error)
+	DeleteBucketReplication(ctx context.Context, bucketID int64) error
+	CreateReplicationTarget(ctx context.Context, target *ReplicationTarget) error
+	GetReplicationTarget(ctx context.Context, id string) (*ReplicationTarget, error)
+	ListReplicationTargets(ctx context.Context) ([]ReplicationTarget, error)
+	DeleteReplicationTarget(ctx context.Context, id string) (bool, error)
+	ReplicationTargetInUse(ctx context.Context, arn string) (bool, error)
+	EnqueueReplication(ctx context.Context, tasks []ReplicationTask) error
+	ClaimReplicationTasks(ctx context.Context, limit int, lease time.Duration) ([]ReplicationTask, error)
+	CompleteReplicationTask(ctx context.Context, id, version int64) (bool, error)
+	RetryReplicationTask(ctx context.Context, id, version int64, attempts int, status, errMsg string, nextAttempt time.Time) (bool, error)
+	ListReplicationTasks(ctx context.Context, filter *ReplicationTaskFilter) ([]ReplicationTask, error)
+	CountReplicationTasks(ctx context.Context) (map[string]int64, error)
+	RequeueFailedReplication(ctx context.Context, bucketID int64) (int64, error)
+	SetObjectReplicationStatus(ctx context.Context, bucketID int64, key, etag, status string) (bool, error)
+	CountObjectsByReplicationStatus(ctx context.Context) (map[string]int64, error)
+
 	// MultipartUpload 操作
 	CreateMultipartUpload(ctx context.Context, upload *MultipartUpload) error
 	GetMultipartUpload(ctx context.Context, uploadID string) (*MultipartUpload, error)
-	ListMultipartUploads(ctx context.Context, bucketID int64) ([]MultipartUpload, error)
+	ListMultipartUploads(ctx context.Context, bucketID int64, opts ListMultipartUploadsOptions) (*ListMultipartUploadsResult, error)
+	ListMultipartUploadsBefore(ctx context.Context, before time.Time) ([]MultipartUpload, error)
+	CountMultipartUploads(ctx context.Context) (int64, error)
 	DeleteMultipartUpload(ctx context.Context, uploadID string) error
 
 	// UploadPart 操作
 	CreateUploadPart(ctx context.Context, part *UploadPart) error
 	GetUploadParts(ctx context.Context, uploadID string) ([]UploadPart, error)
+	ListUploadParts(ctx context.Context, uploadID string, partNumberMarker, maxParts int) ([]UploadPart, error)
 	DeleteUploadParts(ctx context.Context, uploadID string) error
 
 	// 事务
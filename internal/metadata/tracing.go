@@ -0,0 +1,42 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gooss/server/internal/tracing"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTracedStatementLength span 中记录的 SQL 最大长度
+const maxTracedStatementLength = 1024
+
+// queryTracer 为每条 SQL 查询创建 span，实现 pgx.QueryTracer
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	statement := strings.Join(strings.Fields(data.SQL), " ")
+	if len(statement) > maxTracedStatementLength {
+		statement = statement[:maxTracedStatementLength]
+	}
+
+	ctx, _ = tracing.Start(ctx, "db.query",
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", statement),
+	)
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	// 未找到记录属于正常查询结果，不标记为错误
+	if data.Err == pgx.ErrNoRows {
+		data.Err = nil
+	}
+	tracing.End(span, data.Err)
+}
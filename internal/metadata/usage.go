@@ -0,0 +1,45 @@
+package metadata
+
+import "time"
+
+// 请求计费类别：A 类为写入和列举，B 类为读取，其余（删除、预检等）不计费
+const (
+	RequestClassA     = "A"
+	RequestClassB     = "B"
+	RequestClassOther = "other"
+)
+
+// UsageCounter 一段时间内累计的请求数和流量，按天、所有者和 Bucket 汇总
+type UsageCounter struct {
+	Day            time.Time
+	OwnerID        int64
+	BucketName     string
+	ClassARequests int64
+	ClassBRequests int64
+	OtherRequests  int64
+	IngressBytes   int64
+	EgressBytes    int64
+}
+
+// UsageReportFilter 用量报表查询条件，GroupBy 为 bucket、user 或 day
+type UsageReportFilter struct {
+	From       time.Time // 包含
+	To         time.Time // 不包含
+	OwnerID    *int64
+	BucketName string
+	GroupBy    string
+}
+
+// UsageReportRow 用量报表行
+type UsageReportRow struct {
+	Day              *time.Time `json:"day,omitempty"`
+	OwnerID          int64      `json:"owner_id"`
+	Username         string     `json:"username"`
+	BucketName       string     `json:"bucket_name,omitempty"`
+	StorageByteHours int64      `json:"storage_byte_hours"`
+	ClassARequests   int64      `json:"class_a_requests"`
+	ClassBRequests   int64      `json:"class_b_requests"`
+	OtherRequests    int64      `json:"other_requests"`
+	IngressBytes     int64      `json:"ingress_bytes"`
+	EgressBytes      int64      `json:"egress_bytes"`
+}
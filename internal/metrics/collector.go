@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector 导出 pgx 连接池状态
+type PoolCollector struct {
+	stat func() *pgxpool.Stat
+
+	totalConns      *prometheus.Desc
+	idleConns       *prometheus.Desc
+	acquiredConns   *prometheus.Desc
+	maxConns        *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+	canceledAcquire *prometheus.Desc
+}
+
+// NewPoolCollector 创建连接池指标采集器
+func NewPoolCollector(stat func() *pgxpool.Stat) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &PoolCollector{
+		stat:            stat,
+		totalConns:      desc("total_conns", "Total number of connections in the pool."),
+		idleConns:       desc("idle_conns", "Number of idle connections in the pool."),
+		acquiredConns:   desc("acquired_conns", "Number of currently acquired connections."),
+		maxConns:        desc("max_conns", "Maximum size of the pool."),
+		acquireCount:    desc("acquire_total", "Cumulative count of successful acquires."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Total time spent waiting for connections."),
+		emptyAcquire:    desc("empty_acquire_total", "Cumulative count of acquires that waited for a connection."),
+		canceledAcquire: desc("canceled_acquire_total", "Cumulative count of acquires canceled by context."),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (p *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.totalConns
+	ch <- p.idleConns
+	ch <- p.acquiredConns
+	ch <- p.maxConns
+	ch <- p.acquireCount
+	ch <- p.acquireDuration
+	ch <- p.emptyAcquire
+	ch <- p.canceledAcquire
+}
+
+// Collect 实现 prometheus.Collector
+func (p *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := p.stat()
+	ch <- prometheus.MustNewConstMetric(p.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(p.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(p.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(p.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(p.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(p.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
+
+// repoCacheTTL 元数据统计的缓存时间，避免每次抓取都逐个 Bucket 查询数据库
+const repoCacheTTL = time.Minute
+
+type bucketStat struct {
+	name    string
+	objects int64
+	bytes   int64
+}
+
+// RepositoryCollector 导出来自元数据库的统计：各 Bucket 对象数与容量、进行中的分片上传数
+type RepositoryCollector struct {
+	repo metadata.Repository
+
+	bucketObjects    *prometheus.Desc
+	bucketBytes      *prometheus.Desc
+	multipartUploads *prometheus.Desc
+
+	mu        sync.Mutex
+	updatedAt time.Time
+	buckets   []bucketStat
+	uploads   int64
+}
+
+// NewRepositoryCollector 创建元数据统计采集器
+func NewRepositoryCollector(repo metadata.Repository) *RepositoryCollector {
+	return &RepositoryCollector{
+		repo: repo,
+		bucketObjects: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bucket", "objects"),
+			"Number of objects in the bucket.", []string{"bucket"}, nil),
+		bucketBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "bucket", "size_bytes"),
+			"Total size of objects in the bucket.", []string{"bucket"}, nil),
+		multipartUploads: prometheus.NewDesc(prometheus.BuildFQName(namespace, "multipart", "uploads_in_progress"),
+			"Number of multipart uploads that have been initiated but not completed or aborted.", nil, nil),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (r *RepositoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.bucketObjects
+	ch <- r.bucketBytes
+	ch <- r.multipartUploads
+}
+
+// Collect 实现 prometheus.Collector
+func (r *RepositoryCollector) Collect(ch chan<- prometheus.Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.updatedAt) > repoCacheTTL {
+		r.refresh()
+	}
+
+	for _, b := range r.buckets {
+		ch <- prometheus.MustNewConstMetric(r.bucketObjects, prometheus.GaugeValue, float64(b.objects), b.name)
+		ch <- prometheus.MustNewConstMetric(r.bucketBytes, prometheus.GaugeValue, float64(b.bytes), b.name)
+	}
+	ch <- prometheus.MustNewConstMetric(r.multipartUploads, prometheus.GaugeValue, float64(r.uploads))
+}
+
+// refresh 重新查询统计，失败时保留上一次的结果
+func (r *RepositoryCollector) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	buckets, err := r.repo.ListAllBuckets(ctx)
+	if err != nil {
+		logger.Warnf("Failed to collect bucket metrics: %v", err)
+		return
+	}
+
+	stats := make([]bucketStat, 0, len(buckets))
+	for _, bucket := range buckets {
+		objects, size, err := r.repo.GetBucketStats(ctx, bucket.ID)
+		if err != nil {
+			logger.Warnf("Failed to collect stats for bucket %s: %v", bucket.Name, err)
+			continue
+		}
+		stats = append(stats, bucketStat{name: bucket.Name, objects: objects, bytes: size})
+	}
+
+	uploads, err := r.repo.CountMultipartUploads(ctx)
+	if err != nil {
+		logger.Warnf("Failed to count multipart uploads: %v", err)
+		uploads = r.uploads
+	}
+
+	r.buckets = stats
+	r.uploads = uploads
+	r.updatedAt = time.Now()
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "oss"
+
+var (
+	// HTTPRequestsTotal 按操作、方法和状态码统计的请求数
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by operation, method and status code.",
+	}, []string{"operation", "method", "code"})
+
+	// HTTPRequestDuration 按操作统计的请求耗时
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+	}, []string{"operation"})
+
+	// HTTPReceivedBytes 请求体字节数
+	HTTPReceivedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "received_bytes_total",
+		Help:      "Total bytes received in request bodies by operation.",
+	}, []string{"operation"})
+
+	// HTTPSentBytes 响应体字节数
+	HTTPSentBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "sent_bytes_total",
+		Help:      "Total bytes sent in response bodies by operation.",
+	}, []string{"operation"})
+
+	// HTTPInFlight 正在处理的请求数
+	HTTPInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served.",
+	})
+
+	// StorageOperationDuration 存储引擎操作耗时
+	StorageOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "operation_duration_seconds",
+		Help:      "Storage engine operation latency by operation and result.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 16),
+	}, []string{"operation", "result"})
+
+	// AuditLogWriteFailures 审计日志写入失败次数
+	AuditLogWriteFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "audit",
+		Name:      "write_failures_total",
+		Help:      "Total number of audit log records that failed to be written.",
+	})
+
+	// RateLimitedRequests 被限流拒绝的请求数
+	RateLimitedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "rejected_requests_total",
+		Help:      "Total number of requests rejected by rate limiting by scope.",
+	}, []string{"scope"})
+
+	// RateLimitThrottleSeconds 带宽限制造成的等待时长
+	RateLimitThrottleSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "throttle_seconds_total",
+		Help:      "Total time spent waiting on bandwidth limits by direction.",
+	}, []string{"direction"})
+)
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gooss/server/internal/storage"
+)
+
+// instrumentedEngine 记录存储引擎操作耗时的包装器，未覆盖的方法直接透传
+type instrumentedEngine struct {
+	storage.Engine
+}
+
+// InstrumentStorage 为存储引擎添加操作耗时指标
+func InstrumentStorage(engine storage.Engine) storage.Engine {
+	return &instrumentedEngine{Engine: engine}
+}
+
+func observe(operation string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	StorageOperationDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
+
+func (e *instrumentedEngine) Put(ctx context.Context, bucket, key string, reader io.Reader, size int64, contentType string) (*storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := e.Engine.Put(ctx, bucket, key, reader, size, contentType)
+	observe("put", start, err)
+	return info, err
+}
+
+func (e *instrumentedEngine) Get(ctx context.Context, bucket, key string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	start := time.Now()
+	rc, info, err := e.Engine.Get(ctx, bucket, key)
+	observe("get", start, err)
+	return rc, info, err
+}
+
+func (e *instrumentedEngine) GetRange(ctx context.Context, bucket, key string, start, end int64) (io.ReadCloser, *storage.ObjectInfo, error) {
+	began := time.Now()
+	rc, info, err := e.Engine.GetRange(ctx, bucket, key, start, end)
+	observe("get_range", began, err)
+	return rc, info, err
+}
+
+func (e *instrumentedEngine) Delete(ctx context.Context, bucket, key string) error {
+	start := time.Now()
+	err := e.Engine.Delete(ctx, bucket, key)
+	observe("delete", start, err)
+	return err
+}
+
+func (e *instrumentedEngine) Stat(ctx context.Context, bucket, key string) (*storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := e.Engine.Stat(ctx, bucket, key)
+	observe("stat", start, err)
+	return info, err
+}
+
+func (e *instrumentedEngine) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) (*storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := e.Engine.Copy(ctx, srcBucket, srcKey, dstBucket, dstKey)
+	observe("copy", start, err)
+	return info, err
+}
+
+func (e *instrumentedEngine) Rename(ctx context.Context, bucket, srcKey, dstKey string) (*storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := e.Engine.Rename(ctx, bucket, srcKey, dstKey)
+	observe("rename", start, err)
+	return info, err
+}
+
+func (e *instrumentedEngine) PutPart(ctx context.Context, bucket, key, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	start := time.Now()
+	etag, err := e.Engine.PutPart(ctx, bucket, key, uploadID, partNumber, reader, size)
+	observe("put_part", start, err)
+	return etag, err
+}
+
+func (e *instrumentedEngine) CompleteParts(ctx context.Context, bucket, key, uploadID string, parts []storage.PartInfo) (*storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := e.Engine.CompleteParts(ctx, bucket, key, uploadID, parts)
+	observe("complete_parts", start, err)
+	return info, err
+}
+
+func (e *instrumentedEngine) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	start := time.Now()
+	err := e.Engine.AbortMultipartUpload(ctx, bucket, key, uploadID)
+	observe("abort_multipart", start, err)
+	return err
+}
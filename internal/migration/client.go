@@ -0,0 +1,409 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gooss/server/internal/auth"
+	"github.com/gooss/server/internal/metadata"
+)
+
+// Client 访问外部 S3 兼容服务（迁移源端或复制目标），使用 Signature V4 签名和路径风格地址
+type Client struct {
+	endpoint *url.URL
+	signer   *auth.SignatureV4
+	http     *http.Client
+}
+
+// SourceBucket 源端 Bucket
+type SourceBucket struct {
+	Name         string
+	CreationDate string
+}
+
+// SourceObject 源端对象
+type SourceObject struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified string
+}
+
+// ObjectPage 一页列举结果
+type ObjectPage struct {
+	Objects               []SourceObject
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// ResponseError 源端返回的非 2xx 响应
+type ResponseError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s %s failed: %d - %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// NewClient 创建源端客户端，endpoint 缺少协议时按 http 处理
+func NewClient(endpoint, accessKey, secretKey, region string) (*Client, error) {
+	endpoint = NormalizeEndpoint(endpoint)
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source endpoint: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid source endpoint: %q", endpoint)
+	}
+	return &Client{
+		endpoint: u,
+		signer:   auth.NewSignatureV4(accessKey, secretKey, region),
+		http:     &http.Client{},
+	}, nil
+}
+
+// NormalizeEndpoint 去掉末尾斜杠并补全协议
+func NormalizeEndpoint(endpoint string) string {
+	endpoint = strings.TrimSuffix(strings.TrimSpace(endpoint), "/")
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "http://" + endpoint
+	}
+	return endpoint
+}
+
+// do 发送不带请求体的签名请求，非 2xx 响应转换为 *ResponseError
+func (c *Client) do(ctx context.Context, method, bucket, key string, query url.Values, header http.Header) (*http.Response, error) {
+	return c.send(ctx, method, bucket, key, query, header, nil, 0)
+}
+
+// send 发送签名请求，请求体不参与签名（UNSIGNED-PAYLOAD）
+func (c *Client) send(ctx context.Context, method, bucket, key string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	path := strings.TrimSuffix(c.endpoint.Path, "/") + "/"
+	if bucket != "" {
+		path += auth.EncodePath(bucket)
+		if key != "" {
+			path += "/" + auth.EncodePath(key)
+		}
+	}
+	rawURL := c.endpoint.Scheme + "://" + c.endpoint.Host + path
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+
+	// 长度为 0 时使用 NoBody，否则会按未知长度分块发送
+	if body != nil && size == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil && body != http.NoBody {
+		req.ContentLength = size
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	c.signer.SignRequest(req, "")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, &ResponseError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return resp, nil
+}
+
+// ListBuckets 列出源端所有 Bucket
+func (c *Client) ListBuckets(ctx context.Context) ([]SourceBucket, error) {
+	resp, err := c.do(ctx, http.MethodGet, "", "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Buckets struct {
+			Bucket []struct {
+				Name         string `xml:"Name"`
+				CreationDate string `xml:"CreationDate"`
+			} `xml:"Bucket"`
+		} `xml:"Buckets"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	buckets := make([]SourceBucket, 0, len(result.Buckets.Bucket))
+	for _, b := range result.Buckets.Bucket {
+		buckets = append(buckets, SourceBucket{Name: b.Name, CreationDate: b.CreationDate})
+	}
+	return buckets, nil
+}
+
+// ListObjects 使用 ListObjectsV2 列出以 prefix 开头的一页对象（按 Key 字典序）。token 为空时从 startAfter 之后开始，
+// 否则沿用上一页返回的 continuation token
+func (c *Client) ListObjects(ctx context.Context, bucket, prefix, startAfter, token string, maxKeys int) (*ObjectPage, error) {
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("max-keys", strconv.Itoa(maxKeys))
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if token != "" {
+		query.Set("continuation-token", token)
+	} else if startAfter != "" {
+		query.Set("start-after", startAfter)
+	}
+	resp, err := c.do(ctx, http.MethodGet, bucket, "", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		IsTruncated           bool   `xml:"IsTruncated"`
+		NextContinuationToken string `xml:"NextContinuationToken"`
+		Contents              []struct {
+			Key          string `xml:"Key"`
+			Size         int64  `xml:"Size"`
+			ETag         string `xml:"ETag"`
+			LastModified string `xml:"LastModified"`
+		} `xml:"Contents"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	page := &ObjectPage{IsTruncated: result.IsTruncated, NextContinuationToken: result.NextContinuationToken}
+	for _, obj := range result.Contents {
+		page.Objects = append(page.Objects, SourceObject{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			ETag:         strings.Trim(obj.ETag, "\""),
+			LastModified: obj.LastModified,
+		})
+	}
+	return page, nil
+}
+
+// GetObject 下载对象，调用方负责关闭返回的响应体
+func (c *Client) GetObject(ctx context.Context, bucket, key string) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, bucket, key, nil, nil)
+}
+
+// GetObjectRange 下载对象的 [start, end] 字节范围。etag 非空时要求源对象未被修改，否则返回 412
+func (c *Client) GetObjectRange(ctx context.Context, bucket, key string, start, end int64, etag string) (*http.Response, error) {
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if etag != "" {
+		header.Set("If-Match", "\""+etag+"\"")
+	}
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("source ignored range request for %s/%s: %d", bucket, key, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// HeadObject 获取对象的元数据 Header
+func (c *Client) HeadObject(ctx context.Context, bucket, key string) (http.Header, error) {
+	resp, err := c.do(ctx, http.MethodHead, bucket, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp.Header, nil
+}
+
+// PutObject 上传对象，header 中可带 Content-Type、x-amz-meta-* 和 x-amz-tagging，返回目标端的 ETag
+func (c *Client) PutObject(ctx context.Context, bucket, key string, body io.Reader, size int64, header http.Header) (string, error) {
+	resp, err := c.send(ctx, http.MethodPut, bucket, key, nil, header, body, size)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return strings.Trim(resp.Header.Get("ETag"), "\""), nil
+}
+
+// CompletedPart 已上传的分片
+type CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// CreateMultipartUpload 初始化分片上传，header 与 PutObject 相同，返回 upload ID
+func (c *Client) CreateMultipartUpload(ctx context.Context, bucket, key string, header http.Header) (string, error) {
+	resp, err := c.send(ctx, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, header, http.NoBody, 0)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("missing upload ID in response")
+	}
+	return result.UploadID, nil
+}
+
+// UploadPart 上传一个分片，返回分片 ETag
+func (c *Client) UploadPart(ctx context.Context, bucket, key, uploadID string, partNumber int, body io.Reader, size int64) (string, error) {
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(partNumber))
+	query.Set("uploadId", uploadID)
+	resp, err := c.send(ctx, http.MethodPut, bucket, key, query, nil, body, size)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return strings.Trim(resp.Header.Get("ETag"), "\""), nil
+}
+
+// CompleteMultipartUpload 合并分片，返回对象 ETag。S3 可能在 200 响应体中返回错误，同样转换为 *ResponseError
+func (c *Client) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []CompletedPart, header http.Header) (string, error) {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.send(ctx, http.MethodPost, bucket, key, url.Values{"uploadId": {uploadID}}, header, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		XMLName xml.Name
+		ETag    string `xml:"ETag"`
+	}
+	if err := xml.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.XMLName.Local == "Error" {
+		return "", &ResponseError{Method: http.MethodPost, Path: "/" + bucket + "/" + key, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	return strings.Trim(result.ETag, "\""), nil
+}
+
+// AbortMultipartUpload 取消分片上传
+func (c *Client) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	resp, err := c.do(ctx, http.MethodDelete, bucket, key, url.Values{"uploadId": {uploadID}}, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// DeleteObject 删除对象，对象不存在时不报错
+func (c *Client) DeleteObject(ctx context.Context, bucket, key string, header http.Header) error {
+	resp, err := c.do(ctx, http.MethodDelete, bucket, key, nil, header)
+	if err != nil {
+		var respErr *ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound && !strings.Contains(respErr.Body, "NoSuchBucket") {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetObjectTagging 获取对象标签
+func (c *Client) GetObjectTagging(ctx context.Context, bucket, key string) ([]metadata.Tag, error) {
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, url.Values{"tagging": {""}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return parseTagging(resp.Body)
+}
+
+// GetBucketConfig 获取 Bucket 子资源（如 policy、cors、lifecycle）的原始内容，未配置（404）时返回 nil
+func (c *Client) GetBucketConfig(ctx context.Context, bucket, subresource string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, bucket, "", url.Values{subresource: {""}}, nil)
+	if err != nil {
+		var respErr *ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// GetBucketTagging 获取 Bucket 标签，未设置时返回空
+func (c *Client) GetBucketTagging(ctx context.Context, bucket string) ([]metadata.Tag, error) {
+	data, err := c.GetBucketConfig(ctx, bucket, "tagging")
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return parseTagging(bytes.NewReader(data))
+}
+
+// GetBucketVersioning 获取 Bucket 版本控制状态：Enabled、Suspended，从未开启时为空
+func (c *Client) GetBucketVersioning(ctx context.Context, bucket string) (string, error) {
+	data, err := c.GetBucketConfig(ctx, bucket, "versioning")
+	if err != nil || data == nil {
+		return "", err
+	}
+	var result struct {
+		Status string `xml:"Status"`
+	}
+	if err := xml.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.Status, nil
+}
+
+// parseTagging 解析 Tagging XML
+func parseTagging(r io.Reader) ([]metadata.Tag, error) {
+	var result struct {
+		TagSet struct {
+			Tag []struct {
+				Key   string `xml:"Key"`
+				Value string `xml:"Value"`
+			} `xml:"Tag"`
+		} `xml:"TagSet"`
+	}
+	if err := xml.NewDecoder(r).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	tags := make([]metadata.Tag, 0, len(result.TagSet.Tag))
+	for _, t := range result.TagSet.Tag {
+		tags = append(tags, metadata.Tag{Key: t.Key, Value: t.Value})
+	}
+	return tags, nil
+}
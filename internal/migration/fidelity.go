@@ -0,0 +1,244 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// Bucket 配置的迁移结果
+const (
+	settingCopied      = "copied"
+	settingNone        = "none"
+	settingUnsupported = "not supported by target"
+	settingUnavailable = "not supported by source"
+)
+
+// unsupported 源端不支持该接口（501、405 或 NotImplemented）
+func unsupported(err error) bool {
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	return respErr.StatusCode == http.StatusNotImplemented || respErr.StatusCode == http.StatusMethodNotAllowed ||
+		strings.Contains(respErr.Body, "NotImplemented")
+}
+
+// settingResult 将配置读取或写入错误转换为迁移结果
+func settingResult(err error) string {
+	if unsupported(err) {
+		return settingUnavailable
+	}
+	return "failed: " + err.Error()
+}
+
+// copyBucketSettings 复制 Bucket 策略、版本控制和标签。CORS 和生命周期规则目标端不支持按 Bucket 配置，
+// 只记录源端是否设置过。单项失败不影响对象迁移，结果保存在 p.Settings 中
+func (r *runner) copyBucketSettings(ctx context.Context, client *Client, p *metadata.MigrationBucket, target *metadata.Bucket) {
+	source := p.SourceBucket
+	settings := map[string]string{}
+
+	var policy []byte
+	err := r.retry(ctx, "get policy of "+source, func() (err error) {
+		policy, err = client.GetBucketConfig(ctx, source, "policy")
+		return err
+	})
+	switch {
+	case err != nil:
+		settings["policy"] = settingResult(err)
+	case policy == nil:
+		settings["policy"] = settingNone
+	default:
+		settings["policy"] = r.copyPolicy(ctx, source, target, policy)
+	}
+
+	var versioning string
+	err = r.retry(ctx, "get versioning of "+source, func() (err error) {
+		versioning, err = client.GetBucketVersioning(ctx, source)
+		return err
+	})
+	switch {
+	case err != nil:
+		settings["versioning"] = settingResult(err)
+	case versioning == "Enabled":
+		settings["versioning"] = "enabled"
+		if !target.Versioning {
+			target.Versioning = true
+			if err := r.m.repo.UpdateBucket(ctx, target); err != nil {
+				target.Versioning = false
+				settings["versioning"] = settingResult(err)
+			}
+		}
+	case versioning == "Suspended":
+		// 目标端只有开启和关闭两种状态，暂停等同于关闭
+		settings["versioning"] = "suspended, left disabled"
+	default:
+		settings["versioning"] = settingNone
+	}
+
+	var tags []metadata.Tag
+	err = r.retry(ctx, "get tags of "+source, func() (err error) {
+		tags, err = client.GetBucketTagging(ctx, source)
+		return err
+	})
+	switch {
+	case err != nil:
+		settings["tags"] = settingResult(err)
+	case len(tags) == 0:
+		settings["tags"] = settingNone
+	default:
+		if err := metadata.ValidateTags(tags, metadata.MaxBucketTags); err != nil {
+			settings["tags"] = "failed: " + err.Error()
+		} else if err := r.m.repo.SetBucketTags(ctx, target.ID, tags); err != nil {
+			settings["tags"] = settingResult(err)
+		} else {
+			settings["tags"] = settingCopied
+		}
+	}
+
+	for _, name := range []string{"cors", "lifecycle"} {
+		var data []byte
+		err := r.retry(ctx, "get "+name+" of "+source, func() (err error) {
+			data, err = client.GetBucketConfig(ctx, source, name)
+			return err
+		})
+		switch {
+		case err != nil:
+			settings[name] = settingResult(err)
+		case data == nil:
+			settings[name] = settingNone
+		default:
+			settings[name] = settingUnsupported
+		}
+	}
+
+	for name, result := range settings {
+		if result != settingCopied && result != settingNone && result != "enabled" {
+			logger.Warnf("Migration job %s: %s of bucket %s: %s", r.job.ID, name, source, result)
+		}
+	}
+	// 中途停止时结果不完整，恢复后重新复制
+	if ctx.Err() == nil {
+		p.Settings = settings
+	}
+}
+
+// copyPolicy 写入 Bucket 策略，Bucket 改名时同步替换策略中的资源 ARN
+func (r *runner) copyPolicy(ctx context.Context, source string, target *metadata.Bucket, policy []byte) string {
+	if target.Name != source {
+		policy = []byte(strings.NewReplacer(
+			`"arn:aws:s3:::`+source+`"`, `"arn:aws:s3:::`+target.Name+`"`,
+			`"arn:aws:s3:::`+source+`/`, `"arn:aws:s3:::`+target.Name+`/`,
+		).Replace(string(policy)))
+	}
+	var parsed metadata.BucketPolicy
+	if err := json.Unmarshal(policy, &parsed); err != nil {
+		return "failed: invalid policy JSON"
+	}
+	if err := r.m.repo.SetBucketPolicy(ctx, target.ID, policy); err != nil {
+		return settingResult(err)
+	}
+	return settingCopied
+}
+
+// objectAttributes 从源端响应 Header 中得到的对象属性，以及无法保留的属性说明
+type objectAttributes struct {
+	contentType string
+	metadata    map[string]string
+	tags        []metadata.Tag
+	issues      []string
+}
+
+// attributesFromHeader 提取 Content-Type、用户元数据和标准 Header，记录目标端不支持的属性
+func attributesFromHeader(header http.Header) *objectAttributes {
+	attrs := &objectAttributes{
+		contentType: header.Get("Content-Type"),
+		metadata:    metadata.MetadataFromHeader(header),
+	}
+	if class := header.Get("x-amz-storage-class"); class != "" && class != "STANDARD" {
+		attrs.issues = append(attrs.issues, fmt.Sprintf("storage class %s stored as STANDARD", class))
+	}
+	// AES256（S3 托管密钥）为默认加密，内容按明文迁移即可；KMS 和客户提供的密钥无法保留
+	if sse := header.Get("x-amz-server-side-encryption"); sse != "" && sse != "AES256" {
+		attrs.issues = append(attrs.issues, fmt.Sprintf("server-side encryption %s not preserved", sse))
+	}
+	if header.Get("x-amz-server-side-encryption-customer-algorithm") != "" {
+		attrs.issues = append(attrs.issues, "customer-provided encryption key not preserved")
+	}
+	if header.Get("x-amz-website-redirect-location") != "" {
+		attrs.issues = append(attrs.issues, "website redirect location not preserved")
+	}
+	if header.Get("x-amz-object-lock-mode") != "" || header.Get("x-amz-object-lock-legal-hold") == "ON" {
+		attrs.issues = append(attrs.issues, "object lock settings not preserved")
+	}
+	if missing := header.Get("x-amz-missing-meta"); missing != "" && missing != "0" {
+		attrs.issues = append(attrs.issues, fmt.Sprintf("%s metadata entries not readable from source", missing))
+	}
+	return attrs
+}
+
+// fetchTags 读取对象标签。响应带 x-amz-tagging-count: 0 时跳过请求；源端不支持标签接口后整个任务不再请求。
+// 读取失败不影响对象迁移，只记入报告
+func (r *runner) fetchTags(ctx context.Context, client *Client, bucket, key string, header http.Header, attrs *objectAttributes) {
+	count := header.Get("x-amz-tagging-count")
+	if count == "0" {
+		return
+	}
+	if r.tagsUnsupported.Load() {
+		if n, _ := strconv.Atoi(count); n > 0 {
+			attrs.issues = append(attrs.issues, fmt.Sprintf("%d tags not copied: source does not support object tagging", n))
+		}
+		return
+	}
+
+	var tags []metadata.Tag
+	err := r.retry(ctx, "get tags of "+key, func() (err error) {
+		tags, err = client.GetObjectTagging(ctx, bucket, key)
+		return err
+	})
+	if err != nil {
+		if unsupported(err) {
+			r.tagsUnsupported.Store(true)
+		}
+		attrs.issues = append(attrs.issues, "tags not copied: "+err.Error())
+		return
+	}
+	if err := metadata.ValidateTags(tags, metadata.MaxObjectTags); err != nil {
+		attrs.issues = append(attrs.issues, "tags not copied: "+err.Error())
+		return
+	}
+	attrs.tags = tags
+}
+
+// verifyChecksum 校验写入的内容。源端 ETag 为内容 MD5 时要求与写入时计算的 MD5 一致；
+// 分片上传或 KMS/SSE-C 加密对象的 ETag 不是 MD5，只能校验大小
+func verifyChecksum(sourceETag string, size int64, header http.Header, info *storage.ObjectInfo) (string, error) {
+	if info.Size != size {
+		return "", fmt.Errorf("size mismatch: source %d bytes, written %d bytes", size, info.Size)
+	}
+	sse := header.Get("x-amz-server-side-encryption")
+	if strings.Contains(sourceETag, "-") || sse == "aws:kms" || sse == "aws:kms:dsse" ||
+		header.Get("x-amz-server-side-encryption-customer-algorithm") != "" {
+		return metadata.MigrationChecksumSizeOnly, nil
+	}
+	if !strings.EqualFold(sourceETag, info.ETag) {
+		return "", fmt.Errorf("checksum mismatch: source ETag %s, written MD5 %s", sourceETag, info.ETag)
+	}
+	return metadata.MigrationChecksumVerified, nil
+}
+
+// saveReport 保存对象迁移报告，失败时只记录日志
+func (r *runner) saveReport(ctx context.Context, o *metadata.MigrationObject) {
+	o.JobID = r.job.ID
+	if err := r.m.repo.UpsertMigrationObject(ctx, o); err != nil {
+		logger.Warnf("Migration job %s: failed to save report for %s/%s: %v", r.job.ID, o.SourceBucket, o.ObjectKey, err)
+	}
+}
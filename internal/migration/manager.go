@@ -0,0 +1,183 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+)
+
+const (
+	// heartbeatInterval 运行中任务刷新心跳的间隔，同时用于发现其他实例上的取消操作
+	heartbeatInterval = 10 * time.Second
+	// staleAfter 心跳超过该时长未刷新的任务视为所在实例已退出，可被接管
+	staleAfter = time.Minute
+	// claimInterval 扫描可接管任务的间隔
+	claimInterval = time.Minute
+)
+
+// ErrNotStarted 迁移管理器尚未启动
+var ErrNotStarted = errors.New("migration manager is not started")
+
+// Manager 调度迁移任务。任务及进度保存在数据库中，实例重启或崩溃后由任意实例从最后完成的 Key 继续
+type Manager struct {
+	storage storage.Engine
+	repo    metadata.Repository
+	region  string
+	opts    Options
+
+	mu      sync.Mutex
+	ctx     context.Context
+	running map[string]context.CancelFunc
+}
+
+// NewManager 创建迁移管理器
+func NewManager(storage storage.Engine, repo metadata.Repository, region string, opts Options) *Manager {
+	return &Manager{
+		storage: storage,
+		repo:    repo,
+		region:  region,
+		opts:    opts.withDefaults(),
+		running: make(map[string]context.CancelFunc),
+	}
+}
+
+// Start 接管未完成的任务，并定期检查其他实例遗留的任务。ctx 取消后所有任务停止，保持运行状态等待接管
+func (m *Manager) Start(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	go func() {
+		m.claimStale(ctx)
+		ticker := time.NewTicker(claimInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.claimStale(ctx)
+			}
+		}
+	}()
+}
+
+func (m *Manager) claimStale(ctx context.Context) {
+	jobs, err := m.repo.ClaimStaleMigrationJobs(ctx, time.Now().Add(-staleAfter))
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warnf("Failed to claim migration jobs: %v", err)
+		}
+		return
+	}
+	for i := range jobs {
+		logger.Infof("Resuming migration job %s from %s", jobs[i].ID, jobs[i].SourceEndpoint)
+		m.launch(&jobs[i])
+	}
+}
+
+// Submit 保存并启动新任务
+func (m *Manager) Submit(ctx context.Context, job *metadata.MigrationJob) error {
+	if !m.started() {
+		return ErrNotStarted
+	}
+	job.Status = metadata.MigrationStatusPending
+	if err := m.repo.CreateMigrationJob(ctx, job); err != nil {
+		return err
+	}
+	m.launch(job)
+	return nil
+}
+
+// Cancel 取消任务，返回任务此前是否处于未结束状态。任务在其他实例上运行时由其心跳发现并停止
+func (m *Manager) Cancel(ctx context.Context, id string) (bool, error) {
+	ok, err := m.repo.FinishMigrationJob(ctx, id, metadata.MigrationStatusCancelled, "")
+	if err != nil || !ok {
+		return ok, err
+	}
+	m.mu.Lock()
+	if cancel, found := m.running[id]; found {
+		cancel()
+	}
+	m.mu.Unlock()
+	return true, nil
+}
+
+// Resume 从最后完成的 Key 继续失败或已取消的任务，返回任务是否可恢复
+func (m *Manager) Resume(ctx context.Context, id string) (bool, error) {
+	if !m.started() {
+		return false, ErrNotStarted
+	}
+	ok, err := m.repo.ResumeMigrationJob(ctx, id)
+	if err != nil || !ok {
+		return ok, err
+	}
+	job, err := m.repo.GetMigrationJob(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+	m.launch(job)
+	return true, nil
+}
+
+func (m *Manager) started() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ctx != nil
+}
+
+// launch 在后台运行任务，同一实例上同一任务只运行一份
+func (m *Manager) launch(job *metadata.MigrationJob) {
+	m.mu.Lock()
+	if _, found := m.running[job.ID]; found {
+		m.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.running[job.ID] = cancel
+	m.mu.Unlock()
+
+	go func() {
+		defer func() {
+			cancel()
+			m.mu.Lock()
+			delete(m.running, job.ID)
+			m.mu.Unlock()
+		}()
+		go m.heartbeat(ctx, cancel, job.ID)
+		newRunner(m, job).run(ctx)
+	}()
+}
+
+// heartbeat 定期刷新心跳，任务已不在运行状态（被取消）时停止本地执行
+func (m *Manager) heartbeat(ctx context.Context, cancel context.CancelFunc, id string) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			running, err := m.repo.HeartbeatMigrationJob(ctx, id)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Warnf("Failed to update heartbeat of migration job %s: %v", id, err)
+				}
+				continue
+			}
+			if !running {
+				logger.Infof("Migration job %s is no longer running, stopping", id)
+				cancel()
+				return
+			}
+		}
+	}
+}
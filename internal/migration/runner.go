@@ -0,0 +1,510 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/logger"
+)
+
+const (
+	// pageSize 每页列举的对象数，每页完成后保存一次进度
+	pageSize = 1000
+	// persistTimeout 任务停止后保存最终状态的超时
+	persistTimeout = 10 * time.Second
+)
+
+// runner 执行单个迁移任务
+type runner struct {
+	m    *Manager
+	job  *metadata.MigrationJob
+	opts Options
+	bw   *bandwidth
+	// tagsUnsupported 源端不支持对象标签接口，之后不再请求
+	tagsUnsupported atomic.Bool
+}
+
+func newRunner(m *Manager, job *metadata.MigrationJob) *runner {
+	opts := m.opts.forJob(job)
+	return &runner{m: m, job: job, opts: opts, bw: newBandwidth(opts.BytesPerSecond)}
+}
+
+func (r *runner) run(ctx context.Context) {
+	job := r.job
+	repo := r.m.repo
+
+	ok, err := repo.StartMigrationJob(ctx, job.ID)
+	if err != nil {
+		logger.Errorf("Failed to start migration job %s: %v", job.ID, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	logger.Infof("Migration job %s started from %s", job.ID, job.SourceEndpoint)
+
+	client, err := NewClient(job.SourceEndpoint, job.AccessKey, job.SecretKey, job.SourceRegion)
+	if err != nil {
+		r.finish(ctx, metadata.MigrationStatusFailed, err.Error())
+		return
+	}
+
+	all, err := client.ListBuckets(ctx)
+	if err != nil {
+		r.finish(ctx, metadata.MigrationStatusFailed, "failed to list source buckets: "+err.Error())
+		return
+	}
+	var buckets []SourceBucket
+	for _, b := range all {
+		if job.Filters.Selects(b.Name) {
+			buckets = append(buckets, b)
+		}
+	}
+	// 改名后的目标可能与另一个未改名的源 Bucket 同名，两者会被合并到同一个 Bucket
+	targets := make(map[string]string, len(buckets))
+	for _, b := range buckets {
+		target := job.Filters.TargetBucket(b.Name)
+		if other, ok := targets[target]; ok {
+			r.finish(ctx, metadata.MigrationStatusFailed,
+				fmt.Sprintf("source buckets %s and %s are both migrated to %s", other, b.Name, target))
+			return
+		}
+		targets[target] = b.Name
+	}
+	if err := repo.SetMigrationJobTotal(ctx, job.ID, len(buckets)); err != nil {
+		logger.Warnf("Failed to save bucket count of migration job %s: %v", job.ID, err)
+	}
+
+	existing, err := repo.ListMigrationBuckets(ctx, job.ID)
+	if err != nil {
+		r.finish(ctx, metadata.MigrationStatusFailed, "failed to load progress: "+err.Error())
+		return
+	}
+	progress := make(map[string]*metadata.MigrationBucket, len(existing))
+	for i := range existing {
+		progress[existing[i].SourceBucket] = &existing[i]
+	}
+
+	var failedBuckets int
+	var failedObjects int64
+	for _, b := range buckets {
+		p, found := progress[b.Name]
+		if !found {
+			p = &metadata.MigrationBucket{
+				JobID:        job.ID,
+				SourceBucket: b.Name,
+				TargetBucket: job.Filters.TargetBucket(b.Name),
+				Status:       metadata.MigrationStatusPending,
+			}
+		}
+		if p.Status != metadata.MigrationStatusCompleted {
+			migrate := r.migrateBucket
+			if job.DryRun {
+				migrate = r.scanBucket
+			}
+			if err := migrate(ctx, client, p); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				logger.Errorf("Migration job %s: bucket %s failed: %v", job.ID, b.Name, err)
+				failedBuckets++
+				p.Status = metadata.MigrationStatusFailed
+				p.Error = err.Error()
+				r.saveBucket(ctx, p)
+				r.recordError(ctx, b.Name, "", err)
+			}
+		}
+		failedObjects += p.FailedObjects
+	}
+
+	if ctx.Err() != nil {
+		// 被取消时状态已由 Cancel 更新；实例退出时保持运行状态，由心跳超时后接管
+		logger.Infof("Migration job %s stopped", job.ID)
+		return
+	}
+
+	var errMsg string
+	if failedBuckets > 0 || failedObjects > 0 {
+		errMsg = fmt.Sprintf("%d buckets and %d objects failed to migrate", failedBuckets, failedObjects)
+	}
+	if job.SyncInterval != "" {
+		r.schedule(ctx, errMsg)
+		return
+	}
+	if errMsg != "" {
+		r.finish(ctx, metadata.MigrationStatusFailed, errMsg)
+		return
+	}
+	r.finish(ctx, metadata.MigrationStatusCompleted, "")
+}
+
+// pageBatch 一页对象的迁移结果，按列举顺序提交以保证 LastKey 之前的对象均已处理
+type pageBatch struct {
+	lastKey     string
+	listed      int64
+	listedBytes int64
+	pending     sync.WaitGroup
+
+	mu        sync.Mutex
+	completed int64
+	failed    int64
+	skipped   int64
+	deleted   int64
+	bytes     int64
+}
+
+func (b *pageBatch) add(completed, failed, skipped, bytes int64) {
+	b.mu.Lock()
+	b.completed += completed
+	b.failed += failed
+	b.skipped += skipped
+	b.bytes += bytes
+	b.mu.Unlock()
+}
+
+// objectTask 待迁移的对象，existing 为同名的本地对象，同步模式下列举时填入
+type objectTask struct {
+	batch    *pageBatch
+	obj      SourceObject
+	existing *metadata.Object
+}
+
+// maxPagesInFlight 同时处理中的页数上限，限制列举超前于迁移的程度
+const maxPagesInFlight = 4
+
+// migrateBucket 从 LastKey 之后继续迁移 Bucket。对象由任务级并发的工作协程处理，
+// 各页按列举顺序在全部对象处理完后提交进度
+func (r *runner) migrateBucket(ctx context.Context, client *Client, p *metadata.MigrationBucket) error {
+	if err := r.checkWritable(p.TargetBucket); err != nil {
+		return err
+	}
+	target, err := r.ensureBucket(ctx, p.TargetBucket)
+	if err != nil {
+		return err
+	}
+	// 每轮迁移开始时复制一次 Bucket 配置，中途恢复时不再重复
+	if len(p.Settings) == 0 {
+		r.copyBucketSettings(ctx, client, p, target)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	p.Status = metadata.MigrationStatusRunning
+	p.Error = ""
+	r.saveBucket(ctx, p)
+
+	tasks := make(chan objectTask, r.opts.Concurrency)
+	batches := make(chan *pageBatch, maxPagesInFlight)
+
+	var workers sync.WaitGroup
+	for i := 0; i < r.opts.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for t := range tasks {
+				r.processObject(ctx, client, p.SourceBucket, target, t)
+				t.batch.pending.Done()
+			}
+		}()
+	}
+
+	committed := make(chan struct{})
+	go func() {
+		defer close(committed)
+		for b := range batches {
+			b.pending.Wait()
+			// 中途停止时不推进进度，恢复后重新迁移该页（对象写入可重复执行）
+			if ctx.Err() != nil {
+				continue
+			}
+			p.ListedObjects += b.listed
+			p.ListedBytes += b.listedBytes
+			p.CompletedObjects += b.completed
+			p.FailedObjects += b.failed
+			p.SkippedObjects += b.skipped
+			p.DeletedObjects += b.deleted
+			p.CompletedBytes += b.bytes
+			p.LastKey = b.lastKey
+			r.saveBucket(ctx, p)
+		}
+	}()
+
+	listErr := r.listBucket(ctx, client, p.SourceBucket, r.job.Filters.Prefix(p.SourceBucket), p.LastKey, target, tasks, batches)
+	close(tasks)
+	close(batches)
+	workers.Wait()
+	<-committed
+
+	if listErr != nil {
+		return listErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.Status = metadata.MigrationStatusCompleted
+	r.saveBucket(ctx, p)
+	return nil
+}
+
+// listPage 列举一页对象，失败时按退避策略重试
+func (r *runner) listPage(ctx context.Context, client *Client, bucket, prefix, after, token string) (*ObjectPage, error) {
+	var page *ObjectPage
+	err := r.retry(ctx, "list "+bucket, func() (err error) {
+		page, err = client.ListObjects(ctx, bucket, prefix, after, token, pageSize)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	if len(page.Objects) == 0 && page.IsTruncated {
+		return nil, fmt.Errorf("source returned an empty truncated listing after %q", after)
+	}
+	return page, nil
+}
+
+// newPageBatch 创建一页的结果记录，空页保持原有进度
+func newPageBatch(page *ObjectPage, after string) *pageBatch {
+	batch := &pageBatch{listed: int64(len(page.Objects)), lastKey: after}
+	for _, obj := range page.Objects {
+		batch.listedBytes += obj.Size
+	}
+	if len(page.Objects) > 0 {
+		batch.lastKey = page.Objects[len(page.Objects)-1].Key
+	}
+	return batch
+}
+
+// listBucket 使用 continuation token 逐页列举 startAfter 之后的对象并分发给工作协程
+func (r *runner) listBucket(ctx context.Context, client *Client, bucket, prefix, startAfter string, target *metadata.Bucket, tasks chan<- objectTask, batches chan<- *pageBatch) error {
+	after, token := startAfter, ""
+	for {
+		// 迁移过程中开启只读模式时停止列举，Bucket 记为失败，之后从 LastKey 继续
+		if err := r.checkWritable(target.Name); err != nil {
+			return err
+		}
+		page, err := r.listPage(ctx, client, bucket, prefix, after, token)
+		if err != nil {
+			return err
+		}
+		batch := newPageBatch(page, after)
+
+		// 同步模式下与本页 Key 范围内的本地对象比较，最后一页覆盖到末尾以发现源端已删除的对象
+		var local map[string]*metadata.Object
+		if r.job.Mode == metadata.MigrationModeSync {
+			through := ""
+			if page.IsTruncated {
+				through = batch.lastKey
+			}
+			local, err = r.localObjects(ctx, target, bucket, prefix, batch, after, through, page.Objects)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return fmt.Errorf("failed to list local objects: %w", err)
+			}
+		}
+
+		batch.pending.Add(len(page.Objects))
+		batches <- batch
+		for i, obj := range page.Objects {
+			select {
+			case tasks <- objectTask{batch: batch, obj: obj, existing: local[obj.Key]}:
+			case <-ctx.Done():
+				batch.pending.Add(i - len(page.Objects))
+				return ctx.Err()
+			}
+		}
+
+		if !page.IsTruncated {
+			return nil
+		}
+		// 不支持 continuation token 的源端改用 start-after 继续
+		after, token = batch.lastKey, page.NextContinuationToken
+	}
+}
+
+// scanBucket 预演：只列举源对象并统计数量和字节数，不创建 Bucket 也不写入数据
+func (r *runner) scanBucket(ctx context.Context, client *Client, p *metadata.MigrationBucket) error {
+	p.Status = metadata.MigrationStatusRunning
+	p.Error = ""
+	r.saveBucket(ctx, p)
+
+	prefix := r.job.Filters.Prefix(p.SourceBucket)
+	token := ""
+	for {
+		page, err := r.listPage(ctx, client, p.SourceBucket, prefix, p.LastKey, token)
+		if err != nil {
+			return err
+		}
+		batch := newPageBatch(page, p.LastKey)
+		p.ListedObjects += batch.listed
+		p.ListedBytes += batch.listedBytes
+		p.LastKey = batch.lastKey
+		r.saveBucket(ctx, p)
+
+		if !page.IsTruncated {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+
+	p.Status = metadata.MigrationStatusCompleted
+	r.saveBucket(ctx, p)
+	return nil
+}
+
+// processObject 迁移单个对象并记录结果，同步模式下跳过未变化的对象。
+// 任何模式下都不覆盖受 Object Lock 保护的同名对象
+func (r *runner) processObject(ctx context.Context, client *Client, sourceBucket string, target *metadata.Bucket, t objectTask) {
+	if ctx.Err() != nil {
+		return
+	}
+	// 同步模式已列举本地对象，其他模式需要单独查询
+	if t.existing == nil && r.job.Mode != metadata.MigrationModeSync {
+		existing, err := r.m.repo.GetObject(ctx, target.ID, t.obj.Key)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			t.batch.add(0, 1, 0, 0)
+			r.recordError(ctx, sourceBucket, t.obj.Key, fmt.Errorf("failed to check existing object: %w", err))
+			return
+		}
+		t.existing = existing
+	}
+	if t.existing != nil {
+		if r.job.Mode == metadata.MigrationModeSync && unchanged(t.existing, t.obj) {
+			t.batch.add(0, 0, 1, 0)
+			return
+		}
+		if err := t.existing.CheckRemovable(time.Now(), false); err != nil {
+			t.batch.add(0, 1, 0, 0)
+			r.recordError(ctx, sourceBucket, t.obj.Key, fmt.Errorf("failed to overwrite existing object: %w", err))
+			return
+		}
+	}
+
+	if err := r.checkWritable(target.Name); err != nil {
+		t.batch.add(0, 1, 0, 0)
+		r.recordError(ctx, sourceBucket, t.obj.Key, err)
+		return
+	}
+	size, err := r.migrateObject(ctx, client, sourceBucket, target, t.obj)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		t.batch.add(0, 1, 0, 0)
+		r.recordError(ctx, sourceBucket, t.obj.Key, err)
+		return
+	}
+	t.batch.add(1, 0, 0, size)
+}
+
+// checkWritable 目标 Bucket 处于只读模式时拒绝写入
+func (r *runner) checkWritable(bucket string) error {
+	if r.opts.Writable == nil {
+		return nil
+	}
+	if err := r.opts.Writable(bucket); err != nil {
+		return fmt.Errorf("target bucket %s is not writable: %w", bucket, err)
+	}
+	return nil
+}
+
+// ensureBucket 获取目标 Bucket，不存在时创建并归属任务所有者。
+// 同名 Bucket 属于其他用户时拒绝写入，需通过 BucketMapping 改名
+func (r *runner) ensureBucket(ctx context.Context, name string) (*metadata.Bucket, error) {
+	existing, err := r.m.repo.GetBucketByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket: %w", err)
+	}
+	if existing != nil {
+		if existing.OwnerID != r.job.OwnerID {
+			return nil, fmt.Errorf("target bucket %s already exists and belongs to another user, map it to a different name", name)
+		}
+		return existing, nil
+	}
+
+	if err := r.m.storage.CreateBucket(ctx, name); err != nil {
+		return nil, fmt.Errorf("failed to create bucket: %w", err)
+	}
+	bucket := &metadata.Bucket{
+		Name:    name,
+		OwnerID: r.job.OwnerID,
+		Region:  r.m.region,
+		ACL:     "private",
+	}
+	if err := r.m.repo.CreateBucket(ctx, bucket); err != nil {
+		return nil, fmt.Errorf("failed to save bucket metadata: %w", err)
+	}
+	return bucket, nil
+}
+
+// saveBucket 保存 Bucket 进度，任务停止后仍写入最终状态
+func (r *runner) saveBucket(ctx context.Context, p *metadata.MigrationBucket) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+	defer cancel()
+	if err := r.m.repo.UpsertMigrationBucket(ctx, p); err != nil {
+		logger.Warnf("Failed to save progress of migration job %s bucket %s: %v", r.job.ID, p.SourceBucket, err)
+	}
+}
+
+func (r *runner) recordError(ctx context.Context, bucket, key string, err error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+	defer cancel()
+	if key != "" {
+		logger.Warnf("Migration job %s: failed to migrate %s/%s: %v", r.job.ID, bucket, key, err)
+	}
+	if err := r.m.repo.AddMigrationError(ctx, &metadata.MigrationError{
+		JobID:        r.job.ID,
+		SourceBucket: bucket,
+		ObjectKey:    key,
+		Error:        err.Error(),
+	}); err != nil {
+		logger.Warnf("Failed to record error of migration job %s: %v", r.job.ID, err)
+	}
+}
+
+func (r *runner) finish(ctx context.Context, status, errMsg string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+	defer cancel()
+	if _, err := r.m.repo.FinishMigrationJob(ctx, r.job.ID, status, errMsg); err != nil {
+		logger.Errorf("Failed to finish migration job %s: %v", r.job.ID, err)
+		return
+	}
+	if errMsg != "" {
+		logger.Warnf("Migration job %s %s: %s", r.job.ID, status, errMsg)
+		return
+	}
+	logger.Infof("Migration job %s %s", r.job.ID, status)
+}
+
+// schedule 结束本轮同步并安排下一轮，本轮的错误保留在任务上
+func (r *runner) schedule(ctx context.Context, errMsg string) {
+	interval, err := ParseSyncInterval(r.job.SyncInterval)
+	if err != nil {
+		r.finish(ctx, metadata.MigrationStatusFailed, err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+	defer cancel()
+	nextRun := time.Now().Add(interval)
+	if _, err := r.m.repo.ScheduleMigrationJob(ctx, r.job.ID, nextRun, errMsg); err != nil {
+		logger.Errorf("Failed to schedule migration job %s: %v", r.job.ID, err)
+		return
+	}
+	if errMsg != "" {
+		logger.Warnf("Migration job %s sync pass finished with errors, next run at %s: %s", r.job.ID, nextRun.Format(time.RFC3339), errMsg)
+		return
+	}
+	logger.Infof("Migration job %s sync pass finished, next run at %s", r.job.ID, nextRun.Format(time.RFC3339))
+}
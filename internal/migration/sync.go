@@ -0,0 +1,90 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/util"
+)
+
+// localObjects 读取以 prefix 开头且 after < key <= through 的本地对象（through 为空表示不设上限），返回与本页源对象同名的对象。
+// 开启 DeleteMissing 时删除范围内源端已不存在的对象，结果计入 batch
+func (r *runner) localObjects(ctx context.Context, target *metadata.Bucket, sourceBucket, prefix string, batch *pageBatch, after, through string, page []SourceObject) (map[string]*metadata.Object, error) {
+	source := make(map[string]bool, len(page))
+	for _, obj := range page {
+		source[obj.Key] = true
+	}
+
+	local := make(map[string]*metadata.Object)
+	cursor := after
+	for {
+		objects, err := r.m.repo.ListObjectsInKeyRange(ctx, target.ID, prefix, cursor, through, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range objects {
+			obj := &objects[i]
+			if source[obj.Key] {
+				// 多版本时按更新时间排序，保留最新的版本
+				local[obj.Key] = obj
+				continue
+			}
+			if r.job.DeleteMissing && (i == 0 || objects[i-1].Key != obj.Key) {
+				if err := r.deleteLocal(ctx, target, obj); err != nil {
+					if ctx.Err() != nil {
+						return nil, ctx.Err()
+					}
+					batch.failed++
+					r.recordError(ctx, sourceBucket, obj.Key, err)
+					continue
+				}
+				batch.deleted++
+			}
+		}
+		if len(objects) < pageSize {
+			return local, nil
+		}
+		cursor = objects[len(objects)-1].Key
+	}
+}
+
+// deleteLocal 删除源端已不存在的本地对象，受 Object Lock 保护的对象不删除
+func (r *runner) deleteLocal(ctx context.Context, target *metadata.Bucket, obj *metadata.Object) error {
+	if err := obj.CheckRemovable(time.Now(), false); err != nil {
+		return fmt.Errorf("failed to delete object missing at source: %w", err)
+	}
+	// 忽略存储中不存在的错误，以元数据为准
+	r.m.storage.Delete(ctx, target.Name, obj.Key)
+	if err := r.m.repo.DeleteObject(ctx, target.ID, obj.Key); err != nil {
+		return fmt.Errorf("failed to delete object missing at source: %w", err)
+	}
+	return nil
+}
+
+// unchanged 判断本地对象与源对象是否一致：比较大小和 ETag。
+// 分片上传的 ETag 不是内容的 MD5，此时改为比较源对象修改时间是否早于本地写入时间
+func unchanged(local *metadata.Object, src SourceObject) bool {
+	if local.Size != src.Size || local.IsCorrupt() {
+		return false
+	}
+	if !strings.Contains(src.ETag, "-") {
+		return strings.Trim(local.ETag, "\"") == src.ETag
+	}
+	modified, err := time.Parse(time.RFC3339, src.LastModified)
+	return err == nil && !modified.After(local.UpdatedAt)
+}
+
+// ParseSyncInterval 解析重复同步间隔，至少为 1 分钟
+func ParseSyncInterval(v string) (time.Duration, error) {
+	d, err := util.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sync interval %q: %w", v, err)
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("sync interval %q must be at least 1m", v)
+	}
+	return d, nil
+}
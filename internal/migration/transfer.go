@@ -0,0 +1,392 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/ratelimit"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// maxRetryDelay 重试等待时间上限
+const maxRetryDelay = time.Minute
+
+// Options 迁移传输参数
+type Options struct {
+	Concurrency        int   // 每个任务并发迁移的对象数
+	MultipartThreshold int64 // 超过该大小的对象按范围分片下载
+	PartSize           int64
+	PartConcurrency    int // 单个对象并发下载的分片数
+	MaxRetries         int // 对象或分片失败后的重试次数，0 表示使用默认值 5
+	RetryBaseDelay     time.Duration
+	BytesPerSecond     int64 // 每个任务的带宽上限，0 表示不限制
+	// Writable 检查目标 Bucket 能否写入（如只读模式），为 nil 时不检查
+	Writable func(bucket string) error
+}
+
+// withDefaults 补全未设置的参数
+func (o Options) withDefaults() Options {
+	if o.Concurrency <= 0 {
+		o.Concurrency = 16
+	}
+	if o.PartSize <= 0 {
+		o.PartSize = 64 << 20
+	}
+	if o.MultipartThreshold <= 0 {
+		o.MultipartThreshold = 2 * o.PartSize
+	}
+	if o.PartConcurrency <= 0 {
+		o.PartConcurrency = 4
+	}
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = 5
+	}
+	if o.RetryBaseDelay <= 0 {
+		o.RetryBaseDelay = time.Second
+	}
+	return o
+}
+
+// forJob 应用任务单独指定的并发数和带宽上限
+func (o Options) forJob(job *metadata.MigrationJob) Options {
+	if job.Concurrency > 0 {
+		o.Concurrency = job.Concurrency
+	}
+	if job.BytesPerSecond > 0 {
+		o.BytesPerSecond = job.BytesPerSecond
+	}
+	return o
+}
+
+// retryable 源端明确拒绝的请求（4xx，超时和限流除外）和不支持的接口不重试
+func retryable(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		switch {
+		case unsupported(err):
+			return false
+		case respErr.StatusCode == http.StatusRequestTimeout, respErr.StatusCode == http.StatusTooManyRequests:
+			return true
+		case respErr.StatusCode >= 400 && respErr.StatusCode < 500:
+			return false
+		}
+	}
+	return true
+}
+
+// retry 执行 fn，失败时按指数退避（带随机抖动）重试
+func (r *runner) retry(ctx context.Context, what string, fn func() error) error {
+	delay := r.opts.RetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || ctx.Err() != nil || !retryable(err) || attempt >= r.opts.MaxRetries {
+			return err
+		}
+
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay)))
+		logger.Debugf("Migration job %s: %s failed (attempt %d), retrying in %s: %v", r.job.ID, what, attempt+1, wait, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// bandwidth 任务内所有并发传输共享的带宽上限
+type bandwidth struct {
+	store *ratelimit.MemoryStore
+	rate  int64
+}
+
+func newBandwidth(rate int64) *bandwidth {
+	if rate <= 0 {
+		return nil
+	}
+	return &bandwidth{store: ratelimit.NewMemoryStore(), rate: rate}
+}
+
+// reader 返回按任务带宽限速的 reader，未限速时原样返回
+func (b *bandwidth) reader(ctx context.Context, r io.Reader) io.Reader {
+	if b == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, bw: b, reader: r}
+}
+
+type throttledReader struct {
+	ctx    context.Context
+	bw     *bandwidth
+	reader io.Reader
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	// 单次读取不超过 1/10 秒的配额，使限速更平滑
+	if max := t.bw.rate / 10; max > 0 && int64(len(p)) > max {
+		p = p[:max]
+	}
+	n, err := t.reader.Read(p)
+	if n > 0 {
+		_, wait, _ := t.bw.store.Take(t.ctx, "migration", float64(t.bw.rate), t.bw.rate, int64(n), true)
+		if wait > 0 {
+			select {
+			case <-t.ctx.Done():
+				return n, t.ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+	}
+	return n, err
+}
+
+// sourceCopy 写入完成的对象：本地写入结果、源端响应 Header、源端 ETag 和校验结果
+type sourceCopy struct {
+	info     *storage.ObjectInfo
+	header   http.Header
+	etag     string
+	checksum string
+}
+
+// migrateObject 将源对象连同元数据和标签写入目标 Bucket，校验内容并保存迁移报告，返回写入的字节数。
+// 大对象按范围分片并发下载
+func (r *runner) migrateObject(ctx context.Context, client *Client, sourceBucket string, target *metadata.Bucket, obj SourceObject) (int64, error) {
+	var (
+		src *sourceCopy
+		err error
+	)
+	if obj.Size >= r.opts.MultipartThreshold {
+		src, err = r.copyMultipart(ctx, client, sourceBucket, target, obj)
+	} else {
+		err = r.retry(ctx, "copy "+obj.Key, func() error {
+			src, err = r.copySingle(ctx, client, sourceBucket, target, obj)
+			return err
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	attrs := attributesFromHeader(src.header)
+	r.fetchTags(ctx, client, sourceBucket, obj.Key, src.header, attrs)
+
+	object := &metadata.Object{
+		BucketID:     target.ID,
+		Key:          obj.Key,
+		Size:         src.info.Size,
+		ETag:         src.info.ETag,
+		ContentType:  attrs.contentType,
+		StorageClass: "STANDARD",
+		StoragePath:  src.info.StoragePath,
+		Metadata:     attrs.metadata,
+	}
+	if err := r.m.repo.CreateObject(ctx, object); err != nil {
+		return 0, fmt.Errorf("failed to save object metadata: %w", err)
+	}
+	// 覆盖写入时替换旧标签
+	if err := r.m.repo.SetObjectTags(ctx, object.ID, attrs.tags); err != nil {
+		return 0, fmt.Errorf("failed to save object tags: %w", err)
+	}
+
+	r.saveReport(ctx, &metadata.MigrationObject{
+		SourceBucket:  sourceBucket,
+		ObjectKey:     obj.Key,
+		Size:          src.info.Size,
+		SourceETag:    src.etag,
+		TargetETag:    src.info.ETag,
+		Checksum:      src.checksum,
+		MetadataCount: len(attrs.metadata),
+		TagCount:      len(attrs.tags),
+		Issues:        strings.Join(attrs.issues, "; "),
+	})
+	return src.info.Size, nil
+}
+
+// sourceETag 优先使用下载时响应中的 ETag，与实际写入的内容对应
+func sourceETag(header http.Header, listed string) string {
+	if etag := strings.Trim(header.Get("ETag"), "\""); etag != "" {
+		return etag
+	}
+	return listed
+}
+
+// stagingKey 迁移写入使用的暂存 Key，与目标对象在同一目录下。以 .tmp- 开头，
+// 进程中断时残留的文件会被 fsck 识别为临时文件
+func stagingKey(key string) string {
+	name := ".tmp-migration-" + uuid.New().String()
+	// 以 / 结尾的目录占位对象暂存在上一级目录
+	if i := strings.LastIndex(strings.TrimRight(key, "/"), "/"); i >= 0 {
+		return key[:i+1] + name
+	}
+	return name
+}
+
+// promote 将校验通过的暂存对象移动到正式 Key，在此之前同名的旧对象保持不变
+func (r *runner) promote(ctx context.Context, target *metadata.Bucket, staging, key string, info *storage.ObjectInfo) error {
+	moved, err := r.m.storage.Rename(ctx, target.Name, staging, key)
+	if err != nil {
+		r.discardStaging(ctx, target, staging)
+		return fmt.Errorf("failed to promote object: %w", err)
+	}
+	info.Key = key
+	info.StoragePath = moved.StoragePath
+	return nil
+}
+
+// discardStaging 删除写入失败或校验失败的暂存对象
+func (r *runner) discardStaging(ctx context.Context, target *metadata.Bucket, staging string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+	defer cancel()
+	if err := r.m.storage.Delete(ctx, target.Name, staging); err != nil {
+		logger.Warnf("Migration job %s: failed to remove staged object %s: %v", r.job.ID, staging, err)
+	}
+}
+
+// copySingle 单个请求下载整个对象，写入暂存 Key 并校验内容后再替换目标对象
+func (r *runner) copySingle(ctx context.Context, client *Client, sourceBucket string, target *metadata.Bucket, obj SourceObject) (*sourceCopy, error) {
+	resp, err := client.GetObject(ctx, sourceBucket, obj.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	size := obj.Size
+	if resp.ContentLength >= 0 {
+		size = resp.ContentLength
+	}
+	src := &sourceCopy{header: resp.Header, etag: sourceETag(resp.Header, obj.ETag)}
+
+	staging := stagingKey(obj.Key)
+	src.info, err = r.m.storage.Put(ctx, target.Name, staging, r.bw.reader(ctx, resp.Body), size, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to put object: %w", err)
+	}
+	if src.checksum, err = verifyChecksum(src.etag, size, resp.Header, src.info); err != nil {
+		r.discardStaging(ctx, target, staging)
+		return nil, err
+	}
+	if err := r.promote(ctx, target, staging, obj.Key, src.info); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// copyMultipart 先通过 HEAD 获取对象元数据，再按范围并发下载分片写入本地分片上传，任一分片重试后仍失败时中止上传。
+// 范围请求带 If-Match，源对象在迁移过程中被修改时失败而不是拼出不一致的内容。
+// 分片合并到暂存 Key，校验通过后再替换目标对象
+func (r *runner) copyMultipart(ctx context.Context, client *Client, sourceBucket string, target *metadata.Bucket, obj SourceObject) (*sourceCopy, error) {
+	var header http.Header
+	err := r.retry(ctx, "head "+obj.Key, func() (err error) {
+		header, err = client.HeadObject(ctx, sourceBucket, obj.Key)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to head source object: %w", err)
+	}
+	src := &sourceCopy{header: header, etag: sourceETag(header, obj.ETag)}
+	size := obj.Size
+	if n, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		size = n
+	}
+
+	staging := stagingKey(obj.Key)
+	uploadID := uuid.New().String()
+	if err := r.m.storage.InitMultipartUpload(ctx, target.Name, staging, uploadID); err != nil {
+		return nil, fmt.Errorf("failed to init multipart upload: %w", err)
+	}
+
+	partCount := int((size + r.opts.PartSize - 1) / r.opts.PartSize)
+	parts := make([]storage.PartInfo, partCount)
+
+	partCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, r.opts.PartConcurrency)
+	for i := 0; i < partCount; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-partCtx.Done():
+		}
+		if partCtx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := int64(i) * r.opts.PartSize
+			end := start + r.opts.PartSize - 1
+			if end >= size {
+				end = size - 1
+			}
+			err := r.retry(partCtx, fmt.Sprintf("part %d of %s", i+1, obj.Key), func() error {
+				resp, err := client.GetObjectRange(partCtx, sourceBucket, obj.Key, start, end, src.etag)
+				if err != nil {
+					return fmt.Errorf("failed to get part %d of source object: %w", i+1, err)
+				}
+				defer resp.Body.Close()
+				etag, err := r.m.storage.PutPart(partCtx, target.Name, staging, uploadID, i+1, r.bw.reader(partCtx, resp.Body), end-start+1)
+				if err != nil {
+					return fmt.Errorf("failed to put part %d: %w", i+1, err)
+				}
+				parts[i] = storage.PartInfo{PartNumber: i + 1, ETag: etag, Size: end - start + 1}
+				return nil
+			})
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	abort := func() {
+		abortCtx, abortCancel := context.WithTimeout(context.WithoutCancel(ctx), persistTimeout)
+		defer abortCancel()
+		if err := r.m.storage.AbortMultipartUpload(abortCtx, target.Name, staging, uploadID); err != nil {
+			logger.Warnf("Migration job %s: failed to abort multipart upload of %s: %v", r.job.ID, obj.Key, err)
+		}
+	}
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		abort()
+		return nil, firstErr
+	}
+
+	src.info, err = r.m.storage.CompleteParts(ctx, target.Name, staging, uploadID, parts)
+	if err != nil {
+		abort()
+		r.discardStaging(ctx, target, staging)
+		return nil, fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	if src.checksum, err = verifyChecksum(src.etag, size, header, src.info); err != nil {
+		r.discardStaging(ctx, target, staging)
+		return nil, err
+	}
+	if err := r.promote(ctx, target, staging, obj.Key, src.info); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
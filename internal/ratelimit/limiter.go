@@ -0,0 +1,198 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/gooss/server/internal/metrics"
+	"github.com/gooss/server/pkg/config"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// 限流维度
+const (
+	ScopeAccessKey = "access_key"
+	ScopeIP        = "ip"
+	ScopeBucket    = "bucket"
+)
+
+// 带宽方向
+const (
+	DirectionUpload   = "upload"
+	DirectionDownload = "download"
+)
+
+// Limiter 按 Access Key、来源 IP、Bucket 限制请求速率，并按用户限制上传/下载带宽
+type Limiter struct {
+	store Store
+	cfg   atomic.Pointer[config.LimitsConfig]
+}
+
+// New 创建限流器
+func New(store Store, cfg config.LimitsConfig) *Limiter {
+	l := &Limiter{store: store}
+	l.cfg.Store(&cfg)
+	return l
+}
+
+// Update 替换限速配置，用于配置热加载；存储后端不随之切换
+func (l *Limiter) Update(cfg config.LimitsConfig) {
+	l.cfg.Store(&cfg)
+}
+
+// Allow 检查指定维度的请求速率，存储出错时放行以免限流组件故障影响服务
+func (l *Limiter) Allow(ctx context.Context, scope, id string) bool {
+	rate := l.requestRate(scope)
+	if rate <= 0 || id == "" {
+		return true
+	}
+	burst := int64(l.cfg.Load().RateLimitBurst)
+	if burst <= 0 {
+		burst = int64(rate)
+	}
+
+	ok, _, err := l.store.Take(ctx, scope+":"+id, float64(rate), burst, 1, false)
+	if err != nil {
+		logger.Ctx(ctx).Warnf("Rate limit check failed for %s %s: %v", scope, id, err)
+		return true
+	}
+	if !ok {
+		metrics.RateLimitedRequests.WithLabelValues(scope).Inc()
+	}
+	return ok
+}
+
+func (l *Limiter) requestRate(scope string) int {
+	cfg := l.cfg.Load()
+	switch scope {
+	case ScopeAccessKey:
+		return cfg.RateLimitPerSecond
+	case ScopeIP:
+		return cfg.RateLimitPerIP
+	case ScopeBucket:
+		return cfg.RateLimitPerBucket
+	}
+	return 0
+}
+
+// bandwidth 返回指定方向的每用户带宽上限
+func (l *Limiter) bandwidth(direction string) int64 {
+	cfg := l.cfg.Load()
+	if direction == DirectionUpload {
+		return cfg.UploadBytesPerSecond
+	}
+	return cfg.DownloadBytesPerSecond
+}
+
+// Throttled 指定方向是否配置了带宽上限
+func (l *Limiter) Throttled(direction string) bool {
+	return l.bandwidth(direction) > 0
+}
+
+// chunkSize 单次预支的字节数上限，取 1/10 秒的配额使限速更平滑
+func chunkSize(rate int64) int {
+	if n := rate / 10; n > 0 {
+		return int(n)
+	}
+	return 1
+}
+
+// waitBytes 为用户预支 n 字节的带宽并等待
+func (l *Limiter) waitBytes(ctx context.Context, direction, user string, n int) error {
+	rate := l.bandwidth(direction)
+	if rate <= 0 || n <= 0 {
+		return nil
+	}
+
+	_, wait, err := l.store.Take(ctx, "bw:"+direction+":"+user, float64(rate), rate, int64(n), true)
+	if err != nil {
+		logger.Ctx(ctx).Warnf("Bandwidth limit check failed for %s: %v", user, err)
+		return nil
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	metrics.RateLimitThrottleSeconds.WithLabelValues(direction).Add(wait.Seconds())
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// LimitReader 返回按用户上传带宽限速的 reader
+func (l *Limiter) LimitReader(ctx context.Context, user string, r io.Reader) io.Reader {
+	if !l.Throttled(DirectionUpload) {
+		return r
+	}
+	return &limitedReader{ctx: ctx, limiter: l, user: user, reader: r}
+}
+
+// LimitWriter 返回按用户下载带宽限速的 writer
+func (l *Limiter) LimitWriter(ctx context.Context, user string, w io.Writer) io.Writer {
+	if !l.Throttled(DirectionDownload) {
+		return w
+	}
+	return &limitedWriter{ctx: ctx, limiter: l, user: user, writer: w}
+}
+
+type limitedReader struct {
+	ctx     context.Context
+	limiter *Limiter
+	user    string
+	reader  io.Reader
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	// 限速在传输过程中被热加载关闭时直接透传
+	rate := r.limiter.bandwidth(DirectionUpload)
+	if rate <= 0 {
+		return r.reader.Read(p)
+	}
+	if max := chunkSize(rate); len(p) > max {
+		p = p[:max]
+	}
+	n, err := r.reader.Read(p)
+	if waitErr := r.limiter.waitBytes(r.ctx, DirectionUpload, r.user, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
+
+type limitedWriter struct {
+	ctx     context.Context
+	limiter *Limiter
+	user    string
+	writer  io.Writer
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	rate := w.limiter.bandwidth(DirectionDownload)
+	if rate <= 0 {
+		return w.writer.Write(p)
+	}
+	written := 0
+	max := chunkSize(rate)
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > max {
+			chunk = chunk[:max]
+		}
+		if err := w.limiter.waitBytes(w.ctx, DirectionDownload, w.user, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.writer.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix Redis 中令牌桶的键前缀
+const keyPrefix = "oss:ratelimit:"
+
+// takeScript 在 Redis 中原子地执行令牌桶计算，使用 Redis 服务器时间以保证多副本一致。
+// 返回 {是否允许, 需要等待的毫秒数}
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local reserve = ARGV[4] == "1"
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local wait = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+elseif reserve then
+	tokens = tokens - n
+	allowed = 1
+	wait = math.ceil(-tokens * 1000 / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) * 1000 / rate) + 1000)
+return {allowed, wait}
+`)
+
+// RedisStore 基于 Redis 的令牌桶，多个副本共享限额
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore 创建 Redis 令牌桶存储
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Take 实现 Store
+func (s *RedisStore) Take(ctx context.Context, key string, rate float64, burst, n int64, reserve bool) (bool, time.Duration, error) {
+	reserveArg := "0"
+	if reserve {
+		reserveArg = "1"
+	}
+	res, err := takeScript.Run(ctx, s.client, []string{keyPrefix + key}, rate, burst, n, reserveArg).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
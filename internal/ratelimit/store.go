@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Store 令牌桶存储。rate 为每秒补充的令牌数，burst 为桶容量
+type Store interface {
+	// Take 尝试取走 n 个令牌：令牌足够时返回 (true, 0)。
+	// reserve 为 true 时令牌不足也会预支，返回需要等待的时长；为 false 时直接返回 false
+	Take(ctx context.Context, key string, rate float64, burst, n int64, reserve bool) (bool, time.Duration, error)
+}
+
+// idleTTL 令牌桶空闲多久后从内存中清除
+const idleTTL = 10 * time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore 单实例内存令牌桶
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore 创建内存令牌桶存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take 实现 Store
+func (s *MemoryStore) Take(_ context.Context, key string, rate float64, burst, n int64, reserve bool) (bool, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens >= float64(n) {
+		b.tokens -= float64(n)
+		return true, 0, nil
+	}
+	if !reserve {
+		return false, 0, nil
+	}
+	b.tokens -= float64(n)
+	return true, time.Duration(-b.tokens / rate * float64(time.Second)), nil
+}
+
+// sweep 定期清除已回满且空闲的令牌桶，避免按 IP 计数时内存无限增长
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > idleTTL {
+			delete(s.buckets, key)
+		}
+	}
+}
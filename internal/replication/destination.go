@@ -0,0 +1,253 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/migration"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// replicaHeader 复制写入的请求带该 Header，目标端为本服务时不会再向外复制
+const replicaHeader = "x-amz-replication-status"
+
+// errReadOnly 本地目标 Bucket 暂不可写入
+var errReadOnly = errors.New("destination bucket is read-only")
+
+const (
+	// multipartThreshold 超过该大小的对象以分片上传方式复制到远端
+	multipartThreshold = 128 << 20
+	defaultPartSize    = 64 << 20
+	maxParts           = 10000
+)
+
+// destination 复制目标
+type destination interface {
+	put(ctx context.Context, obj *metadata.Object, tags []metadata.Tag, reader io.Reader) error
+	delete(ctx context.Context, key string) error
+}
+
+// destination 根据目标 ARN 返回本地 Bucket 或远端目标
+func (r *Replicator) destination(ctx context.Context, arn string) (destination, error) {
+	localBucket, targetID, err := metadata.ParseReplicationDestination(arn)
+	if err != nil {
+		return nil, err
+	}
+	if localBucket != "" {
+		bucket, err := r.repo.GetBucketByName(ctx, localBucket)
+		if err != nil {
+			return nil, err
+		}
+		if bucket == nil {
+			return nil, fmt.Errorf("destination bucket %s does not exist", localBucket)
+		}
+		return &localDestination{storage: r.storage, repo: r.repo, bucket: bucket, writable: r.opts.Writable}, nil
+	}
+
+	target, err := r.repo.GetReplicationTarget(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("replication target %s does not exist", targetID)
+	}
+	client, err := migration.NewClient(target.Endpoint, target.AccessKey, target.SecretKey, target.Region)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteDestination{client: client, bucket: target.Bucket}, nil
+}
+
+// localDestination 本实例上的另一个 Bucket。副本不计入配额，目标 Bucket 开启了 Object Lock 时保留源对象的锁定设置
+type localDestination struct {
+	storage  storage.Engine
+	repo     metadata.Repository
+	bucket   *metadata.Bucket
+	writable func(bucket string) error
+}
+
+func (d *localDestination) put(ctx context.Context, obj *metadata.Object, tags []metadata.Tag, reader io.Reader) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	if err := d.checkRemovable(ctx, obj.Key); err != nil {
+		return err
+	}
+	info, err := d.storage.Put(ctx, d.bucket.Name, obj.Key, reader, obj.Size, obj.ContentType)
+	if err != nil {
+		return fmt.Errorf("failed to write replica: %w", err)
+	}
+
+	replica := &metadata.Object{
+		BucketID:          d.bucket.ID,
+		Key:               obj.Key,
+		Size:              info.Size,
+		ETag:              info.ETag,
+		ContentType:       obj.ContentType,
+		StorageClass:      "STANDARD",
+		StoragePath:       info.StoragePath,
+		Metadata:          metadata.ObjectMetadata(obj.Metadata),
+		ReplicationStatus: metadata.ReplicationStatusReplica,
+		UpdatedAt:         obj.UpdatedAt,
+	}
+	if d.bucket.ObjectLockEnabled {
+		replica.LockMode = obj.LockMode
+		replica.RetainUntil = obj.RetainUntil
+		replica.LegalHold = obj.LegalHold
+	}
+	if err := d.repo.CreateObject(ctx, replica); err != nil {
+		return fmt.Errorf("failed to save replica metadata: %w", err)
+	}
+	if err := d.repo.SetObjectTags(ctx, replica.ID, tags); err != nil {
+		return fmt.Errorf("failed to save replica tags: %w", err)
+	}
+	return verify(obj, info.Size, info.ETag)
+}
+
+func (d *localDestination) delete(ctx context.Context, key string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	if err := d.checkRemovable(ctx, key); err != nil {
+		return err
+	}
+	// 数据文件可能已不存在，以元数据删除结果为准
+	d.storage.Delete(ctx, d.bucket.Name, key)
+	return d.repo.DeleteObject(ctx, d.bucket.ID, key)
+}
+
+// checkWritable 目标 Bucket 处于只读模式时不写入
+func (d *localDestination) checkWritable() error {
+	if d.writable == nil {
+		return nil
+	}
+	if err := d.writable(d.bucket.Name); err != nil {
+		return fmt.Errorf("%w: %v", errReadOnly, err)
+	}
+	return nil
+}
+
+// checkRemovable 目标对象受 Object Lock 保护时不能覆盖或删除
+func (d *localDestination) checkRemovable(ctx context.Context, key string) error {
+	existing, err := d.repo.GetObject(ctx, d.bucket.ID, key)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return nil
+	}
+	return existing.CheckRemovable(time.Now(), false)
+}
+
+// remoteDestination S3 兼容服务上的 Bucket
+type remoteDestination struct {
+	client *migration.Client
+	bucket string
+}
+
+func (d *remoteDestination) put(ctx context.Context, obj *metadata.Object, tags []metadata.Tag, reader io.Reader) error {
+	header := http.Header{}
+	header.Set("Content-Type", obj.ContentType)
+	for k, v := range metadata.ObjectMetadata(obj.Metadata) {
+		header.Set(k, v)
+	}
+	if len(tags) > 0 {
+		values := url.Values{}
+		for _, tag := range tags {
+			values.Set(tag.Key, tag.Value)
+		}
+		header.Set("x-amz-tagging", values.Encode())
+	}
+	header.Set(replicaHeader, metadata.ReplicationStatusReplica)
+
+	if obj.Size > multipartThreshold {
+		return d.putMultipart(ctx, obj, reader, header)
+	}
+	etag, err := d.client.PutObject(ctx, d.bucket, obj.Key, reader, obj.Size, header)
+	if err != nil {
+		return err
+	}
+	return verify(obj, obj.Size, etag)
+}
+
+// putMultipart 按顺序分片上传大对象（单个 PUT 最大 5GB），失败时取消上传
+func (d *remoteDestination) putMultipart(ctx context.Context, obj *metadata.Object, reader io.Reader, header http.Header) error {
+	uploadID, err := d.client.CreateMultipartUpload(ctx, d.bucket, obj.Key, header)
+	if err != nil {
+		return fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	partSize := multipartPartSize(obj.Size)
+	var parts []migration.CompletedPart
+	for offset := int64(0); offset < obj.Size; offset += partSize {
+		size := partSize
+		if remaining := obj.Size - offset; remaining < size {
+			size = remaining
+		}
+		number := len(parts) + 1
+		etag, err := d.client.UploadPart(ctx, d.bucket, obj.Key, uploadID, number, io.LimitReader(reader, size), size)
+		if err != nil {
+			d.abort(ctx, obj.Key, uploadID)
+			return fmt.Errorf("failed to upload part %d: %w", number, err)
+		}
+		parts = append(parts, migration.CompletedPart{PartNumber: number, ETag: "\"" + etag + "\""})
+	}
+
+	// 目标为本服务时合并请求也需带副本标记
+	completeHeader := http.Header{}
+	completeHeader.Set(replicaHeader, metadata.ReplicationStatusReplica)
+	etag, err := d.client.CompleteMultipartUpload(ctx, d.bucket, obj.Key, uploadID, parts, completeHeader)
+	if err != nil {
+		d.abort(ctx, obj.Key, uploadID)
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return verify(obj, obj.Size, etag)
+}
+
+// abort 取消未完成的分片上传，失败时只记录日志，残留的分片由目标端的生命周期规则清理
+func (d *remoteDestination) abort(ctx context.Context, key, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if err := d.client.AbortMultipartUpload(ctx, d.bucket, key, uploadID); err != nil {
+		logger.Warnf("Failed to abort multipart upload of %s to %s: %v", key, d.bucket, err)
+	}
+}
+
+// multipartPartSize 分片大小，对象过大时增大分片以不超过 S3 的分片数上限
+func multipartPartSize(size int64) int64 {
+	partSize := int64(defaultPartSize)
+	if min := (size + maxParts - 1) / maxParts; min > partSize {
+		// 向上取整到 MB
+		partSize = (min + 1<<20 - 1) / (1 << 20) * (1 << 20)
+	}
+	return partSize
+}
+
+func (d *remoteDestination) delete(ctx context.Context, key string) error {
+	header := http.Header{}
+	header.Set(replicaHeader, metadata.ReplicationStatusReplica)
+	return d.client.DeleteObject(ctx, d.bucket, key, header)
+}
+
+// verify 校验写入目标的大小和 ETag。分片上传对象的 ETag 不是内容 MD5，只校验大小；
+// 不一致通常是复制期间源对象被覆盖，重试时会复制新内容
+func verify(obj *metadata.Object, size int64, etag string) error {
+	if size != obj.Size {
+		return fmt.Errorf("size mismatch: source %d bytes, written %d bytes", obj.Size, size)
+	}
+	if etag == "" || strings.Contains(obj.ETag, "-") || strings.Contains(etag, "-") {
+		return nil
+	}
+	if !strings.EqualFold(etag, obj.ETag) {
+		return fmt.Errorf("checksum mismatch: source ETag %s, written ETag %s", obj.ETag, etag)
+	}
+	return nil
+}
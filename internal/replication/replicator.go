@@ -0,0 +1,294 @@
+// Package replication 按 Bucket 复制规则将对象异步复制到本地 Bucket 或远端 S3 兼容服务，
+// 待复制的对象记录在数据库队列中，失败后按指数退避重试
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/internal/storage"
+	"github.com/gooss/server/pkg/logger"
+)
+
+const (
+	// taskLease 领取任务后的租约时长，处理实例崩溃时任务在租约到期后被重新领取
+	taskLease = 30 * time.Minute
+	// maxErrorLength 队列中记录的错误信息最大长度
+	maxErrorLength = 1024
+)
+
+// Options 复制参数，未设置的项使用默认值
+type Options struct {
+	Workers        int           // 并发复制的对象数
+	PollInterval   time.Duration // 队列为空时的轮询间隔
+	MaxAttempts    int           // 超过该次数后标记为 FAILED，需要手动重试
+	RetryBaseDelay time.Duration // 首次重试等待时间，之后指数增长
+	MaxRetryDelay  time.Duration
+	// Writable 检查本地目标 Bucket 能否写入（如只读模式），返回错误时任务稍后重试且不计入失败次数
+	Writable func(bucket string) error
+}
+
+// Replicator 复制队列的处理器，多个实例可以同时运行，任务通过数据库租约分配
+type Replicator struct {
+	storage storage.Engine
+	repo    metadata.Repository
+	opts    Options
+
+	replicated atomic.Int64
+	deleted    atomic.Int64
+	bytes      atomic.Int64
+	retries    atomic.Int64
+	failed     atomic.Int64
+}
+
+// Stats 复制累计指标和当前队列状态
+type Stats struct {
+	Replicated      int64            `json:"replicated"`
+	Deleted         int64            `json:"deleted"`
+	Bytes           int64            `json:"bytes"`
+	Retries         int64            `json:"retries"`
+	Failed          int64            `json:"failed"`
+	QueueByStatus   map[string]int64 `json:"queue_by_status,omitempty"`
+	ObjectsByStatus map[string]int64 `json:"objects_by_status,omitempty"`
+}
+
+// New 创建复制处理器
+func New(storage storage.Engine, repo metadata.Repository, opts Options) *Replicator {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	if opts.RetryBaseDelay <= 0 {
+		opts.RetryBaseDelay = 10 * time.Second
+	}
+	if opts.MaxRetryDelay <= 0 {
+		opts.MaxRetryDelay = time.Hour
+	}
+	return &Replicator{
+		storage: storage,
+		repo:    repo,
+		opts:    opts,
+	}
+}
+
+// Start 在后台循环处理复制队列：领取到的任务数不足一批时等待 PollInterval，ctx 取消后退出
+func (r *Replicator) Start(ctx context.Context) {
+	go func() {
+		for {
+			n := r.RunOnce(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if n >= r.batchSize() {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.opts.PollInterval):
+			}
+		}
+	}()
+}
+
+// batchSize 每次领取的任务数
+func (r *Replicator) batchSize() int {
+	return r.opts.Workers * 4
+}
+
+// RunOnce 领取一批到期任务并发处理，返回领取的任务数
+func (r *Replicator) RunOnce(ctx context.Context) int {
+	tasks, err := r.repo.ClaimReplicationTasks(ctx, r.batchSize(), taskLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warnf("Failed to claim replication tasks: %v", err)
+		}
+		return 0
+	}
+
+	queue := make(chan metadata.ReplicationTask)
+	var wg sync.WaitGroup
+	for i := 0; i < r.opts.Workers && i < len(tasks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				r.process(ctx, &task)
+			}
+		}()
+	}
+	for _, task := range tasks {
+		queue <- task
+	}
+	close(queue)
+	wg.Wait()
+	return len(tasks)
+}
+
+// Stats 返回累计指标，以及队列和对象的复制状态统计
+func (r *Replicator) Stats(ctx context.Context) *Stats {
+	stats := &Stats{
+		Replicated: r.replicated.Load(),
+		Deleted:    r.deleted.Load(),
+		Bytes:      r.bytes.Load(),
+		Retries:    r.retries.Load(),
+		Failed:     r.failed.Load(),
+	}
+	if counts, err := r.repo.CountReplicationTasks(ctx); err == nil {
+		stats.QueueByStatus = counts
+	}
+	if counts, err := r.repo.CountObjectsByReplicationStatus(ctx); err == nil {
+		stats.ObjectsByStatus = counts
+	}
+	return stats
+}
+
+// process 处理一个复制任务：复制对象的当前状态，成功后删除任务并更新对象复制状态，失败后安排重试
+func (r *Replicator) process(ctx context.Context, task *metadata.ReplicationTask) {
+	bucket, err := r.repo.GetBucketByID(ctx, task.BucketID)
+	if err != nil {
+		r.retry(ctx, task, nil, err)
+		return
+	}
+	if bucket == nil {
+		r.complete(ctx, task, nil, nil)
+		return
+	}
+	obj, err := r.repo.GetObject(ctx, bucket.ID, task.Key)
+	if err != nil {
+		r.retry(ctx, task, nil, err)
+		return
+	}
+
+	// 规则已删除或不再指向该目标，或对象在复制前被删除且删除不复制时丢弃任务
+	cfg, err := r.repo.GetBucketReplication(ctx, bucket.ID)
+	if err != nil {
+		r.retry(ctx, task, obj, err)
+		return
+	}
+	if cfg == nil || !cfg.HasDestination(task.Destination) || (task.Operation == metadata.ReplicationOpPut && obj == nil) {
+		r.complete(ctx, task, obj, nil)
+		return
+	}
+
+	dest, err := r.destination(ctx, task.Destination)
+	if err != nil {
+		r.retry(ctx, task, obj, err)
+		return
+	}
+	if task.Operation == metadata.ReplicationOpDelete {
+		err = dest.delete(ctx, task.Key)
+	} else {
+		err = r.put(ctx, dest, bucket, obj)
+	}
+	if err != nil {
+		r.retry(ctx, task, obj, err)
+		return
+	}
+
+	if task.Operation == metadata.ReplicationOpDelete {
+		r.deleted.Add(1)
+	} else {
+		r.replicated.Add(1)
+		r.bytes.Add(obj.Size)
+	}
+	r.complete(ctx, task, obj, cfg)
+}
+
+// put 将对象数据、元数据和标签写入目标
+func (r *Replicator) put(ctx context.Context, dest destination, bucket *metadata.Bucket, obj *metadata.Object) error {
+	tags, err := r.repo.GetObjectTags(ctx, obj.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+	reader, _, err := r.storage.Get(ctx, bucket.Name, obj.Key)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+	defer reader.Close()
+	return dest.put(ctx, obj, tags, reader)
+}
+
+// complete 删除已完成的任务。cfg 为 nil 表示任务被丢弃，对象不再需要复制
+func (r *Replicator) complete(ctx context.Context, task *metadata.ReplicationTask, obj *metadata.Object, cfg *metadata.ReplicationConfiguration) {
+	removed, err := r.repo.CompleteReplicationTask(ctx, task.ID, task.Version)
+	if err != nil {
+		logger.Warnf("Failed to complete replication of %s to %s: %v", task.Key, task.Destination, err)
+		return
+	}
+	// 处理期间对象被再次写入，任务已放回队列，状态由下一次处理更新
+	if !removed || obj == nil || task.Operation != metadata.ReplicationOpPut {
+		return
+	}
+	status := metadata.ReplicationStatusCompleted
+	if cfg == nil {
+		status = metadata.ReplicationStatusNone
+	}
+	if _, err := r.repo.SetObjectReplicationStatus(ctx, task.BucketID, obj.Key, obj.ETag, status); err != nil {
+		logger.Warnf("Failed to update replication status of %s: %v", obj.Key, err)
+	}
+}
+
+// retry 记录失败并按指数退避安排重试，超过最大次数后标记为 FAILED
+func (r *Replicator) retry(ctx context.Context, task *metadata.ReplicationTask, obj *metadata.Object, cause error) {
+	if ctx.Err() != nil {
+		// 停止时不计入失败次数，租约到期后由其他实例或重启后重新领取
+		return
+	}
+	attempts := task.Attempts + 1
+	if errors.Is(cause, errReadOnly) {
+		attempts = task.Attempts
+	}
+	status := metadata.ReplicationTaskPending
+	if attempts >= r.opts.MaxAttempts {
+		status = metadata.ReplicationTaskFailed
+	}
+	msg := cause.Error()
+	if len(msg) > maxErrorLength {
+		msg = msg[:maxErrorLength]
+	}
+
+	recorded, err := r.repo.RetryReplicationTask(ctx, task.ID, task.Version, attempts, status, msg, time.Now().Add(r.backoff(attempts)))
+	if err != nil {
+		logger.Warnf("Failed to record replication failure of %s to %s: %v", task.Key, task.Destination, err)
+		return
+	}
+	if !recorded {
+		return
+	}
+	if status == metadata.ReplicationTaskPending {
+		r.retries.Add(1)
+		logger.Debugf("Replication of %s to %s failed (attempt %d): %v", task.Key, task.Destination, attempts, cause)
+		return
+	}
+
+	r.failed.Add(1)
+	logger.Errorf("Replication of %s to %s failed after %d attempts: %v", task.Key, task.Destination, attempts, cause)
+	if obj != nil && task.Operation == metadata.ReplicationOpPut {
+		if _, err := r.repo.SetObjectReplicationStatus(ctx, task.BucketID, obj.Key, obj.ETag, metadata.ReplicationStatusFailed); err != nil {
+			logger.Warnf("Failed to update replication status of %s: %v", obj.Key, err)
+		}
+	}
+}
+
+// backoff 第 attempts 次失败后的等待时间，未计入失败时等待 RetryBaseDelay
+func (r *Replicator) backoff(attempts int) time.Duration {
+	delay := r.opts.RetryBaseDelay
+	for i := 1; i < attempts && delay < r.opts.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.opts.MaxRetryDelay {
+		delay = r.opts.MaxRetryDelay
+	}
+	return delay
+}
@@ -59,6 +59,9 @@ type Engine interface {
 	// Copy 复制对象
 	Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) (*ObjectInfo, error)
 
+	// Rename 在 Bucket 内原子地将对象移动到新的 Key，目标已存在时替换；返回的信息不含 ETag
+	Rename(ctx context.Context, bucket, srcKey, dstKey string) (*ObjectInfo, error)
+
 	// CreateBucket 创建 Bucket 目录
 	CreateBucket(ctx context.Context, bucket string) error
 
@@ -79,6 +82,30 @@ type Engine interface {
 
 	// AbortMultipartUpload 取消分片上传
 	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+
+	// ListStagedUploads 列出存储中暂存的分片上传
+	ListStagedUploads(ctx context.Context) ([]StagedUpload, error)
+
+	// ListBucketDirs 列出存储中存在的 Bucket 目录
+	ListBucketDirs(ctx context.Context) ([]string, error)
+
+	// WalkBucket 遍历 Bucket 下的所有文件（包括未完成写入的临时文件）
+	WalkBucket(ctx context.Context, bucket string, fn func(file StoredFile) error) error
+}
+
+// StoredFile 存储中的文件
+type StoredFile struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+	Temp    bool // 写入过程中残留的临时文件
+}
+
+// StagedUpload 存储中暂存的分片上传
+type StagedUpload struct {
+	UploadID string
+	Size     int64
+	ModTime  time.Time
 }
 
 // PartInfo 分片信息
@@ -9,11 +9,15 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/gooss/server/internal/storage"
 )
 
+// tmpFilePrefix Put 写入时使用的临时文件前缀
+const tmpFilePrefix = ".tmp-"
+
 // LocalStorage 本地存储引擎实现
 type LocalStorage struct {
 	basePath      string
@@ -55,7 +59,7 @@ func (l *LocalStorage) Put(ctx context.Context, bucket, key string, reader io.Re
 	}
 
 	// 创建临时文件
-	tmpFile, err := os.CreateTemp(dir, ".tmp-")
+	tmpFile, err := os.CreateTemp(dir, tmpFilePrefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -289,6 +293,22 @@ func (l *LocalStorage) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, d
 	}, nil
 }
 
+// Rename 重命名对象文件，同一文件系统内的 rename 是原子的
+func (l *LocalStorage) Rename(ctx context.Context, bucket, srcKey, dstKey string) (*storage.ObjectInfo, error) {
+	srcPath := l.objectPath(bucket, srcKey)
+	dstPath := l.objectPath(bucket, dstKey)
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		return nil, fmt.Errorf("failed to rename file: %w", err)
+	}
+	l.cleanEmptyDirs(filepath.Dir(srcPath), l.objectPath(bucket, ""))
+
+	return l.Stat(ctx, bucket, dstKey)
+}
+
 // CreateBucket 创建 Bucket 目录
 func (l *LocalStorage) CreateBucket(ctx context.Context, bucket string) error {
 	bucketPath := filepath.Join(l.basePath, bucket)
@@ -415,3 +435,92 @@ func (l *LocalStorage) AbortMultipartUpload(ctx context.Context, bucket, key, up
 	uploadPath := filepath.Join(l.multipartPath, uploadID)
 	return os.RemoveAll(uploadPath)
 }
+
+// ListStagedUploads 列出分片暂存目录
+func (l *LocalStorage) ListStagedUploads(ctx context.Context) ([]storage.StagedUpload, error) {
+	entries, err := os.ReadDir(l.multipartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read multipart directory: %w", err)
+	}
+
+	var uploads []storage.StagedUpload
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		upload := storage.StagedUpload{
+			UploadID: entry.Name(),
+			ModTime:  info.ModTime(),
+		}
+
+		// 统计分片大小，并以最近写入的分片时间为准
+		parts, _ := os.ReadDir(filepath.Join(l.multipartPath, entry.Name()))
+		for _, part := range parts {
+			if partInfo, err := part.Info(); err == nil {
+				upload.Size += partInfo.Size()
+				if partInfo.ModTime().After(upload.ModTime) {
+					upload.ModTime = partInfo.ModTime()
+				}
+			}
+		}
+
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+// ListBucketDirs 列出 Bucket 目录
+func (l *LocalStorage) ListBucketDirs(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(l.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read buckets directory: %w", err)
+	}
+
+	var buckets []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			buckets = append(buckets, entry.Name())
+		}
+	}
+	return buckets, nil
+}
+
+// WalkBucket 遍历 Bucket 目录下的文件，Key 使用 / 分隔
+func (l *LocalStorage) WalkBucket(ctx context.Context, bucket string, fn func(file storage.StoredFile) error) error {
+	root := l.objectPath(bucket, "")
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		return fn(storage.StoredFile{
+			Key:     filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Temp:    strings.HasPrefix(d.Name(), tmpFilePrefix),
+		})
+	})
+}
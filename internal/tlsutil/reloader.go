@@ -0,0 +1,136 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gooss/server/pkg/logger"
+)
+
+// CertReloader 持有当前使用的证书，证书文件变化或调用 Reload 时重新加载，无需重启服务。
+// 新证书加载失败时继续使用旧证书
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// NewCertReloader 加载证书和私钥，文件不可用时返回错误
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新读取证书和私钥
+func (r *CertReloader) Reload() error {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	r.mu.Unlock()
+
+	if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+		logger.Infof("Loaded TLS certificate %s (subject %s, expires %s)",
+			r.certFile, leaf.Subject.CommonName, leaf.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// GetCertificate 供 tls.Config.GetCertificate 使用
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch 定期检查证书文件的修改时间，变化后重新加载，ctx 取消后退出。
+// 采用轮询而不是文件事件，可以覆盖证书目录被整体替换（如 Kubernetes Secret 挂载）的情况
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				certMod, keyMod, err := r.modTimes()
+				if err != nil {
+					logger.Warnf("Failed to stat TLS certificate: %v", err)
+					continue
+				}
+				r.mu.RLock()
+				changed := !certMod.Equal(r.certMod) || !keyMod.Equal(r.keyMod)
+				r.mu.RUnlock()
+				if !changed {
+					continue
+				}
+				if err := r.Reload(); err != nil {
+					logger.Warnf("Failed to reload TLS certificate, keeping previous one: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (r *CertReloader) modTimes() (certMod, keyMod time.Time, err error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return certMod, keyMod, err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return certMod, keyMod, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// ParseMinVersion 解析最低 TLS 版本，支持 "1.0"、"1.1"、"1.2"、"1.3"，为空时使用 TLS 1.2
+func ParseMinVersion(v string) (uint16, error) {
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version: %s", v)
+}
+
+// LoadCertPool 从 PEM 文件加载 CA 证书池
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/gooss/server/internal/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedEngine 为存储引擎操作创建 span 的包装器，未覆盖的方法直接透传
+type tracedEngine struct {
+	storage.Engine
+}
+
+// TraceStorage 为存储引擎添加链路追踪
+func TraceStorage(engine storage.Engine) storage.Engine {
+	return &tracedEngine{Engine: engine}
+}
+
+func startStorageSpan(ctx context.Context, operation, bucket, key string) (context.Context, trace.Span) {
+	return Start(ctx, "storage."+operation,
+		attribute.String("oss.bucket", bucket),
+		attribute.String("oss.key", key),
+	)
+}
+
+func (e *tracedEngine) Put(ctx context.Context, bucket, key string, reader io.Reader, size int64, contentType string) (*storage.ObjectInfo, error) {
+	ctx, span := startStorageSpan(ctx, "put", bucket, key)
+	span.SetAttributes(attribute.Int64("oss.size", size))
+	info, err := e.Engine.Put(ctx, bucket, key, reader, size, contentType)
+	End(span, err)
+	return info, err
+}
+
+func (e *tracedEngine) Get(ctx context.Context, bucket, key string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	ctx, span := startStorageSpan(ctx, "get", bucket, key)
+	rc, info, err := e.Engine.Get(ctx, bucket, key)
+	End(span, err)
+	return rc, info, err
+}
+
+func (e *tracedEngine) GetRange(ctx context.Context, bucket, key string, start, end int64) (io.ReadCloser, *storage.ObjectInfo, error) {
+	ctx, span := startStorageSpan(ctx, "get_range", bucket, key)
+	span.SetAttributes(attribute.Int64("oss.range_start", start), attribute.Int64("oss.range_end", end))
+	rc, info, err := e.Engine.GetRange(ctx, bucket, key, start, end)
+	End(span, err)
+	return rc, info, err
+}
+
+func (e *tracedEngine) Delete(ctx context.Context, bucket, key string) error {
+	ctx, span := startStorageSpan(ctx, "delete", bucket, key)
+	err := e.Engine.Delete(ctx, bucket, key)
+	End(span, err)
+	return err
+}
+
+func (e *tracedEngine) Stat(ctx context.Context, bucket, key string) (*storage.ObjectInfo, error) {
+	ctx, span := startStorageSpan(ctx, "stat", bucket, key)
+	info, err := e.Engine.Stat(ctx, bucket, key)
+	End(span, err)
+	return info, err
+}
+
+func (e *tracedEngine) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) (*storage.ObjectInfo, error) {
+	ctx, span := startStorageSpan(ctx, "copy", dstBucket, dstKey)
+	span.SetAttributes(attribute.String("oss.source_bucket", srcBucket), attribute.String("oss.source_key", srcKey))
+	info, err := e.Engine.Copy(ctx, srcBucket, srcKey, dstBucket, dstKey)
+	End(span, err)
+	return info, err
+}
+
+func (e *tracedEngine) Rename(ctx context.Context, bucket, srcKey, dstKey string) (*storage.ObjectInfo, error) {
+	ctx, span := startStorageSpan(ctx, "rename", bucket, dstKey)
+	span.SetAttributes(attribute.String("oss.source_key", srcKey))
+	info, err := e.Engine.Rename(ctx, bucket, srcKey, dstKey)
+	End(span, err)
+	return info, err
+}
+
+func (e *tracedEngine) PutPart(ctx context.Context, bucket, key, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	ctx, span := startStorageSpan(ctx, "put_part", bucket, key)
+	span.SetAttributes(attribute.String("oss.upload_id", uploadID), attribute.Int("oss.part_number", partNumber))
+	etag, err := e.Engine.PutPart(ctx, bucket, key, uploadID, partNumber, reader, size)
+	End(span, err)
+	return etag, err
+}
+
+func (e *tracedEngine) CompleteParts(ctx context.Context, bucket, key, uploadID string, parts []storage.PartInfo) (*storage.ObjectInfo, error) {
+	ctx, span := startStorageSpan(ctx, "complete_parts", bucket, key)
+	span.SetAttributes(attribute.String("oss.upload_id", uploadID), attribute.Int("oss.parts", len(parts)))
+	info, err := e.Engine.CompleteParts(ctx, bucket, key, uploadID, parts)
+	End(span, err)
+	return info, err
+}
+
+func (e *tracedEngine) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	ctx, span := startStorageSpan(ctx, "abort_multipart", bucket, key)
+	span.SetAttributes(attribute.String("oss.upload_id", uploadID))
+	err := e.Engine.AbortMultipartUpload(ctx, bucket, key, uploadID)
+	End(span, err)
+	return err
+}
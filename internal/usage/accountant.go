@@ -0,0 +1,182 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gooss/server/internal/metadata"
+	"github.com/gooss/server/pkg/logger"
+)
+
+// Request 单个请求的计费信息
+type Request struct {
+	BucketName  string
+	RequesterID int64 // 匿名请求为 0
+	Class       string
+	Ingress     int64
+	Egress      int64
+	Time        time.Time
+}
+
+// pendingKey 内存中按请求方累计，写入时再归属到 Bucket 所有者
+type pendingKey struct {
+	day         string
+	bucketName  string
+	requesterID int64
+}
+
+// Accountant 在内存中累计请求数和流量并定期写入按天汇总表，同时定期采样存储用量
+type Accountant struct {
+	repo           metadata.Repository
+	flushInterval  time.Duration
+	sampleInterval time.Duration
+
+	mu      sync.Mutex
+	pending map[pendingKey]*metadata.UsageCounter
+}
+
+// NewAccountant 创建用量计费器
+func NewAccountant(repo metadata.Repository, flushInterval, sampleInterval time.Duration) *Accountant {
+	return &Accountant{
+		repo:           repo,
+		flushInterval:  flushInterval,
+		sampleInterval: sampleInterval,
+		pending:        make(map[pendingKey]*metadata.UsageCounter),
+	}
+}
+
+// Record 累计一个请求，不访问数据库
+func (a *Accountant) Record(r Request) {
+	day := r.Time.UTC().Truncate(24 * time.Hour)
+	key := pendingKey{day: day.Format("2006-01-02"), bucketName: r.BucketName, requesterID: r.RequesterID}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	counter, ok := a.pending[key]
+	if !ok {
+		counter = &metadata.UsageCounter{Day: day, BucketName: r.BucketName, OwnerID: r.RequesterID}
+		a.pending[key] = counter
+	}
+	switch r.Class {
+	case metadata.RequestClassA:
+		counter.ClassARequests++
+	case metadata.RequestClassB:
+		counter.ClassBRequests++
+	default:
+		counter.OtherRequests++
+	}
+	counter.IngressBytes += r.Ingress
+	counter.EgressBytes += r.Egress
+}
+
+// Start 在后台定期写入请求计数并采样存储用量，ctx 取消后写入剩余计数并退出
+func (a *Accountant) Start(ctx context.Context) {
+	go func() {
+		flush := time.NewTicker(a.flushInterval)
+		defer flush.Stop()
+		sample := time.NewTicker(a.sampleInterval)
+		defer sample.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := a.Flush(flushCtx); err != nil {
+					logger.Warnf("Failed to flush usage counters: %v", err)
+				}
+				cancel()
+				return
+			case <-flush.C:
+				if err := a.Flush(ctx); err != nil {
+					logger.Warnf("Failed to flush usage counters: %v", err)
+				}
+			case <-sample.C:
+				// 间隔远超采样周期（如停机）时不把整段时间按当前用量计入
+				if _, err := a.repo.SampleStorageUsage(ctx, time.Now(), a.sampleInterval/2, 4*a.sampleInterval); err != nil {
+					logger.Warnf("Failed to sample storage usage: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Flush 将内存中的计数归属到 Bucket 所有者后写入数据库，失败时计数保留到下次写入
+func (a *Accountant) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[pendingKey]*metadata.UsageCounter)
+	a.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Bucket 不存在（例如请求了不存在的 Bucket）时归属到请求方
+	owners := make(map[string]int64)
+	merged := make(map[pendingKey]*metadata.UsageCounter)
+	for key, counter := range pending {
+		if key.bucketName != "" {
+			owner, ok := owners[key.bucketName]
+			if !ok {
+				owner = -1
+				bucket, err := a.repo.GetBucketByName(ctx, key.bucketName)
+				if err != nil {
+					a.restore(pending)
+					return err
+				}
+				if bucket != nil {
+					owner = bucket.OwnerID
+				}
+				owners[key.bucketName] = owner
+			}
+			if owner >= 0 {
+				counter.OwnerID = owner
+			}
+		}
+
+		mk := pendingKey{day: key.day, bucketName: key.bucketName, requesterID: counter.OwnerID}
+		if existing, ok := merged[mk]; ok {
+			add(existing, counter)
+		} else {
+			c := *counter
+			merged[mk] = &c
+		}
+	}
+
+	counters := make([]metadata.UsageCounter, 0, len(merged))
+	for _, c := range merged {
+		counters = append(counters, *c)
+	}
+	if err := a.repo.AddUsageCounters(ctx, counters); err != nil {
+		a.restore(pending)
+		return err
+	}
+	return nil
+}
+
+// restore 写入失败时把计数合并回内存
+func (a *Accountant) restore(pending map[pendingKey]*metadata.UsageCounter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, counter := range pending {
+		existing, ok := a.pending[key]
+		if !ok {
+			// 归属可能已被改写，恢复为请求方
+			counter.OwnerID = key.requesterID
+			a.pending[key] = counter
+			continue
+		}
+		add(existing, counter)
+	}
+}
+
+func add(dst, src *metadata.UsageCounter) {
+	dst.ClassARequests += src.ClassARequests
+	dst.ClassBRequests += src.ClassBRequests
+	dst.OtherRequests += src.OtherRequests
+	dst.IngressBytes += src.IngressBytes
+	dst.EgressBytes += src.EgressBytes
+}
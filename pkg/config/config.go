@@ -3,32 +3,63 @@ package config
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Storage  StorageConfig  `mapstructure:"storage"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Auth     AuthConfig     `mapstructure:"auth"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	Limits   LimitsConfig   `mapstructure:"limits"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Storage     StorageConfig     `mapstructure:"storage"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Limits      LimitsConfig      `mapstructure:"limits"`
+	Maintenance MaintenanceConfig `mapstructure:"maintenance"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	Usage       UsageConfig       `mapstructure:"usage"`
+	Migration   MigrationConfig   `mapstructure:"migration"`
+	Replication ReplicationConfig `mapstructure:"replication"`
 }
 
 type ServerConfig struct {
 	Host           string   `mapstructure:"host"`
 	Port           int      `mapstructure:"port"`
 	AdminPort      int      `mapstructure:"admin_port"`
+	AdminHost      string   `mapstructure:"admin_host"`   // 管理端口监听地址，为空时同 host
+	EnablePprof    bool     `mapstructure:"enable_pprof"` // 在管理端口提供 /debug/pprof，未配置管理端口时不生效
 	AllowedOrigins []string `mapstructure:"allowed_origins"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // 可信反向代理地址或网段，只有来自这些地址的 X-Forwarded-For 才被采用
 	APIEndpoint    string   `mapstructure:"api_endpoint"`
+	MetricsToken   string   `mapstructure:"metrics_token"` // 设置后 /metrics 要求 Authorization: Bearer <token>
+	// 优雅关闭：先让 /health 返回失败并等待 readiness_delay，使负载均衡摘除实例，
+	// 再等待进行中的请求完成，两者合计不超过 drain_timeout
+	DrainTimeout   string    `mapstructure:"drain_timeout"`
+	ReadinessDelay string    `mapstructure:"readiness_delay"`
+	TLS            TLSConfig `mapstructure:"tls"`
+	// 只读模式：拒绝所有修改操作，也可通过管理 API 开启
+	ReadOnly        bool     `mapstructure:"read_only"`
+	ReadOnlyBuckets []string `mapstructure:"read_only_buckets"`
+}
+
+// TLSConfig HTTPS 配置，证书文件变化或收到 SIGHUP 时重新加载
+type TLSConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	CertFile       string `mapstructure:"cert_file"`
+	KeyFile        string `mapstructure:"key_file"`
+	MinVersion     string `mapstructure:"min_version"`     // 1.0 | 1.1 | 1.2 | 1.3，默认 1.2
+	ClientCAFile   string `mapstructure:"client_ca_file"`  // 设置后管理 API 要求由该 CA 签发的客户端证书
+	ReloadInterval string `mapstructure:"reload_interval"` // 检查证书文件变化的间隔
 }
 
 type StorageConfig struct {
 	Type        string            `mapstructure:"type"`
 	Local       LocalStorage      `mapstructure:"local"`
 	Distributed DistributedConfig `mapstructure:"distributed"`
+	// Redundant 与主存储目录结构相同的冗余副本（如备份盘），巡检发现损坏时用于修复
+	Redundant LocalStorage `mapstructure:"redundant"`
 }
 
 type LocalStorage struct {
@@ -87,9 +118,80 @@ type LimitsConfig struct {
 	MinPartSize        int64 `mapstructure:"min_part_size"`
 	MaxParts           int   `mapstructure:"max_parts"`
 	RateLimitPerSecond int   `mapstructure:"rate_limit_per_second"`
+
+	// 限流：每秒请求数为 0 表示不限制，burst 为 0 时等于每秒请求数
+	RateLimitPerIP     int    `mapstructure:"rate_limit_per_ip"`
+	RateLimitPerBucket int    `mapstructure:"rate_limit_per_bucket"`
+	RateLimitBurst     int    `mapstructure:"rate_limit_burst"`
+	RateLimitBackend   string `mapstructure:"rate_limit_backend"` // memory 或 redis
+
+	// 每个用户的上传/下载带宽上限（字节/秒），0 表示不限制
+	UploadBytesPerSecond   int64 `mapstructure:"upload_bytes_per_second"`
+	DownloadBytesPerSecond int64 `mapstructure:"download_bytes_per_second"`
+}
+
+// MaintenanceConfig 后台维护任务配置
+type MaintenanceConfig struct {
+	MultipartGC MultipartGCConfig `mapstructure:"multipart_gc"`
+	Scrub       ScrubConfig       `mapstructure:"scrub"`
+}
+
+// MultipartGCConfig 过期分片上传清理配置，时间格式同 util.ParseDuration（如 "7d", "1h"）
+type MultipartGCConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	MaxAge   string `mapstructure:"max_age"`
+	Interval string `mapstructure:"interval"`
+}
+
+// ScrubConfig 数据完整性巡检配置
+type ScrubConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	Interval       string `mapstructure:"interval"`         // 两轮巡检之间的间隔
+	ReverifyAfter  string `mapstructure:"reverify_after"`   // 对象校验结果的有效期
+	BatchSize      int    `mapstructure:"batch_size"`       // 每批从数据库读取的对象数
+	BytesPerSecond int64  `mapstructure:"bytes_per_second"` // 读取限速，0 表示不限速
 }
 
-var globalConfig *Config
+// UsageConfig 用量计费配置
+type UsageConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	FlushInterval  string `mapstructure:"flush_interval"`  // 请求计数写入间隔
+	SampleInterval string `mapstructure:"sample_interval"` // 存储用量采样间隔
+}
+
+// MigrationConfig 迁移任务传输参数，并发数和带宽上限可在创建任务时单独指定
+type MigrationConfig struct {
+	Concurrency        int    `mapstructure:"concurrency"`         // 每个任务并发迁移的对象数
+	MultipartThreshold int64  `mapstructure:"multipart_threshold"` // 超过该大小的对象按范围分片下载
+	PartSize           int64  `mapstructure:"part_size"`
+	PartConcurrency    int    `mapstructure:"part_concurrency"` // 单个对象并发下载的分片数
+	MaxRetries         int    `mapstructure:"max_retries"`      // 对象或分片失败后的重试次数
+	RetryBaseDelay     string `mapstructure:"retry_base_delay"` // 首次重试等待时间，之后指数增长
+	BytesPerSecond     int64  `mapstructure:"bytes_per_second"` // 每个任务的带宽上限，0 表示不限制
+}
+
+// ReplicationConfig Bucket 复制后台任务配置，复制规则通过 PUT /{bucket}?replication 设置
+type ReplicationConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	Workers        int    `mapstructure:"workers"`          // 并发复制的对象数
+	PollInterval   string `mapstructure:"poll_interval"`    // 队列为空时的轮询间隔
+	MaxAttempts    int    `mapstructure:"max_attempts"`     // 超过该次数后标记为 FAILED，需要手动重试
+	RetryBaseDelay string `mapstructure:"retry_base_delay"` // 首次重试等待时间，之后指数增长
+	MaxRetryDelay  string `mapstructure:"max_retry_delay"`
+}
+
+// TracingConfig OpenTelemetry 链路追踪配置
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	ServiceName string  `mapstructure:"service_name"`
+	Exporter    string  `mapstructure:"exporter"` // otlp | stdout | file
+	Endpoint    string  `mapstructure:"endpoint"` // OTLP/HTTP 地址，如 localhost:4318
+	Insecure    bool    `mapstructure:"insecure"`
+	FilePath    string  `mapstructure:"file_path"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+var globalConfig atomic.Pointer[Config]
 
 func Load(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
@@ -109,10 +211,26 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	globalConfig = &cfg
+	globalConfig.Store(&cfg)
 	return &cfg, nil
 }
 
+// Get 返回配置文件中的最新配置，配置文件变化后随之更新
 func Get() *Config {
-	return globalConfig
+	return globalConfig.Load()
+}
+
+// Watch 监听配置文件变化，重新解析后更新 Get 返回的配置并回调 onChange。
+// 解析失败时保留原配置，以 err 回调
+func Watch(onChange func(cfg *Config, err error)) {
+	viper.OnConfigChange(func(fsnotify.Event) {
+		var cfg Config
+		if err := viper.Unmarshal(&cfg); err != nil {
+			onChange(nil, fmt.Errorf("failed to unmarshal config: %w", err))
+			return
+		}
+		globalConfig.Store(&cfg)
+		onChange(&cfg, nil)
+	})
+	viper.WatchConfig()
 }
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// redactedValue 替换敏感配置项的值
+const redactedValue = "******"
+
+// secretKeys 对外展示配置时需要隐藏的配置项
+var secretKeys = map[string]bool{
+	"database.password":       true,
+	"redis.password":          true,
+	"auth.root_password":      true,
+	"auth.init_access_secret": true,
+	"server.metrics_token":    true,
+}
+
+// HotReloadable 判断配置项能否在运行中生效，其余配置项修改后需要重启。
+// 限流后端在启动时选定，修改后同样需要重启
+func HotReloadable(key string) bool {
+	switch key {
+	case "server.allowed_origins", "server.read_only", "server.read_only_buckets", "logging.level":
+		return true
+	case "limits.rate_limit_backend":
+		return false
+	}
+	return strings.HasPrefix(key, "limits.")
+}
+
+// Flatten 将配置展开为以点分隔的配置项，如 "server.port"，redact 为 true 时隐藏敏感值
+func (c *Config) Flatten(redact bool) map[string]interface{} {
+	out := make(map[string]interface{})
+	flatten("", reflect.ValueOf(*c), out)
+	if redact {
+		for key := range secretKeys {
+			if v, ok := out[key].(string); ok && v != "" {
+				out[key] = redactedValue
+			}
+		}
+	}
+	return out
+}
+
+func flatten(prefix string, v reflect.Value, out map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			key = strings.ToLower(t.Field(i).Name)
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			flatten(key, field, out)
+			continue
+		}
+		out[key] = field.Interface()
+	}
+}
+
+// Diff 返回两份配置中取值不同的配置项，按名称排序
+func Diff(old, new *Config) []string {
+	before := old.Flatten(false)
+	after := new.Flatten(false)
+
+	var changed []string
+	for key, v := range after {
+		if !reflect.DeepEqual(before[key], v) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
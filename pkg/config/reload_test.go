@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// secretLike 名称看起来像密钥的配置项，新增此类配置项时必须加入 secretKeys
+var secretLike = regexp.MustCompile(`(password|secret|token)$`)
+
+// fillStrings 将所有字符串配置项设为非空值
+func fillStrings(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			fillStrings(field)
+		case reflect.String:
+			field.SetString("value")
+		}
+	}
+}
+
+func TestFlattenRedactsSecrets(t *testing.T) {
+	var cfg Config
+	fillStrings(reflect.ValueOf(&cfg).Elem())
+
+	found := 0
+	for key, v := range cfg.Flatten(true) {
+		if !secretLike.MatchString(key) {
+			continue
+		}
+		found++
+		if v != redactedValue {
+			t.Errorf("%s = %v, want redacted", key, v)
+		}
+	}
+	if found < len(secretKeys) {
+		t.Errorf("found %d secret-like keys, want at least %d", found, len(secretKeys))
+	}
+}
+
+func TestFlattenKeepsSecretsWithoutRedact(t *testing.T) {
+	var cfg Config
+	fillStrings(reflect.ValueOf(&cfg).Elem())
+
+	out := cfg.Flatten(false)
+	for key := range secretKeys {
+		if out[key] != "value" {
+			t.Errorf("%s = %v, want value", key, out[key])
+		}
+	}
+}
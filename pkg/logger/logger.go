@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"context"
 	"os"
 
+	"github.com/gooss/server/pkg/requestid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -10,11 +13,15 @@ import (
 var log *zap.Logger
 var sugar *zap.SugaredLogger
 
+// atomicLevel 日志级别，可在运行中通过 SetLevel 修改
+var atomicLevel = zap.NewAtomicLevel()
+
 func Init(level, format, output, filePath string) error {
 	var lvl zapcore.Level
 	if err := lvl.UnmarshalText([]byte(level)); err != nil {
 		lvl = zapcore.InfoLevel
 	}
+	atomicLevel.SetLevel(lvl)
 
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "time",
@@ -45,17 +52,24 @@ func Init(level, format, output, filePath string) error {
 			return err
 		}
 		writeSyncer = zapcore.AddSync(file)
+	} else if output == "stderr" {
+		writeSyncer = zapcore.AddSync(os.Stderr)
 	} else {
 		writeSyncer = zapcore.AddSync(os.Stdout)
 	}
 
-	core := zapcore.NewCore(encoder, writeSyncer, lvl)
+	core := zapcore.NewCore(encoder, writeSyncer, atomicLevel)
 	log = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 	sugar = log.Sugar()
 
 	return nil
 }
 
+// SetLevel 修改日志级别，用于配置热加载
+func SetLevel(level string) error {
+	return atomicLevel.UnmarshalText([]byte(level))
+}
+
 func Info(msg string, fields ...zap.Field) {
 	log.Info(msg, fields...)
 }
@@ -96,6 +110,23 @@ func With(fields ...zap.Field) *zap.Logger {
 	return log.With(fields...)
 }
 
+// Ctx 返回带有请求 ID 和当前链路 trace_id/span_id 字段的日志记录器，ctx 中没有对应信息时不附加字段
+func Ctx(ctx context.Context) *zap.SugaredLogger {
+	// 直接返回给调用方使用，抵消包装函数的 caller skip
+	l := sugar.WithOptions(zap.AddCallerSkip(-1))
+	if id := requestid.FromContext(ctx); id != "" {
+		l = l.With(zap.String("request_id", id))
+	}
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return l
+	}
+	return l.With(
+		zap.String("trace_id", spanCtx.TraceID().String()),
+		zap.String("span_id", spanCtx.SpanID().String()),
+	)
+}
+
 func Sync() error {
 	return log.Sync()
 }
@@ -0,0 +1,37 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// S3 兼容的请求 ID 响应头
+const (
+	HeaderRequestID = "x-amz-request-id"
+	HeaderHostID    = "x-amz-id-2"
+)
+
+type contextKey struct{}
+
+// New 生成请求 ID 和扩展请求 ID，格式与 S3 的 x-amz-request-id、x-amz-id-2 一致
+func New() (requestID, hostID string) {
+	var b [40]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return strings.ToUpper(hex.EncodeToString(b[:8])), base64.StdEncoding.EncodeToString(b[8:])
+}
+
+// NewContext 返回携带请求 ID 的 context
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// FromContext 取出 context 中的请求 ID，不存在时返回空字符串
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -81,6 +81,13 @@ type CopyObjectResult struct {
 	ETag         string   `xml:"ETag"`
 }
 
+// CopyPartResult 复制分片响应
+type CopyPartResult struct {
+	XMLName      xml.Name `xml:"CopyPartResult"`
+	LastModified string   `xml:"LastModified"`
+	ETag         string   `xml:"ETag"`
+}
+
 // InitiateMultipartUploadResult 初始化分片上传响应
 type InitiateMultipartUploadResult struct {
 	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
@@ -123,18 +130,28 @@ type PartInfo struct {
 
 // ListMultipartUploadsResult 列出分片上传响应
 type ListMultipartUploadsResult struct {
-	XMLName    xml.Name     `xml:"ListMultipartUploadsResult"`
-	Xmlns      string       `xml:"xmlns,attr"`
-	Bucket     string       `xml:"Bucket"`
-	KeyMarker  string       `xml:"KeyMarker"`
-	MaxUploads int          `xml:"MaxUploads"`
-	Uploads    []UploadInfo `xml:"Upload"`
+	XMLName            xml.Name       `xml:"ListMultipartUploadsResult"`
+	Xmlns              string         `xml:"xmlns,attr"`
+	Bucket             string         `xml:"Bucket"`
+	KeyMarker          string         `xml:"KeyMarker"`
+	UploadIdMarker     string         `xml:"UploadIdMarker"`
+	NextKeyMarker      string         `xml:"NextKeyMarker,omitempty"`
+	NextUploadIdMarker string         `xml:"NextUploadIdMarker,omitempty"`
+	Prefix             string         `xml:"Prefix,omitempty"`
+	Delimiter          string         `xml:"Delimiter,omitempty"`
+	MaxUploads         int            `xml:"MaxUploads"`
+	IsTruncated        bool           `xml:"IsTruncated"`
+	Uploads            []UploadInfo   `xml:"Upload"`
+	CommonPrefixes     []CommonPrefix `xml:"CommonPrefixes,omitempty"`
 }
 
 type UploadInfo struct {
-	Key       string `xml:"Key"`
-	UploadId  string `xml:"UploadId"`
-	Initiated string `xml:"Initiated"`
+	Key          string `xml:"Key"`
+	UploadId     string `xml:"UploadId"`
+	Initiator    *Owner `xml:"Initiator,omitempty"`
+	Owner        *Owner `xml:"Owner,omitempty"`
+	StorageClass string `xml:"StorageClass"`
+	Initiated    string `xml:"Initiated"`
 }
 
 // CompleteMultipartUpload 完成分片上传请求
@@ -148,6 +165,93 @@ type CompletedPartInfo struct {
 	ETag       string `xml:"ETag"`
 }
 
+// Tagging 标签集合（请求与响应共用）
+type Tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	TagSet  TagSet   `xml:"TagSet"`
+}
+
+type TagSet struct {
+	Tags []Tag `xml:"Tag"`
+}
+
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// ObjectLockConfiguration Bucket Object Lock 配置（请求与响应共用）
+type ObjectLockConfiguration struct {
+	XMLName           xml.Name        `xml:"ObjectLockConfiguration"`
+	Xmlns             string          `xml:"xmlns,attr,omitempty"`
+	ObjectLockEnabled string          `xml:"ObjectLockEnabled,omitempty"`
+	Rule              *ObjectLockRule `xml:"Rule,omitempty"`
+}
+
+type ObjectLockRule struct {
+	DefaultRetention DefaultRetention `xml:"DefaultRetention"`
+}
+
+type DefaultRetention struct {
+	Mode  string `xml:"Mode"`
+	Days  int    `xml:"Days,omitempty"`
+	Years int    `xml:"Years,omitempty"`
+}
+
+// ReplicationConfiguration Bucket 复制配置（请求与响应共用）
+type ReplicationConfiguration struct {
+	XMLName xml.Name          `xml:"ReplicationConfiguration"`
+	Xmlns   string            `xml:"xmlns,attr,omitempty"`
+	Role    string            `xml:"Role,omitempty"`
+	Rules   []ReplicationRule `xml:"Rule"`
+}
+
+type ReplicationRule struct {
+	ID                      string                   `xml:"ID,omitempty"`
+	Priority                int                      `xml:"Priority,omitempty"`
+	Status                  string                   `xml:"Status"`
+	Prefix                  *string                  `xml:"Prefix,omitempty"` // 旧版规则格式，与 Filter 二选一
+	Filter                  *ReplicationFilter       `xml:"Filter,omitempty"`
+	DeleteMarkerReplication *DeleteMarkerReplication `xml:"DeleteMarkerReplication,omitempty"`
+	Destination             ReplicationDestination   `xml:"Destination"`
+}
+
+type ReplicationFilter struct {
+	Prefix *string               `xml:"Prefix,omitempty"`
+	Tag    *Tag                  `xml:"Tag,omitempty"`
+	And    *ReplicationFilterAnd `xml:"And,omitempty"`
+}
+
+type ReplicationFilterAnd struct {
+	Prefix string `xml:"Prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag"`
+}
+
+type DeleteMarkerReplication struct {
+	Status string `xml:"Status"`
+}
+
+type ReplicationDestination struct {
+	Bucket       string `xml:"Bucket"`
+	StorageClass string `xml:"StorageClass,omitempty"`
+}
+
+// Retention 对象保留设置（请求与响应共用）
+type Retention struct {
+	XMLName         xml.Name `xml:"Retention"`
+	Xmlns           string   `xml:"xmlns,attr,omitempty"`
+	Mode            string   `xml:"Mode,omitempty"`
+	RetainUntilDate string   `xml:"RetainUntilDate,omitempty"`
+}
+
+// LegalHold 对象法律保留设置（请求与响应共用）
+type LegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status"`
+}
+
 // Error S3 错误响应
 type Error struct {
 	XMLName   xml.Name `xml:"Error"`
@@ -155,6 +259,7 @@ type Error struct {
 	Message   string   `xml:"Message"`
 	Resource  string   `xml:"Resource,omitempty"`
 	RequestId string   `xml:"RequestId,omitempty"`
+	HostId    string   `xml:"HostId,omitempty"`
 }
 
 // S3 错误码
@@ -167,9 +272,12 @@ const (
 	ErrInvalidAccessKeyId      = "InvalidAccessKeyId"
 	ErrInvalidArgument         = "InvalidArgument"
 	ErrInvalidBucketName       = "InvalidBucketName"
+	ErrInvalidBucketState      = "InvalidBucketState"
 	ErrInvalidPart             = "InvalidPart"
 	ErrInvalidPartOrder        = "InvalidPartOrder"
+	ErrInvalidRange            = "InvalidRange"
 	ErrInvalidRequest          = "InvalidRequest"
+	ErrInvalidTag              = "InvalidTag"
 	ErrMalformedXML            = "MalformedXML"
 	ErrMalformedPolicy         = "MalformedPolicy"
 	ErrMalformedPOSTRequest    = "MalformedPOSTRequest"
@@ -177,9 +285,20 @@ const (
 	ErrNoSuchKey               = "NoSuchKey"
 	ErrNoSuchBucketPolicy      = "NoSuchBucketPolicy"
 	ErrNoSuchUpload            = "NoSuchUpload"
+	ErrPreconditionFailed      = "PreconditionFailed"
+	ErrNoSuchTagSet            = "NoSuchTagSet"
+	ErrNoSuchObjectLockConfig  = "NoSuchObjectLockConfiguration"
+	ErrObjectLockConfigMissing = "ObjectLockConfigurationNotFoundError"
+	ErrNoSuchReplicationConfig = "ReplicationConfigurationNotFoundError"
 	ErrSignatureDoesNotMatch   = "SignatureDoesNotMatch"
 	ErrEntityTooLarge          = "EntityTooLarge"
 	ErrEntityTooSmall          = "EntityTooSmall"
+	ErrObjectCorrupted         = "ObjectCorrupted"
+	ErrSlowDown                = "SlowDown"
+	ErrQuotaExceeded           = "QuotaExceeded"
+	ErrMissingContentLength    = "MissingContentLength"
+	ErrServiceReadOnly         = "ServiceReadOnly"
+	ErrBucketReadOnly          = "BucketReadOnly"
 )
 
 // NewError 创建错误响应
@@ -191,6 +310,13 @@ func NewError(code, message, resource string) *Error {
 	}
 }
 
+// WithRequestID 填充请求 ID 和扩展请求 ID
+func (e *Error) WithRequestID(requestID, hostID string) *Error {
+	e.RequestId = requestID
+	e.HostId = hostID
+	return e
+}
+
 // FormatTime 格式化时间为 S3 格式
 func FormatTime(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
@@ -0,0 +1,2 @@
+-- 按请求 ID 查询审计日志
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs((metadata->>'request_id'));
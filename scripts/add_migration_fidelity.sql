@@ -0,0 +1,18 @@
+-- 迁移保真度：Bucket 配置（策略、版本控制、标签、CORS、生命周期）的迁移结果和逐对象校验报告
+
+ALTER TABLE migration_buckets ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS migration_objects (
+    job_id              VARCHAR(36) NOT NULL REFERENCES migration_jobs(id) ON DELETE CASCADE,
+    source_bucket       VARCHAR(255) NOT NULL,
+    object_key          TEXT NOT NULL,
+    size                BIGINT NOT NULL DEFAULT 0,
+    source_etag         VARCHAR(255) NOT NULL DEFAULT '',
+    target_etag         VARCHAR(255) NOT NULL DEFAULT '',
+    checksum            VARCHAR(20) NOT NULL,
+    metadata_count      INTEGER NOT NULL DEFAULT 0,
+    tag_count           INTEGER NOT NULL DEFAULT 0,
+    issues              TEXT NOT NULL DEFAULT '',
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (job_id, source_bucket, object_key)
+);
@@ -0,0 +1,6 @@
+-- 选择性迁移：Bucket 过滤、前缀过滤、Bucket 重命名，以及只统计不迁移的预演
+
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS filters JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS dry_run BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE migration_buckets ADD COLUMN IF NOT EXISTS listed_bytes BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,47 @@
+-- 迁移任务及进度，实例重启后从最后完成的对象继续
+
+CREATE TABLE IF NOT EXISTS migration_jobs (
+    id                  VARCHAR(36) PRIMARY KEY,
+    source_endpoint     TEXT NOT NULL,
+    source_region       VARCHAR(64) NOT NULL DEFAULT 'us-east-1',
+    access_key          VARCHAR(255) NOT NULL,
+    secret_key          VARCHAR(255) NOT NULL,
+    owner_id            BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_by          VARCHAR(255) NOT NULL DEFAULT '',
+    status              VARCHAR(16) NOT NULL,
+    error               TEXT NOT NULL DEFAULT '',
+    total_buckets       INT NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at          TIMESTAMP WITH TIME ZONE,
+    finished_at         TIMESTAMP WITH TIME ZONE,
+    heartbeat_at        TIMESTAMP WITH TIME ZONE
+);
+
+-- 每个 Bucket 的进度，last_key 之前（含）的对象均已处理
+CREATE TABLE IF NOT EXISTS migration_buckets (
+    job_id              VARCHAR(36) NOT NULL REFERENCES migration_jobs(id) ON DELETE CASCADE,
+    source_bucket       VARCHAR(255) NOT NULL,
+    target_bucket       VARCHAR(63) NOT NULL,
+    status              VARCHAR(16) NOT NULL,
+    last_key            TEXT NOT NULL DEFAULT '',
+    listed_objects      BIGINT NOT NULL DEFAULT 0,
+    completed_objects   BIGINT NOT NULL DEFAULT 0,
+    failed_objects      BIGINT NOT NULL DEFAULT 0,
+    completed_bytes     BIGINT NOT NULL DEFAULT 0,
+    error               TEXT NOT NULL DEFAULT '',
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (job_id, source_bucket)
+);
+
+-- 迁移失败的对象
+CREATE TABLE IF NOT EXISTS migration_errors (
+    id                  BIGSERIAL PRIMARY KEY,
+    job_id              VARCHAR(36) NOT NULL REFERENCES migration_jobs(id) ON DELETE CASCADE,
+    source_bucket       VARCHAR(255) NOT NULL,
+    object_key          TEXT NOT NULL DEFAULT '',
+    error               TEXT NOT NULL,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_migration_jobs_status ON migration_jobs(status, heartbeat_at);
+CREATE INDEX IF NOT EXISTS idx_migration_errors_job ON migration_errors(job_id, id);
@@ -0,0 +1,10 @@
+-- 迁移任务增量同步：只复制新增或变化的对象，可选删除源端已不存在的对象，并按间隔重复执行
+
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS mode VARCHAR(16) NOT NULL DEFAULT 'copy';
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS delete_missing BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS sync_interval VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS run_count INT NOT NULL DEFAULT 0;
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS next_run_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE migration_buckets ADD COLUMN IF NOT EXISTS skipped_objects BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE migration_buckets ADD COLUMN IF NOT EXISTS deleted_objects BIGINT NOT NULL DEFAULT 0;
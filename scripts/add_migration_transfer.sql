@@ -0,0 +1,4 @@
+-- 迁移任务单独指定的并发数和带宽上限，0 表示使用配置文件中的 migration 设置
+
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS concurrency INT NOT NULL DEFAULT 0;
+ALTER TABLE migration_jobs ADD COLUMN IF NOT EXISTS bytes_per_second BIGINT NOT NULL DEFAULT 0;
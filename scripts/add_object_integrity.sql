@@ -0,0 +1,5 @@
+-- 对象数据完整性校验结果（后台巡检）
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS integrity_status VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS verified_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_objects_verified_at ON objects(verified_at NULLS FIRST);
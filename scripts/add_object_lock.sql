@@ -0,0 +1,9 @@
+-- Bucket 级 Object Lock 配置
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS object_lock_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS lock_default_mode VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS lock_default_days INT NOT NULL DEFAULT 0;
+
+-- 对象级保留与法律保留
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS lock_mode VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS lock_retain_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,20 @@
+-- 对象标签表
+CREATE TABLE IF NOT EXISTS object_tags (
+    id              BIGSERIAL PRIMARY KEY,
+    object_id       BIGINT REFERENCES objects(id) ON DELETE CASCADE,
+    tag_key         VARCHAR(128) NOT NULL,
+    tag_value       VARCHAR(256) NOT NULL DEFAULT '',
+    UNIQUE(object_id, tag_key)
+);
+
+-- Bucket 标签表
+CREATE TABLE IF NOT EXISTS bucket_tags (
+    id              BIGSERIAL PRIMARY KEY,
+    bucket_id       BIGINT REFERENCES buckets(id) ON DELETE CASCADE,
+    tag_key         VARCHAR(128) NOT NULL,
+    tag_value       VARCHAR(256) NOT NULL DEFAULT '',
+    UNIQUE(bucket_id, tag_key)
+);
+
+-- 按标签查找对象
+CREATE INDEX IF NOT EXISTS idx_object_tags_key_value ON object_tags(tag_key, tag_value);
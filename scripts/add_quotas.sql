@@ -0,0 +1,70 @@
+-- 存储配额与用量计数
+
+-- Bucket 配额，0 表示不限制
+CREATE TABLE IF NOT EXISTS bucket_quotas (
+    bucket_id       BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    max_bytes       BIGINT NOT NULL DEFAULT 0,
+    soft_bytes      BIGINT NOT NULL DEFAULT 0,
+    max_objects     BIGINT NOT NULL DEFAULT 0,
+    soft_objects    BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 用户配额，按用户拥有的所有 Bucket 合计
+CREATE TABLE IF NOT EXISTS user_quotas (
+    user_id         BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    max_bytes       BIGINT NOT NULL DEFAULT 0,
+    soft_bytes      BIGINT NOT NULL DEFAULT 0,
+    max_objects     BIGINT NOT NULL DEFAULT 0,
+    soft_objects    BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Bucket 用量计数，由 objects 表触发器增量维护，避免写入时 SUM()
+CREATE TABLE IF NOT EXISTS bucket_usage (
+    bucket_id       BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    bytes           BIGINT NOT NULL DEFAULT 0,
+    objects         BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 删除时只更新已有计数行：删除 Bucket 级联删除对象时，计数行可能已随 Bucket 删除
+CREATE OR REPLACE FUNCTION objects_usage_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND NOT COALESCE(OLD.is_delete_marker, FALSE) THEN
+        UPDATE bucket_usage
+        SET bytes = bytes - OLD.size, objects = objects - 1, updated_at = NOW()
+        WHERE bucket_id = OLD.bucket_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NOT COALESCE(NEW.is_delete_marker, FALSE) THEN
+        INSERT INTO bucket_usage (bucket_id, bytes, objects, updated_at)
+        VALUES (NEW.bucket_id, NEW.size, 1, NOW())
+        ON CONFLICT (bucket_id) DO UPDATE
+        SET bytes = bucket_usage.bytes + EXCLUDED.bytes, objects = bucket_usage.objects + 1, updated_at = NOW();
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS objects_usage_insert_delete ON objects;
+CREATE TRIGGER objects_usage_insert_delete
+    AFTER INSERT OR DELETE ON objects
+    FOR EACH ROW EXECUTE FUNCTION objects_usage_trigger();
+
+DROP TRIGGER IF EXISTS objects_usage_update ON objects;
+CREATE TRIGGER objects_usage_update
+    AFTER UPDATE ON objects
+    FOR EACH ROW
+    WHEN (OLD.size IS DISTINCT FROM NEW.size
+       OR OLD.bucket_id IS DISTINCT FROM NEW.bucket_id
+       OR OLD.is_delete_marker IS DISTINCT FROM NEW.is_delete_marker)
+    EXECUTE FUNCTION objects_usage_trigger();
+
+-- 按现有对象初始化用量计数
+INSERT INTO bucket_usage (bucket_id, bytes, objects, updated_at)
+SELECT b.id, COALESCE(SUM(o.size), 0), COUNT(o.id), NOW()
+FROM buckets b
+LEFT JOIN objects o ON o.bucket_id = b.id AND NOT COALESCE(o.is_delete_marker, FALSE)
+GROUP BY b.id
+ON CONFLICT (bucket_id) DO UPDATE
+SET bytes = EXCLUDED.bytes, objects = EXCLUDED.objects, updated_at = NOW();
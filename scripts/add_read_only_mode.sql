@@ -0,0 +1,11 @@
+-- 只读（维护）模式
+
+-- 集群级只读的 bucket_name 为空字符串
+CREATE TABLE IF NOT EXISTS read_only_modes (
+    scope       VARCHAR(16) NOT NULL,
+    bucket_name VARCHAR(63) NOT NULL DEFAULT '',
+    reason      TEXT NOT NULL DEFAULT '',
+    enabled_by  VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (scope, bucket_name)
+);
@@ -0,0 +1,43 @@
+-- Bucket 复制
+
+-- 对象复制状态：PENDING | COMPLETED | FAILED | REPLICA，为空表示不需要复制
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS replication_status VARCHAR(16) NOT NULL DEFAULT '';
+
+-- 远端复制目标（S3 兼容服务上的 Bucket）
+CREATE TABLE IF NOT EXISTS replication_targets (
+    id          VARCHAR(36) PRIMARY KEY,
+    endpoint    VARCHAR(512) NOT NULL,
+    region      VARCHAR(64) NOT NULL DEFAULT 'us-east-1',
+    access_key  VARCHAR(255) NOT NULL,
+    secret_key  VARCHAR(255) NOT NULL,
+    bucket      VARCHAR(255) NOT NULL,
+    created_by  VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Bucket 复制规则
+CREATE TABLE IF NOT EXISTS bucket_replication (
+    bucket_id     BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    configuration JSONB NOT NULL,
+    updated_at    TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 复制队列：每个对象和目标只保留一条记录，新的写入覆盖未完成的旧记录
+CREATE TABLE IF NOT EXISTS replication_queue (
+    id              BIGSERIAL PRIMARY KEY,
+    bucket_id       BIGINT NOT NULL REFERENCES buckets(id) ON DELETE CASCADE,
+    object_key      VARCHAR(1024) NOT NULL,
+    destination     VARCHAR(512) NOT NULL,
+    rule_id         VARCHAR(255) NOT NULL DEFAULT '',
+    operation       VARCHAR(16) NOT NULL,
+    status          VARCHAR(16) NOT NULL DEFAULT 'pending',
+    version         BIGINT NOT NULL DEFAULT 1,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (bucket_id, object_key, destination)
+);
+
+CREATE INDEX IF NOT EXISTS idx_replication_queue_due ON replication_queue(status, next_attempt_at);
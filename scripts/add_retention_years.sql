@@ -0,0 +1,2 @@
+-- Object Lock 默认保留期按年设置时单独保存，不再换算为天数
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS lock_default_years INT NOT NULL DEFAULT 0;
@@ -0,0 +1,25 @@
+-- 用量计费：按天汇总的存储量、请求数与流量
+-- 保存 Bucket 名称与所有者而非外键，Bucket 删除后历史记录仍可用于计费
+CREATE TABLE IF NOT EXISTS usage_daily (
+    day                 DATE NOT NULL,
+    owner_id            BIGINT NOT NULL DEFAULT 0,
+    bucket_name         VARCHAR(63) NOT NULL DEFAULT '',
+    storage_byte_hours  BIGINT NOT NULL DEFAULT 0,
+    class_a_requests    BIGINT NOT NULL DEFAULT 0,
+    class_b_requests    BIGINT NOT NULL DEFAULT 0,
+    other_requests      BIGINT NOT NULL DEFAULT 0,
+    ingress_bytes       BIGINT NOT NULL DEFAULT 0,
+    egress_bytes        BIGINT NOT NULL DEFAULT 0,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (day, owner_id, bucket_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_daily_owner ON usage_daily(owner_id, day);
+
+-- 存储量采样进度，多个实例共享，保证每段时间只累计一次
+CREATE TABLE IF NOT EXISTS usage_sampler_state (
+    id          INT PRIMARY KEY,
+    sampled_at  TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+INSERT INTO usage_sampler_state (id, sampled_at) VALUES (1, NOW()) ON CONFLICT (id) DO NOTHING;
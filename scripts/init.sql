@@ -33,6 +33,10 @@ CREATE TABLE IF NOT EXISTS buckets (
     acl             VARCHAR(32) DEFAULT 'private',
     versioning      BOOLEAN DEFAULT FALSE,
     default_expiry  VARCHAR(32) DEFAULT '7d',
+    object_lock_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    lock_default_mode   VARCHAR(16) NOT NULL DEFAULT '',
+    lock_default_days   INT NOT NULL DEFAULT 0,
+    lock_default_years  INT NOT NULL DEFAULT 0,
     created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
@@ -49,6 +53,12 @@ CREATE TABLE IF NOT EXISTS objects (
     storage_path    VARCHAR(1024),
     metadata        JSONB DEFAULT '{}',
     is_delete_marker BOOLEAN DEFAULT FALSE,
+    lock_mode       VARCHAR(16) NOT NULL DEFAULT '',
+    lock_retain_until TIMESTAMP WITH TIME ZONE,
+    legal_hold      BOOLEAN NOT NULL DEFAULT FALSE,
+    integrity_status VARCHAR(16) NOT NULL DEFAULT '',
+    verified_at     TIMESTAMP WITH TIME ZONE,
+    replication_status VARCHAR(16) NOT NULL DEFAULT '',
     created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     UNIQUE(bucket_id, key, version_id)
@@ -87,6 +97,24 @@ CREATE TABLE IF NOT EXISTS bucket_policies (
     updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- 对象标签表
+CREATE TABLE IF NOT EXISTS object_tags (
+    id              BIGSERIAL PRIMARY KEY,
+    object_id       BIGINT REFERENCES objects(id) ON DELETE CASCADE,
+    tag_key         VARCHAR(128) NOT NULL,
+    tag_value       VARCHAR(256) NOT NULL DEFAULT '',
+    UNIQUE(object_id, tag_key)
+);
+
+-- Bucket 标签表
+CREATE TABLE IF NOT EXISTS bucket_tags (
+    id              BIGSERIAL PRIMARY KEY,
+    bucket_id       BIGINT REFERENCES buckets(id) ON DELETE CASCADE,
+    tag_key         VARCHAR(128) NOT NULL,
+    tag_value       VARCHAR(256) NOT NULL DEFAULT '',
+    UNIQUE(bucket_id, tag_key)
+);
+
 -- 审计日志表
 CREATE TABLE IF NOT EXISTS audit_logs (
     id              BIGSERIAL PRIMARY KEY,
@@ -105,15 +133,227 @@ CREATE TABLE IF NOT EXISTS audit_logs (
     created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- Bucket 配额，0 表示不限制
+CREATE TABLE IF NOT EXISTS bucket_quotas (
+    bucket_id       BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    max_bytes       BIGINT NOT NULL DEFAULT 0,
+    soft_bytes      BIGINT NOT NULL DEFAULT 0,
+    max_objects     BIGINT NOT NULL DEFAULT 0,
+    soft_objects    BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 用户配额，按用户拥有的所有 Bucket 合计
+CREATE TABLE IF NOT EXISTS user_quotas (
+    user_id         BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    max_bytes       BIGINT NOT NULL DEFAULT 0,
+    soft_bytes      BIGINT NOT NULL DEFAULT 0,
+    max_objects     BIGINT NOT NULL DEFAULT 0,
+    soft_objects    BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Bucket 用量计数，由 objects 表触发器增量维护，避免写入时 SUM()
+CREATE TABLE IF NOT EXISTS bucket_usage (
+    bucket_id       BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    bytes           BIGINT NOT NULL DEFAULT 0,
+    objects         BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 删除时只更新已有计数行：删除 Bucket 级联删除对象时，计数行可能已随 Bucket 删除
+CREATE OR REPLACE FUNCTION objects_usage_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND NOT COALESCE(OLD.is_delete_marker, FALSE) THEN
+        UPDATE bucket_usage
+        SET bytes = bytes - OLD.size, objects = objects - 1, updated_at = NOW()
+        WHERE bucket_id = OLD.bucket_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NOT COALESCE(NEW.is_delete_marker, FALSE) THEN
+        INSERT INTO bucket_usage (bucket_id, bytes, objects, updated_at)
+        VALUES (NEW.bucket_id, NEW.size, 1, NOW())
+        ON CONFLICT (bucket_id) DO UPDATE
+        SET bytes = bucket_usage.bytes + EXCLUDED.bytes, objects = bucket_usage.objects + 1, updated_at = NOW();
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS objects_usage_insert_delete ON objects;
+CREATE TRIGGER objects_usage_insert_delete
+    AFTER INSERT OR DELETE ON objects
+    FOR EACH ROW EXECUTE FUNCTION objects_usage_trigger();
+
+DROP TRIGGER IF EXISTS objects_usage_update ON objects;
+CREATE TRIGGER objects_usage_update
+    AFTER UPDATE ON objects
+    FOR EACH ROW
+    WHEN (OLD.size IS DISTINCT FROM NEW.size
+       OR OLD.bucket_id IS DISTINCT FROM NEW.bucket_id
+       OR OLD.is_delete_marker IS DISTINCT FROM NEW.is_delete_marker)
+    EXECUTE FUNCTION objects_usage_trigger();
+
+-- 用量计费：按天汇总的存储量、请求数与流量
+-- 保存 Bucket 名称与所有者而非外键，Bucket 删除后历史记录仍可用于计费
+CREATE TABLE IF NOT EXISTS usage_daily (
+    day                 DATE NOT NULL,
+    owner_id            BIGINT NOT NULL DEFAULT 0,
+    bucket_name         VARCHAR(63) NOT NULL DEFAULT '',
+    storage_byte_hours  BIGINT NOT NULL DEFAULT 0,
+    class_a_requests    BIGINT NOT NULL DEFAULT 0,
+    class_b_requests    BIGINT NOT NULL DEFAULT 0,
+    other_requests      BIGINT NOT NULL DEFAULT 0,
+    ingress_bytes       BIGINT NOT NULL DEFAULT 0,
+    egress_bytes        BIGINT NOT NULL DEFAULT 0,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (day, owner_id, bucket_name)
+);
+
+-- 存储量采样进度，多个实例共享，保证每段时间只累计一次
+CREATE TABLE IF NOT EXISTS usage_sampler_state (
+    id          INT PRIMARY KEY,
+    sampled_at  TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- 只读（维护）模式，集群级只读的 bucket_name 为空字符串
+CREATE TABLE IF NOT EXISTS read_only_modes (
+    scope       VARCHAR(16) NOT NULL,
+    bucket_name VARCHAR(63) NOT NULL DEFAULT '',
+    reason      TEXT NOT NULL DEFAULT '',
+    enabled_by  VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (scope, bucket_name)
+);
+
+-- 迁移任务及进度
+CREATE TABLE IF NOT EXISTS migration_jobs (
+    id                  VARCHAR(36) PRIMARY KEY,
+    source_endpoint     TEXT NOT NULL,
+    source_region       VARCHAR(64) NOT NULL DEFAULT 'us-east-1',
+    access_key          VARCHAR(255) NOT NULL,
+    secret_key          VARCHAR(255) NOT NULL,
+    owner_id            BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_by          VARCHAR(255) NOT NULL DEFAULT '',
+    mode                VARCHAR(16) NOT NULL DEFAULT 'copy', -- copy | sync
+    delete_missing      BOOLEAN NOT NULL DEFAULT FALSE,
+    sync_interval       VARCHAR(32) NOT NULL DEFAULT '',     -- 非空时按间隔重复同步
+    concurrency         INT NOT NULL DEFAULT 0,              -- 0 表示使用 migration.concurrency
+    bytes_per_second    BIGINT NOT NULL DEFAULT 0,           -- 0 表示使用 migration.bytes_per_second
+    filters             JSONB NOT NULL DEFAULT '{}',         -- Bucket 过滤、前缀过滤和重命名
+    dry_run             BOOLEAN NOT NULL DEFAULT FALSE,      -- 只统计对象数和字节数，不迁移数据
+    status              VARCHAR(16) NOT NULL,
+    error               TEXT NOT NULL DEFAULT '',
+    total_buckets       INT NOT NULL DEFAULT 0,
+    run_count           INT NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at          TIMESTAMP WITH TIME ZONE,
+    finished_at         TIMESTAMP WITH TIME ZONE,
+    heartbeat_at        TIMESTAMP WITH TIME ZONE,
+    next_run_at         TIMESTAMP WITH TIME ZONE
+);
+
+-- 每个 Bucket 的进度，last_key 之前（含）的对象均已处理
+CREATE TABLE IF NOT EXISTS migration_buckets (
+    job_id              VARCHAR(36) NOT NULL REFERENCES migration_jobs(id) ON DELETE CASCADE,
+    source_bucket       VARCHAR(255) NOT NULL,
+    target_bucket       VARCHAR(63) NOT NULL,
+    status              VARCHAR(16) NOT NULL,
+    last_key            TEXT NOT NULL DEFAULT '',
+    listed_objects      BIGINT NOT NULL DEFAULT 0,
+    listed_bytes        BIGINT NOT NULL DEFAULT 0,
+    completed_objects   BIGINT NOT NULL DEFAULT 0,
+    failed_objects      BIGINT NOT NULL DEFAULT 0,
+    skipped_objects     BIGINT NOT NULL DEFAULT 0,
+    deleted_objects     BIGINT NOT NULL DEFAULT 0,
+    completed_bytes     BIGINT NOT NULL DEFAULT 0,
+    error               TEXT NOT NULL DEFAULT '',
+    settings            JSONB NOT NULL DEFAULT '{}',
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (job_id, source_bucket)
+);
+
+-- 迁移失败的对象
+CREATE TABLE IF NOT EXISTS migration_errors (
+    id                  BIGSERIAL PRIMARY KEY,
+    job_id              VARCHAR(36) NOT NULL REFERENCES migration_jobs(id) ON DELETE CASCADE,
+    source_bucket       VARCHAR(255) NOT NULL,
+    object_key          TEXT NOT NULL DEFAULT '',
+    error               TEXT NOT NULL,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 迁移对象的校验报告
+CREATE TABLE IF NOT EXISTS migration_objects (
+    job_id              VARCHAR(36) NOT NULL REFERENCES migration_jobs(id) ON DELETE CASCADE,
+    source_bucket       VARCHAR(255) NOT NULL,
+    object_key          TEXT NOT NULL,
+    size                BIGINT NOT NULL DEFAULT 0,
+    source_etag         VARCHAR(255) NOT NULL DEFAULT '',
+    target_etag         VARCHAR(255) NOT NULL DEFAULT '',
+    checksum            VARCHAR(20) NOT NULL,
+    metadata_count      INTEGER NOT NULL DEFAULT 0,
+    tag_count           INTEGER NOT NULL DEFAULT 0,
+    issues              TEXT NOT NULL DEFAULT '',
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (job_id, source_bucket, object_key)
+);
+
+-- 远端复制目标（S3 兼容服务上的 Bucket）
+CREATE TABLE IF NOT EXISTS replication_targets (
+    id          VARCHAR(36) PRIMARY KEY,
+    endpoint    VARCHAR(512) NOT NULL,
+    region      VARCHAR(64) NOT NULL DEFAULT 'us-east-1',
+    access_key  VARCHAR(255) NOT NULL,
+    secret_key  VARCHAR(255) NOT NULL,
+    bucket      VARCHAR(255) NOT NULL,
+    created_by  VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Bucket 复制规则
+CREATE TABLE IF NOT EXISTS bucket_replication (
+    bucket_id     BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    configuration JSONB NOT NULL,
+    updated_at    TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 复制队列：每个对象和目标只保留一条记录，新的写入覆盖未完成的旧记录
+CREATE TABLE IF NOT EXISTS replication_queue (
+    id              BIGSERIAL PRIMARY KEY,
+    bucket_id       BIGINT NOT NULL REFERENCES buckets(id) ON DELETE CASCADE,
+    object_key      VARCHAR(1024) NOT NULL,
+    destination     VARCHAR(512) NOT NULL,
+    rule_id         VARCHAR(255) NOT NULL DEFAULT '',
+    operation       VARCHAR(16) NOT NULL,
+    status          VARCHAR(16) NOT NULL DEFAULT 'pending',
+    version         BIGINT NOT NULL DEFAULT 1,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (bucket_id, object_key, destination)
+);
+
 -- 索引
 CREATE INDEX IF NOT EXISTS idx_objects_bucket_key ON objects(bucket_id, key);
 CREATE INDEX IF NOT EXISTS idx_objects_bucket_prefix ON objects(bucket_id, key varchar_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_objects_verified_at ON objects(verified_at NULLS FIRST);
 CREATE INDEX IF NOT EXISTS idx_credentials_access_key ON credentials(access_key);
 CREATE INDEX IF NOT EXISTS idx_multipart_bucket ON multipart_uploads(bucket_id);
+CREATE INDEX IF NOT EXISTS idx_object_tags_key_value ON object_tags(tag_key, tag_value);
 CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs(user_id);
 CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
 CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_audit_logs_bucket_name ON audit_logs(bucket_name);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs((metadata->>'request_id'));
+CREATE INDEX IF NOT EXISTS idx_usage_daily_owner ON usage_daily(owner_id, day);
+CREATE INDEX IF NOT EXISTS idx_migration_jobs_status ON migration_jobs(status, heartbeat_at);
+CREATE INDEX IF NOT EXISTS idx_migration_errors_job ON migration_errors(job_id, id);
+CREATE INDEX IF NOT EXISTS idx_replication_queue_due ON replication_queue(status, next_attempt_at);
+
+-- 初始化存储量采样进度
+INSERT INTO usage_sampler_state (id, sampled_at) VALUES (1, NOW()) ON CONFLICT (id) DO NOTHING;
 
 -- 初始管理员用户 (密码: admin123, 需要在应用启动时更新为真实 hash)
 INSERT INTO users (username, password_hash, is_admin) 